@@ -2,7 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"os/exec"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/f/mcptools/pkg/alias"
 	"github.com/spf13/cobra"
@@ -27,6 +30,9 @@ Examples:
   # List all registered server aliases
   mcp alias list
 
+  # Check that each alias still connects, with its tool count
+  mcp alias list --verify
+
   # Remove a server alias
   mcp alias remove myfs
 
@@ -50,8 +56,14 @@ func aliasAddCmd() *cobra.Command {
 
 The alias will be registered and can be used in place of the server command.
 
-Example:
-  mcp alias add myfs npx -y @modelcontextprotocol/server-filesystem ~/`,
+Instead of a command, an alias can refer to a server of an editor config as
+config:<config>/<server>, where <config> is a config alias such as cursor. The
+server's command, arguments and environment are read from the config each time
+the alias is used, so it runs exactly what the editor does.
+
+Examples:
+  mcp alias add myfs npx -y @modelcontextprotocol/server-filesystem ~/
+  mcp alias add fs config:cursor/filesystem`,
 		Args: cobra.MinimumNArgs(2),
 		RunE: func(thisCmd *cobra.Command, args []string) error {
 			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
@@ -83,9 +95,20 @@ Example:
 }
 
 func aliasListCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "list",
+	var verbose, verify bool
+	listCmd := &cobra.Command{
+		Use:   "list [--verbose] [--verify]",
 		Short: "List all registered MCP server aliases",
+		Long: `List all registered MCP server aliases.
+
+With --verbose, the executable each alias runs is resolved on the PATH. With --verify, each
+alias is also connected to and its tool count or error is reported, and the command fails if
+any alias doesn't work.
+
+Examples:
+  mcp alias list --verbose
+  mcp alias list --verify`,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// Load existing aliases
 			aliases, err := alias.Load()
@@ -98,14 +121,93 @@ func aliasListCmd() *cobra.Command {
 				return nil
 			}
 
-			fmt.Fprintln(cmd.OutOrStdout(), "Registered MCP server aliases:")
-			for name, a := range aliases {
-				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", name, a.Command)
+			names := make([]string, 0, len(aliases))
+			for name := range aliases {
+				names = append(names, name)
 			}
+			sort.Strings(names)
 
-			return nil
+			if !verbose && !verify {
+				fmt.Fprintln(cmd.OutOrStdout(), "Registered MCP server aliases:")
+				for _, name := range names {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", name, aliases[name].Command)
+				}
+				return nil
+			}
+
+			return printAliasDetails(cmd, aliases, names, verify)
 		},
 	}
+
+	listCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show the executable each alias resolves to")
+	listCmd.Flags().BoolVar(&verify, "verify", false, "Connect to each alias and report its tool count or error")
+	return listCmd
+}
+
+// printAliasDetails prints a table of aliases with the executable each resolves to and, with
+// verify, the outcome of connecting to it. It returns an error if any alias fails to verify.
+func printAliasDetails(cmd *cobra.Command, aliases alias.Aliases, names []string, verify bool) error {
+	var results []serverTools
+	if verify {
+		// An alias name given as the server is resolved by CreateClientFunc
+		results = listMultiTools(names)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	if verify {
+		fmt.Fprintln(w, "NAME\tCOMMAND\tRESOLVED\tSTATUS")
+	} else {
+		fmt.Fprintln(w, "NAME\tCOMMAND\tRESOLVED")
+	}
+
+	failed := 0
+	for i, name := range names {
+		command := aliases[name].Command
+		row := []string{name, command, resolveAliasCommand(command)}
+		if verify {
+			status := fmt.Sprintf("ok (%d tools)", len(results[i].tools))
+			if results[i].err != nil {
+				status = "error: " + results[i].err.Error()
+				failed++
+			}
+			row = append(row, status)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d aliases failed verification", failed, len(names))
+	}
+	return nil
+}
+
+// resolveAliasCommand describes what the server command of an alias runs: the path of its
+// executable on the PATH, or its URL for HTTP servers. Config references are resolved first.
+func resolveAliasCommand(command string) string {
+	args := ParseCommandString(command)
+	if isConfigRef(command) {
+		target, err := resolveConfigRef(command)
+		if err != nil {
+			return "(" + err.Error() + ")"
+		}
+		args = target.args
+	}
+
+	switch {
+	case len(args) == 0:
+		return "(empty command)"
+	case len(args) == 1 && IsHTTP(args[0]):
+		return args[0]
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return "(not found: " + args[0] + ")"
+	}
+	return path
 }
 
 func aliasRemoveCmd() *cobra.Command {
@@ -145,3 +247,40 @@ Example:
 		},
 	}
 }
+
+// configRefPrefix starts an alias value that refers to a server of an editor config, such as
+// "config:cursor/filesystem", instead of holding a server command.
+const configRefPrefix = "config:"
+
+// isConfigRef reports whether an alias value refers to a server of an editor config.
+func isConfigRef(value string) bool {
+	return strings.HasPrefix(value, configRefPrefix)
+}
+
+// resolveConfigRef reads the server a "config:<config alias>/<server>" alias value refers to,
+// from the config file as it is when the alias is used.
+func resolveConfigRef(ref string) (serverTarget, error) {
+	configName, serverName, found := strings.Cut(strings.TrimPrefix(ref, configRefPrefix), "/")
+	if !found || configName == "" || serverName == "" {
+		return serverTarget{}, fmt.Errorf("invalid config reference %q, expected %s<config>/<server>", ref, configRefPrefix)
+	}
+
+	configs, err := loadConfigsFile()
+	if err != nil {
+		return serverTarget{}, err
+	}
+	configFile, jsonPath, err := getConfigFileAndPath(configs, configName, "")
+	if err != nil {
+		return serverTarget{}, err
+	}
+	configData, err := readConfigFile(configFile)
+	if err != nil {
+		return serverTarget{}, err
+	}
+
+	serverConfig, ok := getServerFromConfig(configData, jsonPath, serverName)
+	if !ok {
+		return serverTarget{}, fmt.Errorf("server '%s' not found in %s", serverName, configFile)
+	}
+	return serverConfigTarget(serverConfig)
+}
@@ -2,10 +2,17 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/f/mcptools/pkg/alias"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestAliasCommands(t *testing.T) {
@@ -165,3 +172,103 @@ func TestAliasCommands(t *testing.T) {
 		}
 	})
 }
+
+func TestAliasListVerify(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := alias.Save(alias.Aliases{
+		"works":  {Command: "sh -c true"},
+		"broken": {Command: "no-such-mcp-server --stdio"},
+		"remote": {Command: "https://example.com/mcp"},
+	}); err != nil {
+		t.Fatalf("Failed to save aliases: %v", err)
+	}
+
+	originalFunc := CreateClientFunc
+	defer func() { CreateClientFunc = originalFunc }()
+	CreateClientFunc = func(args []string, _ ...client.ClientOption) (*client.Client, error) {
+		if args[0] == "broken" {
+			return nil, errors.New("init error: server exited")
+		}
+		mockClient := client.NewClient(&MockTransport{
+			ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+				return map[string]any{"tools": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}}, nil
+			},
+		})
+		_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+		return mockClient, nil
+	}
+
+	cmd := aliasListCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--verify"})
+	err := cmd.Execute()
+	if err == nil || err.Error() != "1 of 3 aliases failed verification" {
+		t.Errorf("Expected the failed alias to be reported, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a header and a row per alias, got:\n%s", buf.String())
+	}
+	// Aliases are listed by name
+	assertContains(t, lines[1], "broken")
+	assertContains(t, lines[1], "(not found: no-such-mcp-server)")
+	assertContains(t, lines[1], "error: init error: server exited")
+	assertContains(t, lines[2], "https://example.com/mcp")
+	assertContains(t, lines[3], "works")
+	assertContains(t, lines[3], "ok (2 tools)")
+}
+
+func TestResolveConfigRef(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configPath := filepath.Join(home, "editor.json")
+	config := `{"mcpServers": {
+		"fs": {"command": "node", "args": ["server.js", "--root", "/my docs"], "env": {"TOKEN": "secret"}},
+		"api": {"url": "https://example.com/sse", "type": "sse", "headers": {"Authorization": "Bearer x"}}
+	}}`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := saveConfigsFile(&ConfigsFile{Aliases: map[string]ConfigAlias{
+		"editor": {Path: configPath, JSONPath: "mcpServers"},
+	}}); err != nil {
+		t.Fatalf("Failed to save configs: %v", err)
+	}
+
+	target, err := resolveConfigRef("config:editor/fs")
+	if err != nil {
+		t.Fatalf("resolveConfigRef() error = %v", err)
+	}
+	if !reflect.DeepEqual(target.args, []string{"node", "server.js", "--root", "/my docs"}) {
+		t.Errorf("args = %q, want the command and arguments of the config", target.args)
+	}
+	if !reflect.DeepEqual(target.env, []string{"TOKEN=secret"}) {
+		t.Errorf("env = %q, want the env of the config", target.env)
+	}
+
+	target, err = resolveConfigRef("config:editor/api")
+	if err != nil {
+		t.Fatalf("resolveConfigRef() error = %v", err)
+	}
+	if target.transport != "sse" || !reflect.DeepEqual(target.headers, []string{"Authorization: Bearer x"}) {
+		t.Errorf("target = %+v, want the SSE transport and headers of the config", target)
+	}
+
+	for _, ref := range []string{"config:editor", "config:editor/missing", "config:unknown/fs"} {
+		if _, err := resolveConfigRef(ref); err == nil {
+			t.Errorf("resolveConfigRef(%q) returned no error", ref)
+		}
+	}
+
+	// Aliases holding a broken reference fail when they are used
+	if err := alias.Save(alias.Aliases{"fs": {Command: "config:editor/missing"}}); err != nil {
+		t.Fatalf("Failed to save aliases: %v", err)
+	}
+	if _, err := CreateClientFunc([]string{"fs"}); err == nil || !strings.HasPrefix(err.Error(), "alias fs: server 'missing' not found") {
+		t.Errorf("CreateClientFunc() error = %v, want the unresolved reference", err)
+	}
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// allListings are the listings shown by the all command, in order, with the JSON-RPC
+// method of each. The name of a listing is also the capability it needs and the key of the
+// items in its result.
+var allListings = []struct {
+	name   string
+	method string
+	title  string
+}{
+	{name: "tools", method: "tools/list", title: "Tools"},
+	{name: "resources", method: "resources/list", title: "Resources"},
+	{name: "prompts", method: "prompts/list", title: "Prompts"},
+}
+
+// listing is the outcome of one of the listings of the all command.
+type listing struct {
+	items []any
+	err   error
+}
+
+// AllCmd creates the all command.
+func AllCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "all [command args...]",
+		Short: "List the tools, resources and prompts of an MCP server",
+		Long: `List the tools, resources and prompts of an MCP server in one round trip, by sending the three
+list requests as a single JSON-RPC batch. Only the listings for capabilities the server advertises
+are requested. When the transport or the server doesn't support batches, the listings are
+requested one after another instead. Use -- to separate the server command.
+
+  mcp all -- npx -y @modelcontextprotocol/server-everything`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			parsedArgs := ProcessFlags(args)
+			if len(parsedArgs) > 0 && parsedArgs[0] == "--" {
+				parsedArgs = parsedArgs[1:]
+			}
+
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp all -- npx -y @modelcontextprotocol/server-everything\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			listings := listAll(context.Background(), mcpClient)
+
+			failed := false
+			if jsonutils.ParseFormat(FormatOption) != jsonutils.FormatTable {
+				result := map[string]any{}
+				for _, l := range allListings {
+					if outcome, ok := listings[l.name]; ok {
+						if outcome.err != nil {
+							fmt.Fprintf(os.Stderr, "Error: failed to list %s: %v\n", l.name, outcome.err)
+							failed = true
+							continue
+						}
+						result[l.name] = outcome.items
+					}
+				}
+				if formatErr := FormatAndPrintResponse(thisCmd, result, nil); formatErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+					os.Exit(1)
+				}
+			} else {
+				for _, l := range allListings {
+					outcome, ok := listings[l.name]
+					if !ok {
+						continue
+					}
+
+					fmt.Fprintf(thisCmd.OutOrStdout(), "%s:\n", l.title)
+					if outcome.err != nil {
+						fmt.Fprintf(os.Stderr, "Error: failed to list %s: %v\n", l.name, outcome.err)
+						failed = true
+						continue
+					}
+					if formatErr := FormatAndPrintResponse(thisCmd, map[string]any{l.name: outcome.items}, nil); formatErr != nil {
+						fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+						failed = true
+					}
+				}
+			}
+
+			if failed {
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// listAll lists the tools, resources and prompts the server advertises, keyed by listing
+// name. The list requests are sent as one batch, falling back to separate requests when the
+// transport or the server can't handle batches.
+func listAll(ctx context.Context, mcpClient *client.Client) map[string]listing {
+	names := []string{}
+	requests := []mcpclient.BatchRequest{}
+	info, known := initializeResult(mcpClient)
+	capabilities, _ := info["capabilities"].(map[string]any)
+	for _, l := range allListings {
+		if known && capabilities[l.name] == nil {
+			continue
+		}
+		names = append(names, l.name)
+		requests = append(requests, mcpclient.BatchRequest{Method: l.method, Params: map[string]any{}})
+	}
+
+	listings := make(map[string]listing, len(names))
+	if len(requests) == 0 {
+		return listings
+	}
+
+	responses, err := mcpclient.Batch(ctx, mcpClient, requests...)
+	if err != nil && !errors.Is(err, mcpclient.ErrBatchUnsupported) {
+		for _, name := range names {
+			listings[name] = listing{err: err}
+		}
+		return listings
+	}
+
+	for i, name := range names {
+		if err != nil {
+			items, listErr := listEntities(ctx, mcpClient, name)
+			listings[name] = listing{items: sortEntities(filterEntities(items)), err: listErr}
+			continue
+		}
+
+		response := responses[i]
+		if response.Error != nil {
+			listings[name] = listing{err: errors.New(response.Error.Message)}
+			continue
+		}
+
+		var result map[string]any
+		if unmarshalErr := json.Unmarshal(response.Result, &result); unmarshalErr != nil {
+			listings[name] = listing{err: fmt.Errorf("invalid response: %w", unmarshalErr)}
+			continue
+		}
+
+		// The batch only holds the first page, so the remaining pages are listed separately
+		nextCursor, _ := result["nextCursor"].(string)
+		if nextCursor != "" && !FirstPageOnly {
+			items, listErr := listEntities(ctx, mcpClient, name)
+			listings[name] = listing{items: sortEntities(filterEntities(items)), err: listErr}
+			continue
+		}
+		if FirstPageOnly {
+			warnMorePages(name, mcp.Cursor(nextCursor))
+		}
+
+		items, _ := result[name].([]any)
+		if items == nil {
+			items = []any{}
+		}
+		listings[name] = listing{items: sortEntities(filterEntities(items))}
+	}
+	return listings
+}
+
+// listEntities lists the tools, resources or prompts of the server with separate requests.
+func listEntities(ctx context.Context, mcpClient *client.Client, name string) ([]any, error) {
+	switch name {
+	case "tools":
+		listTools := mcpClient.ListTools
+		if FirstPageOnly {
+			listTools = mcpClient.ListToolsByPage
+		}
+		resp, err := listTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		if FirstPageOnly {
+			warnMorePages(name, resp.NextCursor)
+		}
+		return ConvertJSONToSlice(resp.Tools), nil
+	case "resources":
+		listResources := mcpClient.ListResources
+		if FirstPageOnly {
+			listResources = mcpClient.ListResourcesByPage
+		}
+		resp, err := listResources(ctx, mcp.ListResourcesRequest{})
+		if err != nil {
+			return nil, err
+		}
+		if FirstPageOnly {
+			warnMorePages(name, resp.NextCursor)
+		}
+		return ConvertJSONToSlice(resp.Resources), nil
+	case "prompts":
+		listPrompts := mcpClient.ListPrompts
+		if FirstPageOnly {
+			listPrompts = mcpClient.ListPromptsByPage
+		}
+		resp, err := listPrompts(ctx, mcp.ListPromptsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		if FirstPageOnly {
+			warnMorePages(name, resp.NextCursor)
+		}
+		return ConvertJSONToSlice(resp.Prompts), nil
+	default:
+		return nil, fmt.Errorf("unknown listing %q", name)
+	}
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// batchingTransport is a mock transport that also answers batch requests.
+type batchingTransport struct {
+	MockTransport
+	batches [][]string
+}
+
+// SendBatch answers each request of a batch with ExecuteFunc.
+func (b *batchingTransport) SendBatch(_ context.Context, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error) {
+	var methods []string
+	responses := make([]*transport.JSONRPCResponse, len(requests))
+	for i, request := range requests {
+		methods = append(methods, request.Method)
+		result, err := b.ExecuteFunc(request.Method, request.Params)
+		id := request.ID
+		if err != nil {
+			responses[i] = &transport.JSONRPCResponse{ID: &id}
+			responses[i].Error = &struct {
+				Code    int             `json:"code"`
+				Message string          `json:"message"`
+				Data    json.RawMessage `json:"data"`
+			}{Code: mcp.INTERNAL_ERROR, Message: err.Error()}
+			continue
+		}
+		data, _ := json.Marshal(result)
+		responses[i] = &transport.JSONRPCResponse{ID: &id, Result: data}
+	}
+	b.batches = append(b.batches, methods)
+	return responses, nil
+}
+
+func listAllResult(method string, _ any) (map[string]any, error) {
+	switch method {
+	case "tools/list":
+		return map[string]any{"tools": []any{map[string]any{"name": "search", "description": "Search things"}}}, nil
+	case "resources/list":
+		return map[string]any{"resources": []any{map[string]any{"uri": "test://doc", "name": "doc"}}}, nil
+	case "prompts/list":
+		return nil, fmt.Errorf("prompts are unavailable")
+	default:
+		return map[string]any{}, nil
+	}
+}
+
+func TestListAllBatch(t *testing.T) {
+	batching := &batchingTransport{MockTransport: MockTransport{ExecuteFunc: listAllResult}}
+	mockClient := client.NewClient(batching)
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+
+	result := &mcp.InitializeResult{}
+	result.Capabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+	result.Capabilities.Prompts = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+	rememberInitializeResult(mockClient, result)
+
+	listings := listAll(context.Background(), mockClient)
+
+	if len(batching.batches) != 1 || strings.Join(batching.batches[0], ",") != "tools/list,prompts/list" {
+		t.Fatalf("Expected one batch of the advertised listings, got %v", batching.batches)
+	}
+	if _, ok := listings["resources"]; ok {
+		t.Error("Expected resources not to be listed when the server doesn't advertise them")
+	}
+	if tools := listings["tools"]; tools.err != nil || len(tools.items) != 1 {
+		t.Errorf("Expected one tool, got %+v", tools)
+	}
+	if prompts := listings["prompts"]; prompts.err == nil || prompts.err.Error() != "prompts are unavailable" {
+		t.Errorf("Expected the prompts error, got %+v", prompts)
+	}
+}
+
+func TestListAllWithoutBatching(t *testing.T) {
+	var methods []string
+	mockClient := client.NewClient(&MockTransport{
+		ExecuteFunc: func(method string, params any) (map[string]any, error) {
+			methods = append(methods, method)
+			return listAllResult(method, params)
+		},
+	})
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+
+	listings := listAll(context.Background(), mockClient)
+
+	if strings.Join(methods, ",") != "tools/list,resources/list,prompts/list" {
+		t.Errorf("Expected the listings to be requested one by one, got %v", methods)
+	}
+	if resources := listings["resources"]; resources.err != nil || len(resources.items) != 1 {
+		t.Errorf("Expected one resource, got %+v", resources)
+	}
+}
+
+func TestAllCmdRun(t *testing.T) {
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "table"
+
+	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		switch method {
+		case "tools/list":
+			return map[string]any{"tools": []any{map[string]any{"name": "search", "description": "Search things"}}}, nil
+		case "resources/list":
+			return map[string]any{"resources": []any{map[string]any{"uri": "test://doc", "name": "doc"}}}, nil
+		default:
+			return map[string]any{"prompts": []any{map[string]any{"name": "greet"}}}, nil
+		}
+	})
+	defer cleanup()
+
+	cmd := AllCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"Tools:", "search", "Resources:", "test://doc", "Prompts:", "greet"} {
+		assertContains(t, output, expected)
+	}
+}
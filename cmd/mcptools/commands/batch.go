@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/spf13/cobra"
+)
+
+// batchCall is a tool invocation read from a batch file.
+type batchCall struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// BatchCmd creates the batch command.
+func BatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "batch file [--continue-on-error] [command args...]",
+		Short: "Call a sequence of tools listed in a file",
+		Long: `Call the tools listed in a JSON Lines file, one after another over a single connection to the
+server. Each line is an object such as {"tool": "write_file", "arguments": {"path": "a.txt"}}, and
+blank lines are ignored. Use - to read the calls from stdin, and -- to separate the server command.
+
+Results are printed to stdout as they arrive, and a summary is printed to stderr. The batch stops
+at the first failed call unless --continue-on-error is given, and the command exits with a
+non-zero status if any call failed.
+
+  mcp batch setup.jsonl -- npx -y @modelcontextprotocol/server-filesystem ~`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			continueOnError := false
+			remainingArgs := []string{}
+			for _, arg := range args {
+				if arg == "--continue-on-error" {
+					continueOnError = true
+					continue
+				}
+				remainingArgs = append(remainingArgs, arg)
+			}
+
+			parsedArgs := ProcessFlags(remainingArgs)
+			if len(parsedArgs) < 2 {
+				fmt.Fprintln(os.Stderr, "Error: a batch file and a server command are required")
+				fmt.Fprintln(os.Stderr, "Example: mcp batch setup.jsonl -- npx -y @modelcontextprotocol/server-filesystem ~")
+				os.Exit(1)
+			}
+			file, serverArgs := parsedArgs[0], parsedArgs[1:]
+			if serverArgs[0] == "--" {
+				serverArgs = serverArgs[1:]
+			}
+
+			calls, readErr := readBatchFile(file)
+			if readErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", readErr)
+				os.Exit(1)
+			}
+
+			mcpClient, clientErr := CreateClientFunc(serverArgs)
+			if clientErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", clientErr)
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			failures := runBatch(thisCmd, mcpClient, calls, continueOnError)
+			if failures > 0 {
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// readBatchFile reads the calls of a batch file, or of stdin when path is "-".
+func readBatchFile(path string) ([]batchCall, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // Path is provided by the user
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading batch file: %w", err)
+	}
+	return parseBatchCalls(data)
+}
+
+// parseBatchCalls parses one call per line of data, skipping blank lines.
+func parseBatchCalls(data []byte) ([]batchCall, error) {
+	var calls []batchCall
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var call batchCall
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&call); err != nil {
+			return nil, fmt.Errorf("line %d: invalid call: %w", lineNumber, err)
+		}
+		if call.Tool == "" {
+			return nil, fmt.Errorf("line %d: tool is required", lineNumber)
+		}
+		calls = append(calls, call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading batch file: %w", err)
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("the batch file has no calls")
+	}
+	return calls, nil
+}
+
+// runBatch calls each tool in order, printing the results and a summary, and returns the
+// number of failed calls. Unless continueOnError is set, it stops at the first failure.
+func runBatch(cmd *cobra.Command, mcpClient *client.Client, calls []batchCall, continueOnError bool) int {
+	errOut := cmd.ErrOrStderr()
+	successes, failures := 0, 0
+
+	for i, call := range calls {
+		fmt.Fprintf(errOut, "[%d/%d] %s\n", i+1, len(calls), call.Tool)
+
+		resp, err := callEntity(mcpClient, EntityTypeTool, call.Tool, call.Arguments, nil)
+		if err == nil {
+			err = toolResultError(resp)
+		}
+		if err == nil {
+			err = FormatAndPrintResponse(cmd, resp, nil)
+		}
+
+		if err != nil {
+			failures++
+			fmt.Fprintf(errOut, "Error: %s failed: %s\n", call.Tool, describeCallError(err))
+			if !continueOnError {
+				break
+			}
+			continue
+		}
+		successes++
+	}
+
+	fmt.Fprintf(errOut, "\n%d calls, %d succeeded, %d failed", len(calls), successes, failures)
+	if skipped := len(calls) - successes - failures; skipped > 0 {
+		fmt.Fprintf(errOut, ", %d skipped", skipped)
+	}
+	fmt.Fprintln(errOut)
+	return failures
+}
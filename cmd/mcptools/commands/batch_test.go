@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseBatchCalls(t *testing.T) {
+	calls, err := parseBatchCalls([]byte("{\"tool\": \"a\", \"arguments\": {\"x\": 1}}\n\n  {\"tool\": \"b\"}\n"))
+	if err != nil {
+		t.Fatalf("parseBatchCalls() error = %v", err)
+	}
+	expected := []batchCall{
+		{Tool: "a", Arguments: map[string]any{"x": float64(1)}},
+		{Tool: "b"},
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("parseBatchCalls() = %v, want %v", calls, expected)
+	}
+
+	invalid := map[string]string{
+		"empty file":    "\n\n",
+		"not JSON":      "{\"tool\": \"a\"}\nnot json",
+		"missing tool":  `{"arguments": {}}`,
+		"unknown field": `{"tool": "a", "args": {}}`,
+	}
+	for name, data := range invalid {
+		if _, err := parseBatchCalls([]byte(data)); err == nil {
+			t.Errorf("%s: parseBatchCalls() expected an error", name)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "table"
+
+	var called []string
+	cleanup := setupMockClient(func(_ string, params any) (map[string]any, error) {
+		data, _ := json.Marshal(params)
+		var request struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(data, &request)
+		name := request.Name
+		called = append(called, name)
+		if name == "broken" {
+			return nil, errors.New("tool failed")
+		}
+		return map[string]any{"content": []any{map[string]any{"type": "text", "text": "ran " + name}}}, nil
+	})
+	defer cleanup()
+	mockClient, _ := CreateClientFunc(nil)
+
+	calls := []batchCall{{Tool: "first"}, {Tool: "broken"}, {Tool: "last"}}
+
+	testCases := []struct {
+		continueOnError bool
+		expectedCalls   []string
+		expectedSummary string
+	}{
+		{false, []string{"first", "broken"}, "3 calls, 1 succeeded, 1 failed, 1 skipped"},
+		{true, []string{"first", "broken", "last"}, "3 calls, 2 succeeded, 1 failed"},
+	}
+
+	for _, tc := range testCases {
+		called = nil
+		cmd := &cobra.Command{}
+		var out, errOut bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+
+		if failures := runBatch(cmd, mockClient, calls, tc.continueOnError); failures != 1 {
+			t.Errorf("runBatch() failures = %d, want 1", failures)
+		}
+		if !reflect.DeepEqual(called, tc.expectedCalls) {
+			t.Errorf("called %v, want %v", called, tc.expectedCalls)
+		}
+		assertContains(t, out.String(), "ran first")
+		if !strings.HasSuffix(errOut.String(), tc.expectedSummary+"\n") {
+			t.Errorf("summary = %q, want %q", errOut.String(), tc.expectedSummary)
+		}
+	}
+}
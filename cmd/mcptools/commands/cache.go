@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/f/mcptools/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+// CacheCmd creates the cache command.
+func CacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of server tool schemas",
+		Long: `Manage the cache of server tool schemas.
+
+The call command caches the tools each server lists in $HOME/.mcpt/cache for 10 minutes, and
+converts the params of further calls to the same server with the cached schemas instead of
+listing the tools again. Use --no-cache with call to bypass the cache.
+
+Examples:
+  # Remove all cached tool schemas
+  mcp cache clear`,
+	}
+
+	cmd.AddCommand(cacheClearCmd())
+
+	return cmd
+}
+
+func cacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached tool schemas",
+		Args:  cobra.NoArgs,
+		RunE: func(thisCmd *cobra.Command, _ []string) error {
+			removed, err := cache.Clear()
+			if err != nil {
+				return fmt.Errorf("error clearing cache: %w", err)
+			}
+			fmt.Fprintf(thisCmd.OutOrStdout(), "Removed the cached tools of %d server(s)\n", removed)
+			return nil
+		},
+	}
+}
@@ -3,21 +3,71 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/f/mcptools/pkg/alias"
+	"github.com/f/mcptools/pkg/cache"
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/f/mcptools/pkg/template"
+	"github.com/itchyny/gojq"
+	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
 )
 
 // CallCmd creates the call command.
 func CallCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:                "call entity [command args...]",
-		Short:              "Call a tool, resource, or prompt on the MCP server",
+	return addRawFlag(&cobra.Command{
+		Use:   "call entity [command args...]",
+		Short: "Call a tool, resource, or prompt on the MCP server",
+		Long: `Call a tool, read a resource or get a prompt on an MCP server and print the result.
+
+Exit codes:
+  0  the call succeeded
+  1  invalid arguments, or the result couldn't be printed
+  2  the server couldn't be reached, or the connection failed during the call
+  3  the server answered with a JSON-RPC error
+  4  the tool reported a failure by setting isError in its result; the result is printed to stderr
+
+Use --retries to retry calls that fail because the connection failed or an HTTP server answered
+with a 5xx status, waiting --retry-delay (default 1s) before the first retry and twice as long
+before each further one. JSON-RPC errors returned by the server are not retried.
+
+--params takes a JSON object or a single key=value pair, and can be repeated. A value that
+starts with [ or { is parsed as JSON and any other value is passed as a string; key[]=value
+appends a string to an array. Later --params override earlier ones for the same key. String
+values are converted to the integer, number, boolean or array type the tool's input schema
+declares for them; use --no-coerce to send them as written. The tool schemas are cached in
+~/.mcpt/cache for 10 minutes per server, so that repeated calls don't list the tools again; use
+--no-cache to list them anyway, and mcp cache clear to empty the cache.
+
+Use --query to run the result through a jq expression before it is formatted; it can't be
+combined with --fields.
+
+Use --interactive to be asked in turn for each parameter of the tool's input schema that
+wasn't given with --params or --arg-file.
+
+Use --save-template name to save the entity, params and server of a successful call to
+~/.mcpt/templates/name.json, and --template name to call it again. The server and params of
+the call replace those of the template, one param at a time.
+
+Use --diff-with file.json to compare the result with one saved earlier, for example with
+--output-file, and print the paths that were added, removed or changed instead of the result.
+
+Use --arg-file name=path, which can be repeated, to pass the contents of a file as the string
+value of a parameter. It takes precedence over the same parameter in --params.`,
 		DisableFlagParsing: true,
 		SilenceUsage:       true,
+		ValidArgsFunction:  completeCallArgs,
 		Run: func(thisCmd *cobra.Command, args []string) {
 			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
 				_ = thisCmd.Help()
@@ -36,6 +86,21 @@ func CallCmd() *cobra.Command {
 			cmdArgs := args
 			parsedArgs := []string{}
 			entityName := ""
+			repeat := 0
+			concurrency := 1
+			var fields []string
+			var query *gojq.Code
+			outputFile := ""
+			decodeBase64 := false
+			retries := 0
+			retryDelay := defaultRetryDelay
+			var paramValues []string
+			var argFiles []string
+			coerce := true
+			interactive := false
+			templateName := ""
+			saveTemplate := ""
+			diffWith := ""
 
 			i := 0
 			entityExtracted := false
@@ -46,7 +111,7 @@ func CallCmd() *cobra.Command {
 					FormatOption = cmdArgs[i+1]
 					i += 2
 				case (cmdArgs[i] == FlagParams || cmdArgs[i] == FlagParamsShort) && i+1 < len(cmdArgs):
-					ParamsString = cmdArgs[i+1]
+					paramValues = append(paramValues, cmdArgs[i+1])
 					i += 2
 				case (cmdArgs[i] == FlagTransport) && i+1 < len(cmdArgs):
 					TransportOption = cmdArgs[i+1]
@@ -57,6 +122,131 @@ func CallCmd() *cobra.Command {
 				case (cmdArgs[i] == FlagAuthHeader) && i+1 < len(cmdArgs):
 					AuthHeader = cmdArgs[i+1]
 					i += 2
+				case (cmdArgs[i] == FlagHeader) && i+1 < len(cmdArgs):
+					HeaderOptions = append(HeaderOptions, cmdArgs[i+1])
+					i += 2
+				case (cmdArgs[i] == FlagEnv) && i+1 < len(cmdArgs):
+					ServerEnvOptions = append(ServerEnvOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagProtocolVersion && i+1 < len(cmdArgs):
+					ProtocolVersionOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagInitParams && i+1 < len(cmdArgs):
+					InitParamsOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagClientName && i+1 < len(cmdArgs):
+					ClientNameOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagClientVersion && i+1 < len(cmdArgs):
+					ClientVersionOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagCapability && i+1 < len(cmdArgs):
+					CapabilityOptions = append(CapabilityOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagRoot && i+1 < len(cmdArgs):
+					RootOptions = append(RootOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagConnectTimeout && i+1 < len(cmdArgs):
+					setConnectTimeout(cmdArgs[i+1])
+					i += 2
+				case (cmdArgs[i] == FlagMaxRetries) && i+1 < len(cmdArgs):
+					setMaxRetries(cmdArgs[i+1])
+					i += 2
+				case (cmdArgs[i] == FlagLogFile) && i+1 < len(cmdArgs):
+					LogFileOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagVerboseRPC:
+					VerboseRPC = true
+					i++
+				case cmdArgs[i] == FlagNoPrettyEmbedded:
+					NoPrettyEmbedded = true
+					i++
+				case cmdArgs[i] == FlagSaveImages && i+1 < len(cmdArgs):
+					SaveImagesDir = cmdArgs[i+1]
+					i += 2
+				case (cmdArgs[i] == FlagRepeat || cmdArgs[i] == FlagConcurrency) && i+1 < len(cmdArgs):
+					value, atoiErr := strconv.Atoi(cmdArgs[i+1])
+					if atoiErr != nil || value < 1 {
+						fmt.Fprintf(os.Stderr, "Error: %s must be a positive integer\n", cmdArgs[i])
+						os.Exit(1)
+					}
+					if cmdArgs[i] == FlagRepeat {
+						repeat = value
+					} else {
+						concurrency = value
+					}
+					i += 2
+				case cmdArgs[i] == FlagFields && i+1 < len(cmdArgs):
+					fields = parseFieldPaths(cmdArgs[i+1])
+					if len(fields) == 0 {
+						fmt.Fprintf(os.Stderr, "Error: %s requires a comma-separated list of paths such as content.0.text\n", FlagFields)
+						os.Exit(1)
+					}
+					i += 2
+				case cmdArgs[i] == FlagQuery && i+1 < len(cmdArgs):
+					query = parseQueryFlag(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagOutputFile && i+1 < len(cmdArgs):
+					outputFile = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagDecodeBase64:
+					decodeBase64 = true
+					i++
+				case cmdArgs[i] == FlagRaw:
+					RawOutput = true
+					i++
+				case cmdArgs[i] == FlagIndent && i+1 < len(cmdArgs):
+					setIndentOption(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagCompact:
+					CompactOption = true
+					i++
+				case cmdArgs[i] == FlagNoColor:
+					setColorOption(string(jsonutils.ColorNever))
+					i++
+				case cmdArgs[i] == FlagColor && i+1 < len(cmdArgs):
+					setColorOption(cmdArgs[i+1])
+					i += 2
+				case strings.HasPrefix(cmdArgs[i], FlagColor+"="):
+					setColorOption(strings.TrimPrefix(cmdArgs[i], FlagColor+"="))
+					i++
+				case cmdArgs[i] == FlagRetries && i+1 < len(cmdArgs):
+					value, atoiErr := strconv.Atoi(cmdArgs[i+1])
+					if atoiErr != nil || value < 0 {
+						fmt.Fprintf(os.Stderr, "Error: %s must be a non-negative integer\n", FlagRetries)
+						os.Exit(1)
+					}
+					retries = value
+					i += 2
+				case cmdArgs[i] == FlagInteractive:
+					interactive = true
+					i++
+				case cmdArgs[i] == FlagNoCoerce:
+					coerce = false
+					i++
+				case cmdArgs[i] == FlagNoCache:
+					NoCacheOption = true
+					i++
+				case cmdArgs[i] == FlagDiffWith && i+1 < len(cmdArgs):
+					diffWith = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagTemplate && i+1 < len(cmdArgs):
+					templateName = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagSaveTemplate && i+1 < len(cmdArgs):
+					saveTemplate = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagArgFile && i+1 < len(cmdArgs):
+					argFiles = append(argFiles, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagRetryDelay && i+1 < len(cmdArgs):
+					value, parseErr := time.ParseDuration(cmdArgs[i+1])
+					if parseErr != nil || value < 0 {
+						fmt.Fprintf(os.Stderr, "Error: %s must be a duration such as 500ms or 2s\n", FlagRetryDelay)
+						os.Exit(1)
+					}
+					retryDelay = value
+					i += 2
 				case !entityExtracted:
 					entityName = cmdArgs[i]
 					entityExtracted = true
@@ -70,6 +260,49 @@ func CallCmd() *cobra.Command {
 				}
 			}
 
+			if fields != nil && query != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s and %s can't be used together\n", FlagFields, FlagQuery)
+				os.Exit(1)
+			}
+
+			if RawOutput && (fields != nil || query != nil || outputFile != "") {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s, %s or %s\n", FlagRaw, FlagFields, FlagQuery, FlagOutputFile)
+				os.Exit(1)
+			}
+
+			if diffWith != "" && (RawOutput || outputFile != "") {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s or %s\n", FlagDiffWith, FlagRaw, FlagOutputFile)
+				os.Exit(1)
+			}
+
+			if decodeBase64 && outputFile == "" {
+				fmt.Fprintf(os.Stderr, "Error: %s requires %s\n", FlagDecodeBase64, FlagOutputFile)
+				os.Exit(1)
+			}
+
+			// A template names the entity, so every positional argument belongs to the server
+			var saved *template.Template
+			if templateName != "" {
+				var loadErr error
+				if saved, loadErr = template.Load(templateName); loadErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", loadErr)
+					os.Exit(1)
+				}
+				if entityName != "" {
+					parsedArgs = append([]string{entityName}, parsedArgs...)
+				}
+				if len(parsedArgs) == 0 {
+					parsedArgs = saved.Server
+				}
+				entityName = saved.Entity
+			}
+
+			if saveTemplate != "" && (repeat > 0 || concurrency > 1) {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s or %s\n", FlagSaveTemplate, FlagRepeat, FlagConcurrency)
+				os.Exit(1)
+			}
+			templateEntity := entityName
+
 			if entityName == "" {
 				fmt.Fprintln(os.Stderr, "Error: entity name is required")
 				fmt.Fprintln(
@@ -96,65 +329,799 @@ func CallCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
-			var params map[string]any
-			if ParamsString != "" {
-				if jsonErr := json.Unmarshal([]byte(ParamsString), &params); jsonErr != nil {
-					fmt.Fprintf(os.Stderr, "Error: invalid JSON for params: %v\n", jsonErr)
+			if len(paramValues) == 0 && ParamsString != "" {
+				paramValues = []string{ParamsString}
+			}
+			params, paramsErr := parseCallParams(paramValues)
+			if paramsErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", paramsErr)
+				os.Exit(1)
+			}
+			if saved != nil {
+				params = mergeParams(saved.Arguments, params)
+			}
+
+			if len(argFiles) > 0 {
+				var fileErr error
+				if params, fileErr = readArgFiles(params, argFiles); fileErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", fileErr)
 					os.Exit(1)
 				}
 			}
 
-			mcpClient, clientErr := CreateClientFunc(parsedArgs)
-			if clientErr != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", clientErr)
+			if entityType != EntityTypeTool && entityType != EntityTypeRes && entityType != EntityTypePrompt {
+				fmt.Fprintf(os.Stderr, "Error: unsupported entity type: %s\n", entityType)
 				os.Exit(1)
 			}
-			defer CloseWithTimeout(mcpClient)
 
-			var resp map[string]any
-			var execErr error
+			if interactive {
+				if entityType != EntityTypeTool {
+					fmt.Fprintf(os.Stderr, "Error: %s only works with tools\n", FlagInteractive)
+					os.Exit(1)
+				}
+				ask, restore := newTerminalAsk()
+				var askErr error
+				params, askErr = askToolParams(parsedArgs, entityName, params, ask, os.Stderr)
+				restore()
+				if askErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", askErr)
+					os.Exit(1)
+				}
+			}
 
-			switch entityType {
-			case EntityTypeTool:
-				var toolResponse *mcp.CallToolResult
-				request := mcp.CallToolRequest{}
-				request.Params.Name = entityName
-				request.Params.Arguments = params
-				toolResponse, execErr = mcpClient.CallTool(context.Background(), request)
-				if execErr == nil && toolResponse != nil {
-					resp = ConvertJSONToMap(toolResponse)
-				} else {
-					resp = map[string]any{}
-				}
-			case EntityTypeRes:
-				var resourceResponse *mcp.ReadResourceResult
-				request := mcp.ReadResourceRequest{}
-				request.Params.URI = entityName
-				resourceResponse, execErr = mcpClient.ReadResource(context.Background(), request)
-				if execErr == nil && resourceResponse != nil {
-					resp = ConvertJSONToMap(resourceResponse)
-				} else {
-					resp = map[string]any{}
-				}
-			case EntityTypePrompt:
-				var promptResponse *mcp.GetPromptResult
-				request := mcp.GetPromptRequest{}
-				request.Params.Name = entityName
-				promptResponse, execErr = mcpClient.GetPrompt(context.Background(), request)
-				if execErr == nil && promptResponse != nil {
-					resp = ConvertJSONToMap(promptResponse)
+			if repeat > 0 || concurrency > 1 {
+				if retries > 0 {
+					fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s or %s\n", FlagRetries, FlagRepeat, FlagConcurrency)
+					os.Exit(1)
+				}
+				if repeat == 0 {
+					repeat = concurrency
+				}
+				stats, benchErr := benchmarkCalls(parsedArgs, entityType, entityName, params, coerce, repeat, concurrency)
+				if benchErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", benchErr)
+					os.Exit(1)
+				}
+				printCallStats(thisCmd, stats)
+				if stats.Failures > 0 {
+					os.Exit(1)
+				}
+				return
+			}
+
+			mcpClient, resp, attempts, execErr := callWithRetries(parsedArgs, entityType, entityName, params, coerce, retries, retryDelay)
+			if mcpClient != nil {
+				defer CloseWithTimeout(mcpClient)
+			}
+
+			if execErr != nil {
+				if attempts > 1 {
+					fmt.Fprintf(os.Stderr, "Error: %s (gave up after %d attempts)\n", describeCallError(execErr), attempts)
 				} else {
-					resp = map[string]any{}
+					fmt.Fprintf(os.Stderr, "Error: %s\n", describeCallError(execErr))
 				}
-			default:
-				fmt.Fprintf(os.Stderr, "Error: unsupported entity type: %s\n", entityType)
-				os.Exit(1)
+				if mcpClient != nil {
+					CloseWithTimeout(mcpClient)
+				}
+				os.Exit(callErrorExitCode(execErr))
+			}
+
+			if saveTemplate != "" && toolResultError(resp) == nil {
+				invocation := &template.Template{Entity: templateEntity, Arguments: params, Server: parsedArgs}
+				if saveErr := template.Save(saveTemplate, invocation); saveErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", saveErr)
+					CloseWithTimeout(mcpClient)
+					os.Exit(1)
+				}
+				fmt.Fprintf(thisCmd.ErrOrStderr(), "Saved template %s\n", saveTemplate)
+			}
+
+			if RawOutput {
+				if rawErr := printRawResult(thisCmd, mcpClient, resp); rawErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", rawErr)
+					CloseWithTimeout(mcpClient)
+					os.Exit(1)
+				}
+				if toolResultError(resp) != nil {
+					CloseWithTimeout(mcpClient)
+					os.Exit(exitCodeToolError)
+				}
+				return
+			}
+
+			if toolErr := toolResultError(resp); toolErr != nil {
+				// The table format shows the error text; JSON formats keep the whole result
+				errorOutput := toolErr.Error()
+				if jsonutils.ParseFormat(FormatOption) != jsonutils.FormatTable {
+					if formatted, formatErr := jsonutils.Format(resp, formatOptions()); formatErr == nil {
+						errorOutput = formatted
+					}
+				} else if jsonutils.UseColors(os.Stderr) {
+					errorOutput = jsonutils.ColorRed + errorOutput + jsonutils.ColorReset
+				}
+				fmt.Fprintf(thisCmd.ErrOrStderr(), "Error: tool %s failed:\n%s\n", entityName, errorOutput)
+				CloseWithTimeout(mcpClient)
+				os.Exit(exitCodeToolError)
+			}
+
+			var output any = resp
+			switch {
+			case fields != nil:
+				output, execErr = jsonutils.SelectFields(resp, fields)
+			case query != nil:
+				output, execErr = applyQuery(query, resp)
+			}
+
+			if execErr == nil && diffWith != "" {
+				diffs, diffErr := diffResultFile(diffWith, output)
+				if diffErr == nil {
+					diffErr = jsonutils.FormatDiff(thisCmd.OutOrStdout(), diffs)
+				}
+				if diffErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", diffErr)
+					os.Exit(1)
+				}
+				return
 			}
 
-			if formatErr := FormatAndPrintResponse(thisCmd, resp, execErr); formatErr != nil {
+			if execErr == nil && outputFile != "" {
+				printOutputFile(thisCmd, outputFile, output, decodeBase64)
+				return
+			}
+
+			if formatErr := FormatAndPrintResponse(thisCmd, output, execErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
 		},
+	})
+}
+
+// Exit codes of the call command, which let scripts tell failures apart.
+const (
+	exitCodeInvalidArgs = 1
+	exitCodeConnection  = 2
+	exitCodeProtocol    = 3
+	exitCodeToolError   = 4
+)
+
+// Retry delays of the call command.
+const (
+	defaultRetryDelay = time.Second
+	maxRetryDelay     = 30 * time.Second
+)
+
+// connectError is returned for a call when the client couldn't connect to the server.
+type connectError struct {
+	err error
+}
+
+func (e *connectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectError) Unwrap() error {
+	return e.err
+}
+
+// invalidParamError is returned for a call when the value of a param doesn't match the type
+// the tool's input schema declares for it.
+type invalidParamError struct {
+	name string
+	err  error
+}
+
+func (e *invalidParamError) Error() string {
+	return fmt.Sprintf("invalid value for parameter %s: %v", e.name, e.err)
+}
+
+func (e *invalidParamError) Unwrap() error {
+	return e.err
+}
+
+// callErrorExitCode returns the exit code for an error returned by a call. Invalid arguments
+// and params are usage errors, errors of the underlying connection are transport errors, and
+// any other error is a JSON-RPC error returned by the server.
+func callErrorExitCode(err error) int {
+	var paramErr *invalidParamError
+	var argsErr *invalidArgsError
+	if errors.As(err, &paramErr) || errors.As(err, &argsErr) {
+		return exitCodeInvalidArgs
+	}
+	var connectErr *connectError
+	var transportErr *mcpclient.TransportError
+	if errors.As(err, &connectErr) || errors.As(err, &transportErr) {
+		return exitCodeConnection
+	}
+	return exitCodeProtocol
+}
+
+// isRetryableCallError reports whether a failed call may succeed when it is retried: the
+// connection failed, and the server didn't reject the request with a 4xx HTTP status.
+// JSON-RPC errors are answers from the server, which a retry would only repeat.
+func isRetryableCallError(err error) bool {
+	if callErrorExitCode(err) != exitCodeConnection {
+		return false
+	}
+	if code, ok := mcpclient.StatusCode(err); ok && code < 500 {
+		return false
+	}
+	return true
+}
+
+// describeCallError describes the error of a call, with its code when the server returned a
+// JSON-RPC error.
+func describeCallError(err error) string {
+	var rpcErr *mcpclient.RPCError
+	if errors.As(err, &rpcErr) {
+		return fmt.Sprintf("%v (JSON-RPC error %d)", err, rpcErr.Code)
+	}
+	return err.Error()
+}
+
+// retryBackoff returns how long to wait after a failed attempt, doubling delay with each
+// attempt up to maxRetryDelay.
+func retryBackoff(delay time.Duration, attempt int) time.Duration {
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// callWithRetries connects to the server and makes a call, retrying up to retries times when
+// the call fails in a way that may be transient. Each retry uses a new connection, since the
+// old one may not have survived the failure. It returns the client the last attempt used,
+// which is nil if it couldn't connect, the result, and the number of attempts made.
+func callWithRetries(serverArgs []string, entityType, entityName string, params map[string]any, coerce bool, retries int, delay time.Duration) (*client.Client, map[string]any, int, error) {
+	for attempt := 1; ; attempt++ {
+		var resp map[string]any
+		mcpClient, err := CreateClientFunc(serverArgs)
+		if err != nil {
+			err = &connectError{err: err}
+		} else {
+			callParams := params
+			if coerce && entityType == EntityTypeTool {
+				callParams, err = coerceToolParams(mcpClient, entityName, params)
+				if err != nil {
+					return mcpClient, nil, attempt, err
+				}
+			}
+
+			// Long-running tools can report progress while the call is pending
+			var progressToken mcp.ProgressToken
+			finishProgress := func() {}
+			if entityType == EntityTypeTool {
+				progressToken = callProgressToken
+				finishProgress = watchProgress(mcpClient, progressToken)
+			}
+
+			resp, err = callEntity(mcpClient, entityType, entityName, callParams, progressToken)
+			finishProgress()
+		}
+
+		if err == nil || attempt > retries || !isRetryableCallError(err) {
+			return mcpClient, resp, attempt, err
+		}
+
+		wait := retryBackoff(delay, attempt)
+		fmt.Fprintf(os.Stderr, "Attempt %d failed: %v; retrying in %v\n", attempt, err, wait)
+		if mcpClient != nil {
+			CloseWithTimeout(mcpClient)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// parseFieldPaths splits a --fields value into its paths, dropping empty ones.
+func parseFieldPaths(value string) []string {
+	var paths []string
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// parseCallParams merges the --params values of a call in order. Each value is either a JSON
+// object or a key=value pair, where key[]=value appends a string to an array and a value
+// starting with [ or { is parsed as JSON.
+func parseCallParams(values []string) (map[string]any, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	params := make(map[string]any)
+	for _, value := range values {
+		if strings.HasPrefix(strings.TrimSpace(value), "{") {
+			var object map[string]any
+			if err := json.Unmarshal([]byte(value), &object); err != nil {
+				return nil, fmt.Errorf("invalid JSON for params: %w", err)
+			}
+			for k, v := range object {
+				params[k] = v
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(value, "=")
+		if !ok || key == "" || key == "[]" {
+			return nil, fmt.Errorf("invalid params %q, expected a JSON object or key=value", value)
+		}
+		if name, isArray := strings.CutSuffix(key, "[]"); isArray {
+			items, _ := params[name].([]any)
+			if _, exists := params[name]; exists && items == nil {
+				return nil, fmt.Errorf("params %s[] can't be appended to, %s is not an array", name, name)
+			}
+			params[name] = append(items, raw)
+			continue
+		}
+
+		params[key] = raw
+		if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+			var parsed any
+			if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+				return nil, fmt.Errorf("invalid JSON for params %s: %w", key, err)
+			}
+			params[key] = parsed
+		}
+	}
+	return params, nil
+}
+
+// coerceToolParams converts the string values of params to the types declared for them by
+// the input schema of the named tool. Params are returned unchanged if the tool or its schema
+// can't be found. The schema is taken from the tool cache of the server when it is fresh.
+func coerceToolParams(mcpClient *client.Client, toolName string, params map[string]any) (map[string]any, error) {
+	hasStrings := false
+	for _, value := range params {
+		if _, ok := value.(string); ok {
+			hasStrings = true
+			break
+		}
+	}
+	if !hasStrings {
+		return params, nil
+	}
+
+	tool, err := findCachedTool(mcpClient, toolName)
+	if err != nil {
+		return params, nil
+	}
+	properties := tool.InputSchema.Properties
+
+	coerced := make(map[string]any, len(params))
+	for name, value := range params {
+		coerced[name] = value
+		property, _ := properties[name].(map[string]any)
+		if _, ok := value.(string); !ok || property == nil {
+			continue
+		}
+		converted, err := jsonutils.CoerceValue(schemaPropertyType(property), value)
+		if err != nil {
+			return nil, &invalidParamError{name: name, err: err}
+		}
+		coerced[name] = converted
+	}
+	return coerced, nil
+}
+
+// findCachedTool returns the named tool of the server, from the cache when its tools were
+// listed recently. Otherwise, or when the cached tools don't include it, the tools are listed
+// and cached again. The tools are cached under the key of the server the client is connected
+// to, so that an alias and the command it stands for share them. Without a key, or with
+// --no-cache, the cache isn't used.
+func findCachedTool(mcpClient *client.Client, toolName string) (mcp.Tool, error) {
+	key, ok := serverKey(mcpClient)
+	if NoCacheOption || !ok {
+		return findTool(mcpClient, toolName)
+	}
+
+	if tools, ok := cache.LoadTools(key, cache.DefaultTTL); ok {
+		for _, tool := range tools {
+			if tool.Name == toolName {
+				return tool, nil
+			}
+		}
+	}
+
+	tools, err := listAndCacheTools(mcpClient, key)
+	if err != nil {
+		return mcp.Tool{}, err
+	}
+	for _, tool := range tools {
+		if tool.Name == toolName {
+			return tool, nil
+		}
+	}
+	return mcp.Tool{}, fmt.Errorf("tool %s not found", toolName)
+}
+
+// listAndCacheTools lists the tools of the server and caches them under key.
+func listAndCacheTools(mcpClient *client.Client, key []string) ([]mcp.Tool, error) {
+	resp, err := mcpClient.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.SaveTools(key, resp.Tools); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return resp.Tools, nil
+}
+
+// schemaPropertyType returns the type of a JSON schema property, picking the first type other
+// than null when several are allowed.
+func schemaPropertyType(property map[string]any) string {
+	switch t := property["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// mergeParams returns the params of base with those of overrides applied on top.
+func mergeParams(base, overrides map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(overrides))
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		merged[name] = value
+	}
+	return merged
+}
+
+// readArgFiles sets the parameters named by --arg-file name=path values to the contents of
+// their files, creating params if needed.
+func readArgFiles(params map[string]any, specs []string) (map[string]any, error) {
+	if params == nil {
+		params = make(map[string]any, len(specs))
+	}
+	for _, spec := range specs {
+		name, path, ok := strings.Cut(spec, "=")
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected name=path", FlagArgFile, spec)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", FlagArgFile, name, err)
+		}
+		params[name] = string(data)
+	}
+	return params, nil
+}
+
+// callEntity calls a tool, reads a resource or gets a prompt. A non-nil progressToken asks
+// the server to report the progress of a tool call.
+func callEntity(mcpClient *client.Client, entityType, entityName string, params map[string]any, progressToken mcp.ProgressToken) (map[string]any, error) {
+	switch entityType {
+	case EntityTypeTool:
+		request := mcp.CallToolRequest{}
+		request.Params.Name = entityName
+		request.Params.Arguments = params
+		if progressToken != nil {
+			request.Params.Meta = &struct {
+				ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
+			}{ProgressToken: progressToken}
+		}
+		ctx := mcpclient.WithRPCError(context.Background())
+		toolResponse, err := mcpClient.CallTool(ctx, request)
+		if err != nil || toolResponse == nil {
+			return map[string]any{}, mcpclient.WrapRPCError(ctx, err)
+		}
+		return withStructuredContent(mcpClient, ConvertJSONToMap(toolResponse)), nil
+	case EntityTypeRes:
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = entityName
+		ctx := mcpclient.WithRPCError(context.Background())
+		resourceResponse, err := mcpClient.ReadResource(ctx, request)
+		if err != nil || resourceResponse == nil {
+			return map[string]any{}, mcpclient.WrapRPCError(ctx, err)
+		}
+		return ConvertJSONToMap(resourceResponse), nil
+	case EntityTypePrompt:
+		request := mcp.GetPromptRequest{}
+		request.Params.Name = entityName
+		ctx := mcpclient.WithRPCError(context.Background())
+		promptResponse, err := mcpClient.GetPrompt(ctx, request)
+		if err != nil || promptResponse == nil {
+			return map[string]any{}, mcpclient.WrapRPCError(ctx, err)
+		}
+		return ConvertJSONToMap(promptResponse), nil
+	default:
+		return nil, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+}
+
+// withStructuredContent adds the structuredContent of the tool result the server sent, which
+// mcp-go doesn't model, to result.
+func withStructuredContent(mcpClient *client.Client, result map[string]any) map[string]any {
+	recording, ok := mcpclient.GetRecording(mcpClient)
+	if !ok {
+		return result
+	}
+
+	var raw struct {
+		StructuredContent any `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(recording.LastResult(), &raw); err == nil && raw.StructuredContent != nil {
+		result["structuredContent"] = raw.StructuredContent
+	}
+	return result
+}
+
+// callStats summarizes the calls made in benchmark mode.
+type callStats struct {
+	Calls       int
+	Successes   int
+	Failures    int
+	Concurrency int
+	Total       time.Duration
+	Min         time.Duration
+	Max         time.Duration
+	Mean        time.Duration
+	P50         time.Duration
+	P95         time.Duration
+}
+
+// benchmarkCalls makes repeat calls using concurrency clients in parallel, each with its own
+// connection to the server, and collects their latencies.
+func benchmarkCalls(serverArgs []string, entityType, entityName string, params map[string]any, coerce bool, repeat, concurrency int) (callStats, error) {
+	if concurrency > repeat {
+		concurrency = repeat
+	}
+
+	clients := make([]*client.Client, 0, concurrency)
+	defer func() {
+		for _, c := range clients {
+			CloseWithTimeout(c)
+		}
+	}()
+	for len(clients) < concurrency {
+		c, err := CreateClientFunc(serverArgs)
+		if err != nil {
+			return callStats{}, err
+		}
+		clients = append(clients, c)
+	}
+
+	if coerce && entityType == EntityTypeTool {
+		var err error
+		if params, err = coerceToolParams(clients[0], entityName, params); err != nil {
+			return callStats{}, err
+		}
+	}
+
+	jobs := make(chan struct{}, repeat)
+	for i := 0; i < repeat; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	latencies := make([]time.Duration, 0, repeat)
+	failures := 0
+
+	start := time.Now()
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *client.Client) {
+			defer wg.Done()
+			for range jobs {
+				callStart := time.Now()
+				resp, err := callEntity(c, entityType, entityName, params, nil)
+				latency := time.Since(callStart)
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if isError, _ := resp["isError"].(bool); err != nil || isError {
+					failures++
+				}
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	stats := summarizeLatencies(latencies)
+	stats.Failures = failures
+	stats.Successes = stats.Calls - failures
+	stats.Concurrency = concurrency
+	stats.Total = time.Since(start)
+	return stats, nil
+}
+
+// summarizeLatencies computes the latency distribution of a set of calls.
+func summarizeLatencies(latencies []time.Duration) callStats {
+	stats := callStats{Calls: len(latencies)}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, latency := range sorted {
+		sum += latency
+	}
+
+	// Nearest-rank percentiles
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Mean = sum / time.Duration(len(sorted))
+	stats.P50 = percentile(50)
+	stats.P95 = percentile(95)
+	return stats
+}
+
+// printCallStats prints the benchmark summary, as JSON when the json format is requested.
+func printCallStats(cmd *cobra.Command, stats callStats) {
+	if strings.ToLower(FormatOption) == formatJSON {
+		output, _ := json.MarshalIndent(map[string]any{
+			"calls":       stats.Calls,
+			"successes":   stats.Successes,
+			"failures":    stats.Failures,
+			"concurrency": stats.Concurrency,
+			"total_ms":    durationMillis(stats.Total),
+			"min_ms":      durationMillis(stats.Min),
+			"max_ms":      durationMillis(stats.Max),
+			"mean_ms":     durationMillis(stats.Mean),
+			"p50_ms":      durationMillis(stats.P50),
+			"p95_ms":      durationMillis(stats.P95),
+		}, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	throughput := float64(stats.Calls) / stats.Total.Seconds()
+	fmt.Fprintf(out, "Calls:       %d (%d succeeded, %d failed)\n", stats.Calls, stats.Successes, stats.Failures)
+	fmt.Fprintf(out, "Concurrency: %d\n", stats.Concurrency)
+	fmt.Fprintf(out, "Total time:  %v (%.1f calls/s)\n", roundLatency(stats.Total), throughput)
+	fmt.Fprintf(out, "Latency:     min %v, mean %v, p50 %v, p95 %v, max %v\n",
+		roundLatency(stats.Min), roundLatency(stats.Mean), roundLatency(stats.P50), roundLatency(stats.P95), roundLatency(stats.Max))
+}
+
+// durationMillis converts a duration to fractional milliseconds.
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// roundLatency rounds a latency for display.
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+// completeCallArgs completes the arguments of the call command. The server position is
+// completed with server aliases. Shells only pass the words before the cursor, so a server
+// given after the entity isn't seen while the entity is completed; the entity is completed
+// with the names of the server's tools when the server is known without it, which is when
+// the project's .mcp.json defines a single server. A params flag given after the server, as
+// in "mcp call read_file myfs -p <tab>", is completed with the parameters of the tool's
+// input schema.
+func completeCallArgs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Drop the params flag, which ProcessFlags leaves in place, and --raw, which it rejects
+	withoutParams := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if (args[i] == FlagParams || args[i] == FlagParamsShort) && i+1 < len(args) {
+			i++
+			continue
+		}
+		if args[i] == FlagRaw {
+			continue
+		}
+		withoutParams = append(withoutParams, args[i])
+	}
+	if n := len(withoutParams); n > 0 && (withoutParams[n-1] == FlagParams || withoutParams[n-1] == FlagParamsShort) {
+		return completeParamNames(ProcessFlags(withoutParams[:n-1]), toComplete)
+	}
+	positional := ProcessFlags(withoutParams)
+
+	if len(positional) == 0 {
+		return completeToolNames(nil, toComplete)
+	}
+
+	// With the entity already given, a lone server word may be an alias
+	if len(positional) == 1 {
+		aliases, err := alias.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return names, cobra.ShellCompDirectiveDefault
+	}
+
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+// completionToolsKey returns the cache key of the server of a call being completed. Without
+// server args, the server of the project's .mcp.json is used when it defines a single one. The
+// key is found by resolving aliases and config files only: completion never starts the server
+// command, which the user may still be typing. ok is false when no server is known.
+func completionToolsKey(serverArgs []string) ([]string, bool) {
+	args, target, err := resolveServerArgs(serverArgs)
+	if err != nil {
+		return nil, false
+	}
+	conn, err := resolveConnection(args, target)
+	if err != nil {
+		return nil, false
+	}
+	return conn.key, true
+}
+
+// completionTools returns the cached tools of the server of a call being completed. Nothing
+// is completed until a call or listing has cached the server's tools.
+func completionTools(serverArgs []string) ([]mcp.Tool, bool) {
+	key, ok := completionToolsKey(serverArgs)
+	if !ok {
+		return nil, false
+	}
+	return cache.LoadTools(key, cache.DefaultTTL)
+}
+
+// completeToolNames completes a tool name with the names of the server's cached tools.
+func completeToolNames(serverArgs []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tools, ok := completionTools(serverArgs)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, tool := range tools {
+		if strings.HasPrefix(tool.Name, toComplete) {
+			names = append(names, tool.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeParamNames completes a params value with "name=" for each parameter of the tool's
+// cached input schema. positional holds the entity followed by the server, which may be left
+// out when the project's .mcp.json defines a single server.
+func completeParamNames(positional []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(positional) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tools, ok := completionTools(positional[1:])
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	index := slices.IndexFunc(tools, func(tool mcp.Tool) bool { return tool.Name == positional[0] })
+	if index < 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tool := tools[index]
+
+	var names []string
+	for name := range tool.InputSchema.Properties {
+		if candidate := name + "="; strings.HasPrefix(candidate, toComplete) {
+			names = append(names, candidate)
+		}
 	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 }
@@ -2,8 +2,27 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/f/mcptools/pkg/alias"
+	"github.com/f/mcptools/pkg/cache"
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/f/mcptools/pkg/template"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
 )
 
 func TestCallCmdRun_Help(t *testing.T) {
@@ -37,6 +56,10 @@ func TestCallCmdRun_Tool(t *testing.T) {
 	}
 
 	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		if method == "tools/list" {
+			// The schema is looked up to convert string params
+			return map[string]any{"tools": []any{}}, nil
+		}
 		if method != "tools/call" {
 			t.Errorf("Expected method 'tools/call', got %q", method)
 		}
@@ -62,6 +85,250 @@ func TestCallCmdRun_Tool(t *testing.T) {
 	assertEquals(t, output, expectedOutput)
 }
 
+func TestCallCmdRun_ArgFile(t *testing.T) {
+	code := "package main\n\nfunc main() {}\n"
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte(code), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var arguments map[string]any
+	cleanup := setupMockClient(func(method string, params any) (map[string]any, error) {
+		if method == "tools/list" {
+			return map[string]any{"tools": []any{}}, nil
+		}
+		data, _ := json.Marshal(params)
+		var request struct {
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(data, &request)
+		arguments = request.Arguments
+		return map[string]any{"content": []any{map[string]any{"type": "text", "text": "formatted"}}}, nil
+	})
+	defer cleanup()
+
+	cmd := CallCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"format_code", "--params", `{"language":"go","code":"ignored"}`, "--arg-file", "code=" + path, "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	want := map[string]any{"language": "go", "code": code}
+	if !reflect.DeepEqual(arguments, want) {
+		t.Errorf("arguments = %v, want %v", arguments, want)
+	}
+}
+
+func TestParseCallParams(t *testing.T) {
+	params, err := parseCallParams([]string{
+		`{"limit":10,"name":"old"}`,
+		"name=report",
+		`tags=["a","b"]`,
+		"tags[]=c",
+		"paths[]=/tmp",
+		"paths[]=/var",
+		`filter={"status":"open"}`,
+		"expr=a=b",
+	})
+	if err != nil {
+		t.Fatalf("parseCallParams() error = %v", err)
+	}
+	want := map[string]any{
+		"limit":  float64(10),
+		"name":   "report",
+		"tags":   []any{"a", "b", "c"},
+		"paths":  []any{"/tmp", "/var"},
+		"filter": map[string]any{"status": "open"},
+		"expr":   "a=b",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("params = %v, want %v", params, want)
+	}
+
+	for _, values := range [][]string{{"name"}, {"=value"}, {"{broken"}, {"note=[draft"}, {"name=a", "name[]=b"}} {
+		if _, err := parseCallParams(values); err == nil {
+			t.Errorf("parseCallParams(%q) returned no error", values)
+		}
+	}
+}
+
+func TestCoerceToolParams(t *testing.T) {
+	schema := map[string]any{
+		"tools": []any{map[string]any{
+			"name": "search",
+			"inputSchema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"count":  map[string]any{"type": "integer"},
+					"ratio":  map[string]any{"type": []any{"null", "number"}},
+					"exact":  map[string]any{"type": "boolean"},
+					"tags":   map[string]any{"type": "array"},
+					"query":  map[string]any{"type": "string"},
+					"filter": map[string]any{"type": "object"},
+				},
+			},
+		}},
+	}
+	listed := 0
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		listed++
+		return schema, nil
+	})
+	defer cleanup()
+	mockClient, _ := CreateClientFunc(nil)
+
+	params := map[string]any{"count": "5", "ratio": "0.5", "exact": "true", "tags": "go", "query": "42", "filter": map[string]any{"a": "1"}, "extra": "7"}
+	coerced, err := coerceToolParams(mockClient, "search", params)
+	if err != nil {
+		t.Fatalf("coerceToolParams() error = %v", err)
+	}
+	want := map[string]any{"count": int64(5), "ratio": 0.5, "exact": true, "tags": []any{"go"}, "query": "42", "filter": map[string]any{"a": "1"}, "extra": "7"}
+	if !reflect.DeepEqual(coerced, want) {
+		t.Errorf("coerced = %v, want %v", coerced, want)
+	}
+	if params["count"] != "5" {
+		t.Errorf("params were modified: %v", params)
+	}
+
+	if _, err := coerceToolParams(mockClient, "search", map[string]any{"count": "five"}); err == nil || !strings.Contains(err.Error(), "count") {
+		t.Errorf("coerceToolParams() error = %v, want an invalid count", err)
+	}
+
+	// Unknown tools keep their string values
+	if coerced, err := coerceToolParams(mockClient, "other", map[string]any{"count": "5"}); err != nil || coerced["count"] != "5" {
+		t.Errorf("coerceToolParams() = %v, %v for an unknown tool", coerced, err)
+	}
+
+	// Params without strings don't need the schema
+	listed = 0
+	if _, err := coerceToolParams(mockClient, "search", map[string]any{"count": float64(5)}); err != nil || listed != 0 {
+		t.Errorf("coerceToolParams() listed tools %d times, error = %v", listed, err)
+	}
+}
+
+func TestCoerceToolParamsUsesCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer func() { NoCacheOption = false }()
+
+	schema := map[string]any{"tools": []any{map[string]any{
+		"name":        "search",
+		"inputSchema": map[string]any{"type": "object", "properties": map[string]any{"count": map[string]any{"type": "integer"}}},
+	}}}
+	listed := 0
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		listed++
+		return schema, nil
+	})
+	defer cleanup()
+	mockClient, _ := CreateClientFunc(nil)
+
+	serverKeys.Store(mockClient, newServerKey("stdio", []string{"npx", "-y", "server"}, nil, nil))
+	for range 2 {
+		coerced, err := coerceToolParams(mockClient, "search", map[string]any{"count": "5"})
+		if err != nil || coerced["count"] != int64(5) {
+			t.Fatalf("coerceToolParams() = %v, %v", coerced, err)
+		}
+	}
+	if listed != 1 {
+		t.Errorf("tools were listed %d times, want once and then taken from the cache", listed)
+	}
+
+	// Tools missing from the cache are listed again, and so are all tools with --no-cache
+	_, _ = coerceToolParams(mockClient, "other", map[string]any{"count": "5"})
+	NoCacheOption = true
+	_, _ = coerceToolParams(mockClient, "search", map[string]any{"count": "5"})
+	if listed != 3 {
+		t.Errorf("tools were listed %d times, want 3", listed)
+	}
+}
+
+func TestReadArgFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("a=b"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := readArgFiles(nil, []string{"text=" + path})
+	if err != nil {
+		t.Fatalf("readArgFiles() error = %v", err)
+	}
+	if params["text"] != "a=b" {
+		t.Errorf("params = %v", params)
+	}
+
+	for _, spec := range []string{"text", "=" + path, "text=", "text=" + path + ".missing"} {
+		if _, err := readArgFiles(nil, []string{spec}); err == nil {
+			t.Errorf("readArgFiles(%q) returned no error", spec)
+		}
+	}
+}
+
+func TestCallCmdRun_Template(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	originalParams := ParamsString
+	ParamsString = ""
+	defer func() { ParamsString = originalParams }()
+
+	var toolName string
+	var arguments map[string]any
+	cleanup := setupMockClient(func(method string, params any) (map[string]any, error) {
+		if method == "tools/list" {
+			return map[string]any{"tools": []any{}}, nil
+		}
+		data, _ := json.Marshal(params)
+		var request struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(data, &request)
+		toolName, arguments = request.Name, request.Arguments
+		return map[string]any{"content": []any{map[string]any{"type": "text", "text": "found"}}}, nil
+	})
+	defer cleanup()
+	var server []string
+	mockClientFunc := CreateClientFunc
+	CreateClientFunc = func(args []string, opts ...client.ClientOption) (*client.Client, error) {
+		server = args
+		return mockClientFunc(args, opts...)
+	}
+
+	call := func(args ...string) {
+		t.Helper()
+		cmd := CallCmd()
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("cmd.Execute() error = %v", err)
+		}
+	}
+
+	call("search", "-p", "query=mcp", "-p", "limit=5", "--save-template", "find", "my-server", "--flag")
+	saved, err := template.Load("find")
+	if err != nil {
+		t.Fatalf("template.Load() error = %v", err)
+	}
+	want := &template.Template{Entity: "search", Arguments: map[string]any{"query": "mcp", "limit": "5"}, Server: []string{"my-server", "--flag"}}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved template = %+v, want %+v", saved, want)
+	}
+
+	toolName, arguments, server = "", nil, nil
+	call("--template", "find", "-p", "limit=10")
+	if toolName != "search" || !reflect.DeepEqual(arguments, map[string]any{"query": "mcp", "limit": "10"}) {
+		t.Errorf("replayed call = %s %v", toolName, arguments)
+	}
+	if !reflect.DeepEqual(server, []string{"my-server", "--flag"}) {
+		t.Errorf("replayed server = %v", server)
+	}
+
+	call("--template", "find", "other-server")
+	if !reflect.DeepEqual(server, []string{"other-server"}) {
+		t.Errorf("server = %v, want the server given on the command line", server)
+	}
+}
+
 func TestCallCmdRun_Resource(t *testing.T) {
 	// Create a mock client that returns successful response
 	mockResponse := map[string]any{
@@ -99,3 +366,420 @@ func TestCallCmdRun_Resource(t *testing.T) {
 	expectedOutput := `{"contents":[{"mimeType":"text/plain","text":"bar","uri":"test://foo"}]}`
 	assertContains(t, output, expectedOutput)
 }
+
+func TestCallCmdRun_Raw(t *testing.T) {
+	originalFunc, originalRaw := CreateClientFunc, RawOutput
+	defer func() { CreateClientFunc, RawOutput = originalFunc, originalRaw }()
+
+	// The raw output keeps fields the client doesn't know about and long values intact
+	longText := strings.Repeat("x", 80)
+	mockClient := client.NewClient(mcpclient.NewRecording(&MockTransport{
+		ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+			return map[string]any{
+				"content": []any{map[string]any{"type": "text", "text": longText}},
+				"custom":  map[string]any{"count": 1},
+			}, nil
+		},
+	}))
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+	CreateClientFunc = func(_ []string, _ ...client.ClientOption) (*client.Client, error) {
+		return mockClient, nil
+	}
+
+	cmd := CallCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"test-tool", "--raw", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	expected := `{"content":[{"text":"` + longText + `","type":"text"}],"custom":{"count":1}}`
+	assertEquals(t, strings.TrimSpace(buf.String()), expected)
+}
+
+func TestRawFlagOnlyOnSingleResultCommands(t *testing.T) {
+	for _, cmd := range []*cobra.Command{CallCmd(), ReadResourceCmd(), GetPromptCmd()} {
+		if cmd.Flags().Lookup("raw") == nil {
+			t.Errorf("Expected %s to register --raw", cmd.Name())
+		}
+	}
+	for _, cmd := range []*cobra.Command{ToolsCmd(), ResourcesCmd(), PromptsCmd()} {
+		if cmd.Flags().Lookup("raw") != nil {
+			t.Errorf("Expected %s not to register --raw", cmd.Name())
+		}
+	}
+}
+
+func TestCallEntityStructuredContent(t *testing.T) {
+	mockClient := client.NewClient(mcpclient.NewRecording(&MockTransport{
+		ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+			return map[string]any{
+				"content":           []any{map[string]any{"type": "text", "text": `{"files":3}`}},
+				"structuredContent": map[string]any{"files": 3},
+			}, nil
+		},
+	}))
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+
+	resp, err := callEntity(mockClient, EntityTypeTool, "stats", nil, nil)
+	if err != nil {
+		t.Fatalf("callEntity() error = %v", err)
+	}
+	if !reflect.DeepEqual(resp["structuredContent"], map[string]any{"files": float64(3)}) {
+		t.Errorf("Expected the structured content to be kept, got %v", resp["structuredContent"])
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := summarizeLatencies(latencies)
+
+	testCases := []struct {
+		name     string
+		got      time.Duration
+		expected time.Duration
+	}{
+		{name: "min", got: stats.Min, expected: 1 * time.Millisecond},
+		{name: "max", got: stats.Max, expected: 100 * time.Millisecond},
+		{name: "mean", got: stats.Mean, expected: 50500 * time.Microsecond},
+		{name: "p50", got: stats.P50, expected: 50 * time.Millisecond},
+		{name: "p95", got: stats.P95, expected: 95 * time.Millisecond},
+	}
+	for _, tc := range testCases {
+		if tc.got != tc.expected {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.expected)
+		}
+	}
+	if stats.Calls != 100 {
+		t.Errorf("Calls = %d, want 100", stats.Calls)
+	}
+
+	if empty := summarizeLatencies(nil); empty.Calls != 0 || empty.Max != 0 {
+		t.Errorf("summarizeLatencies(nil) = %+v", empty)
+	}
+}
+
+func TestCallErrorExitCode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := alias.Save(alias.Aliases{"broken": {Command: "config:missing/server"}}); err != nil {
+		t.Fatalf("alias.Save() error = %v", err)
+	}
+
+	// Errors of the client factory are wrapped as in callWithRetries
+	createErr := func(args []string, setup func()) error {
+		originalVersion, originalHeaders, originalEnv := ProtocolVersionOption, HeaderOptions, ServerEnvOptions
+		defer func() {
+			ProtocolVersionOption, HeaderOptions, ServerEnvOptions = originalVersion, originalHeaders, originalEnv
+		}()
+		setup()
+		_, err := CreateClientFunc(args)
+		if err == nil {
+			t.Fatalf("CreateClientFunc(%v) succeeded, want an error", args)
+		}
+		return &connectError{err: err}
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "transport error", err: fmt.Errorf("transport error: %w", &mcpclient.TransportError{Err: errors.New("server process exited")}), expected: exitCodeConnection},
+		{name: "JSON-RPC error", err: errors.New("Unknown tool: missing"), expected: exitCodeProtocol},
+		{name: "JSON-RPC error that looks like a transport error", err: errors.New("transport error: upstream down"), expected: exitCodeProtocol},
+		{name: "invalid param", err: &invalidParamError{name: "count", err: errors.New("not an integer")}, expected: exitCodeInvalidArgs},
+		{name: "connection failed", err: &connectError{err: errors.New("failed to start command")}, expected: exitCodeConnection},
+		{name: "unsupported protocol version", err: createErr([]string{"true"}, func() { ProtocolVersionOption = "1999-01-01" }), expected: exitCodeInvalidArgs},
+		{name: "malformed header", err: createErr([]string{"http://localhost:1/mcp"}, func() { HeaderOptions = []string{"no colon"} }), expected: exitCodeInvalidArgs},
+		{name: "malformed env", err: createErr([]string{"true"}, func() { ServerEnvOptions = []string{"NOVALUE"} }), expected: exitCodeInvalidArgs},
+		{name: "unresolvable alias", err: createErr([]string{"broken"}, func() {}), expected: exitCodeInvalidArgs},
+		{name: "server command that fails to start", err: createErr([]string{"/nonexistent/mcp-server"}, func() {}), expected: exitCodeConnection},
+	}
+	for _, tc := range testCases {
+		if got := callErrorExitCode(tc.err); got != tc.expected {
+			t.Errorf("%s: callErrorExitCode() = %d, want %d", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestIsRetryableCallError(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	statusErr := func(code int) error {
+		status = code
+		httpTransport, err := mcpclient.NewStreamableHTTP(server.URL, mcpclient.HTTPOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = mcpclient.NewRecording(httpTransport).SendRequest(context.Background(), transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call"})
+		return fmt.Errorf("transport error: %w", err)
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "connection dropped", err: fmt.Errorf("transport error: %w", &mcpclient.TransportError{Err: errors.New("server process exited")}), expected: true},
+		{name: "connection failed", err: &connectError{err: errors.New("connection refused")}, expected: true},
+		{name: "service unavailable", err: statusErr(http.StatusServiceUnavailable), expected: true},
+		{name: "unauthorized", err: statusErr(http.StatusUnauthorized), expected: false},
+		{name: "JSON-RPC error", err: errors.New("Unknown tool: missing"), expected: false},
+	}
+	for _, tc := range testCases {
+		if got := isRetryableCallError(tc.err); got != tc.expected {
+			t.Errorf("%s: isRetryableCallError(%v) = %v, want %v", tc.name, tc.err, got, tc.expected)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, want := range expected {
+		if got := retryBackoff(time.Second, i+1); got != want {
+			t.Errorf("retryBackoff(1s, %d) = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := retryBackoff(time.Second, 40); got != maxRetryDelay {
+		t.Errorf("retryBackoff(1s, 40) = %v, want %v", got, maxRetryDelay)
+	}
+}
+
+func TestCallWithRetries(t *testing.T) {
+	failures := 2
+	calls := 0
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("connection reset")
+		}
+		return map[string]any{"content": []any{map[string]any{"type": "text", "text": "ok"}}}, nil
+	})
+	defer cleanup()
+
+	_, resp, attempts, err := callWithRetries([]string{"server"}, EntityTypeTool, "flaky", nil, true, 3, time.Millisecond)
+	if err != nil || attempts != 3 || resp == nil {
+		t.Errorf("callWithRetries() = %v, %d attempts, %v; want a result after 3 attempts", resp, attempts, err)
+	}
+
+	calls = 0
+	failures = 10
+	_, _, attempts, err = callWithRetries([]string{"server"}, EntityTypeTool, "flaky", nil, true, 2, time.Millisecond)
+	if err == nil || attempts != 3 {
+		t.Errorf("callWithRetries() = %d attempts, %v; want an error after 3 attempts", attempts, err)
+	}
+}
+
+func TestCompleteCallArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	if err := alias.Save(alias.Aliases{
+		"myfs":   {Command: "npx -y @modelcontextprotocol/server-filesystem ~"},
+		"mytime": {Command: "uvx mcp-server-time"},
+		"other":  {Command: "other-server"},
+	}); err != nil {
+		t.Fatalf("alias.Save() error = %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		toComplete string
+		expected   []string
+		directive  cobra.ShellCompDirective
+	}{
+		{
+			name:      "entity",
+			args:      []string{},
+			directive: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "server alias",
+			args:       []string{"read_file"},
+			toComplete: "my",
+			expected:   []string{"myfs", "mytime"},
+			directive:  cobra.ShellCompDirectiveDefault,
+		},
+		{
+			name:      "server alias after params",
+			args:      []string{"read_file", "--params", `{"path":"x"}`},
+			expected:  []string{"myfs", "mytime", "other"},
+			directive: cobra.ShellCompDirectiveDefault,
+		},
+		{
+			name:      "server arguments",
+			args:      []string{"read_file", "npx"},
+			directive: cobra.ShellCompDirectiveDefault,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := completeCallArgs(nil, tc.args, tc.toComplete)
+			if len(completions) != 0 || len(tc.expected) != 0 {
+				if !reflect.DeepEqual(completions, tc.expected) {
+					t.Errorf("completions = %v, want %v", completions, tc.expected)
+				}
+			}
+			if directive != tc.directive {
+				t.Errorf("directive = %v, want %v", directive, tc.directive)
+			}
+		})
+	}
+}
+
+// failOnConnect makes the test fail if a client is created, as completion must not start servers.
+func failOnConnect(t *testing.T) {
+	t.Helper()
+	originalFunc := CreateClientFunc
+	CreateClientFunc = func(args []string, _ ...client.ClientOption) (*client.Client, error) {
+		t.Errorf("Expected completion not to connect to %v", args)
+		return nil, errors.New("unexpected connection")
+	}
+	t.Cleanup(func() { CreateClientFunc = originalFunc })
+}
+
+func TestCompleteCallParams(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	failOnConnect(t)
+
+	tools := []mcp.Tool{{
+		Name: "read_file",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"path":     map[string]any{"type": "string"},
+				"encoding": map[string]any{"type": "string"},
+				"page":     map[string]any{"type": "integer"},
+			},
+		},
+	}}
+	for _, args := range [][]string{{"myfs"}, {"npx", "server"}} {
+		if err := cache.SaveTools(newServerKey("stdio", args, nil, nil), tools); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{
+			name:     "all parameters",
+			args:     []string{"read_file", "myfs", "--params"},
+			expected: []string{"encoding=", "page=", "path="},
+		},
+		{
+			name:       "parameters with the prefix",
+			args:       []string{"read_file", "npx", "server", "-p", `{"page":1}`, "-p"},
+			toComplete: "pa",
+			expected:   []string{"page=", "path="},
+		},
+		{
+			name: "unknown tool",
+			args: []string{"write_file", "myfs", "-p"},
+		},
+		{
+			name: "no server yet",
+			args: []string{"read_file", "-p"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := completeCallArgs(nil, tc.args, tc.toComplete)
+			if !reflect.DeepEqual(completions, tc.expected) {
+				t.Errorf("completions = %v, want %v", completions, tc.expected)
+			}
+			if directive&cobra.ShellCompDirectiveNoFileComp == 0 {
+				t.Errorf("directive = %v, want file completion turned off", directive)
+			}
+		})
+	}
+}
+
+func TestCompleteCallToolNames(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	failOnConnect(t)
+
+	tools := []mcp.Tool{
+		{Name: "write_file", InputSchema: mcp.ToolInputSchema{Type: "object"}},
+		{
+			Name: "read_file",
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: map[string]any{"path": map[string]any{"type": "string"}},
+			},
+		},
+		{Name: "list_directory", InputSchema: mcp.ToolInputSchema{Type: "object"}},
+	}
+	key := newServerKey("stdio", []string{"npx", "-y", "server-filesystem", "."}, nil, nil)
+
+	// Without a .mcp.json the server isn't known yet
+	if completions, _ := completeCallArgs(nil, []string{}, ""); completions != nil {
+		t.Errorf("completions = %v without a server, want none", completions)
+	}
+
+	config := `{"mcpServers": {"fs": {"command": "npx", "args": ["-y", "server-filesystem", "."]}}}`
+	if err := os.WriteFile(filepath.Join(dir, ".mcp.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Until the server's tools are cached, nothing is completed
+	if completions, _ := completeCallArgs(nil, []string{}, ""); completions != nil {
+		t.Errorf("completions = %v without cached tools, want none", completions)
+	}
+	if err := cache.SaveTools(key, tools); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{
+			name:     "all tools",
+			args:     []string{},
+			expected: []string{"list_directory", "read_file", "write_file"},
+		},
+		{
+			name:       "tools with the prefix",
+			args:       []string{},
+			toComplete: "re",
+			expected:   []string{"read_file"},
+		},
+		{
+			name:     "parameters from the project server",
+			args:     []string{"read_file", "-p"},
+			expected: []string{"path="},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := completeCallArgs(nil, tc.args, tc.toComplete)
+			if !reflect.DeepEqual(completions, tc.expected) {
+				t.Errorf("completions = %v, want %v", completions, tc.expected)
+			}
+			if directive&cobra.ShellCompDirectiveNoFileComp == 0 {
+				t.Errorf("directive = %v, want file completion turned off", directive)
+			}
+		})
+	}
+}
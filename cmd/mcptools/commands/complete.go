@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// CompleteCmd creates the complete command.
+func CompleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "complete ref argument value [command args...]",
+		Short: "Ask the MCP server to complete a prompt or resource argument",
+		Long: `Ask an MCP server that supports completions for the values an argument of a prompt or resource
+template may take, given what has been typed so far. The reference is a prompt name, written as
+name or prompt:name, or a resource template written as resource:uri. Use -- to separate the
+server command, and pass "" as the value to list every suggestion.
+
+  mcp complete code_review language py -- npx -y @modelcontextprotocol/server-everything
+  mcp complete 'resource:test://static/resource/{id}' id 1 -- npx -y @modelcontextprotocol/server-everything`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			parsedArgs := ProcessFlags(args)
+			if len(parsedArgs) < 3 {
+				fmt.Fprintln(os.Stderr, "Error: a reference, an argument name and a value are required")
+				fmt.Fprintln(os.Stderr, "Example: mcp complete code_review language py -- npx -y @modelcontextprotocol/server-everything")
+				os.Exit(1)
+			}
+			positional, serverArgs := parsedArgs[:3], parsedArgs[3:]
+			if len(serverArgs) > 0 && serverArgs[0] == "--" {
+				serverArgs = serverArgs[1:]
+			}
+
+			ref, refErr := completionRef(positional[0])
+			if refErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", refErr)
+				os.Exit(1)
+			}
+
+			mcpClient, err := CreateClientFunc(serverArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			result, completeErr := complete(mcpClient, ref, positional[1], positional[2])
+			if completeErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", completeErr)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+
+			if jsonutils.ParseFormat(FormatOption) != jsonutils.FormatTable {
+				if formatErr := FormatAndPrintResponse(thisCmd, ConvertJSONToMap(result.Completion), nil); formatErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+					os.Exit(1)
+				}
+				return
+			}
+
+			out := thisCmd.OutOrStdout()
+			for _, value := range result.Completion.Values {
+				fmt.Fprintln(out, value)
+			}
+			if more := result.Completion.Total - len(result.Completion.Values); more > 0 {
+				fmt.Fprintf(os.Stderr, "(%d more not shown)\n", more)
+			} else if result.Completion.HasMore {
+				fmt.Fprintln(os.Stderr, "(more not shown)")
+			}
+		},
+	}
+}
+
+// completionRef parses a completion reference: a prompt name, optionally prefixed with
+// "prompt:", or a resource URI prefixed with "resource:".
+func completionRef(ref string) (any, error) {
+	entityType, name, found := strings.Cut(ref, ":")
+	switch {
+	case !found:
+		return mcp.PromptReference{Type: "ref/prompt", Name: ref}, nil
+	case entityType == EntityTypePrompt:
+		return mcp.PromptReference{Type: "ref/prompt", Name: name}, nil
+	case entityType == EntityTypeRes:
+		return mcp.ResourceReference{Type: "ref/resource", URI: name}, nil
+	default:
+		return nil, fmt.Errorf("unsupported reference type %q, use prompt:<name> or resource:<uri>", entityType)
+	}
+}
+
+// complete asks the server for the completions of an argument of ref.
+func complete(mcpClient *client.Client, ref any, argument, value string) (*mcp.CompleteResult, error) {
+	request := mcp.CompleteRequest{}
+	request.Params.Ref = ref
+	request.Params.Argument.Name = argument
+	request.Params.Argument.Value = value
+	return mcpClient.Complete(context.Background(), request)
+}
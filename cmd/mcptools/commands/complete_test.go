@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCompleteCmdRun(t *testing.T) {
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "table"
+
+	var sentParams map[string]any
+	cleanup := setupMockClient(func(method string, params any) (map[string]any, error) {
+		if method != "completion/complete" {
+			t.Errorf("Expected method 'completion/complete', got %q", method)
+		}
+		data, _ := json.Marshal(params)
+		_ = json.Unmarshal(data, &sentParams)
+		return map[string]any{
+			"completion": map[string]any{"values": []any{"python", "perl"}},
+		}, nil
+	})
+	defer cleanup()
+
+	cmd := CompleteCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"code_review", "language", "p", "--", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("cmd.Execute() error = %v", err)
+	}
+
+	if output := buf.String(); output != "python\nperl\n" {
+		t.Errorf("output = %q, want one value per line", output)
+	}
+
+	expected := map[string]any{
+		"ref":      map[string]any{"type": "ref/prompt", "name": "code_review"},
+		"argument": map[string]any{"name": "language", "value": "p"},
+	}
+	if !reflect.DeepEqual(sentParams, expected) {
+		t.Errorf("params = %v, want %v", sentParams, expected)
+	}
+}
+
+func TestCompletionRef(t *testing.T) {
+	testCases := []struct {
+		ref      string
+		expected any
+		wantErr  bool
+	}{
+		{ref: "code_review", expected: mcp.PromptReference{Type: "ref/prompt", Name: "code_review"}},
+		{ref: "prompt:code_review", expected: mcp.PromptReference{Type: "ref/prompt", Name: "code_review"}},
+		{ref: "resource:file:///{path}", expected: mcp.ResourceReference{Type: "ref/resource", URI: "file:///{path}"}},
+		{ref: "tool:read_file", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := completionRef(tc.ref)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("completionRef(%q) expected an error", tc.ref)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("completionRef(%q) = %v, %v, want %v", tc.ref, got, err, tc.expected)
+		}
+	}
+}
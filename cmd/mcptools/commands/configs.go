@@ -2,15 +2,24 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/f/mcptools/pkg/alias"
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -33,6 +42,7 @@ type ServerConfig struct {
 // Constants for common strings.
 const (
 	defaultJSONPath = "$.mcpServers"
+	zedJSONPath     = "$.context_servers"
 	formatJSON      = "json"
 	formatPretty    = "pretty"
 	formatTable     = "table"
@@ -54,6 +64,15 @@ var EnvOption string
 // URLOption stores the URL for URL-based servers.
 var URLOption string
 
+// NoBackupOption disables the backup copy made before a config file is overwritten.
+var NoBackupOption bool
+
+// ForceOption allows rewriting a config file whose comments and trailing commas would be lost.
+var ForceOption bool
+
+// JSONPathOption overrides the JSONPath of the servers object in a config file.
+var JSONPathOption string
+
 // ConfigAlias represents a configuration alias.
 type ConfigAlias struct {
 	Path     string `json:"path"`
@@ -81,6 +100,52 @@ func getConfigsFilePath() (string, error) {
 	return filepath.Join(configDir, "configs.json"), nil
 }
 
+// defaultConfigAliases returns the aliases of the supported editors and clients.
+func defaultConfigAliases() map[string]ConfigAlias {
+	return map[string]ConfigAlias{
+		"vscode": {
+			Path:     appConfigPath("Code", "User", "settings.json"),
+			JSONPath: "$.mcp.servers",
+			Source:   "VS Code",
+		},
+		"vscode-insiders": {
+			Path:     appConfigPath("Code - Insiders", "User", "settings.json"),
+			JSONPath: "$.mcp.servers",
+			Source:   "VS Code Insiders",
+		},
+		"windsurf": {
+			Path:     "~/.codeium/windsurf/mcp_config.json",
+			JSONPath: defaultJSONPath,
+			Source:   "Windsurf",
+		},
+		"cursor": {
+			Path:     "~/.cursor/mcp.json",
+			JSONPath: defaultJSONPath,
+			Source:   "Cursor",
+		},
+		"claude-desktop": {
+			Path:     appConfigPath("Claude", "claude_desktop_config.json"),
+			JSONPath: defaultJSONPath,
+			Source:   "Claude Desktop",
+		},
+		"claude-code": {
+			Path:     "~/.claude.json",
+			JSONPath: defaultJSONPath,
+			Source:   "Claude Code",
+		},
+		"zed": {
+			Path:     zedSettingsPath(),
+			JSONPath: zedJSONPath,
+			Source:   "Zed",
+		},
+		"cline": {
+			Path:     clineSettingsPath(),
+			JSONPath: defaultJSONPath,
+			Source:   "Cline",
+		},
+	}
+}
+
 // loadConfigsFile loads the configs file, creating it if it doesn't exist.
 func loadConfigsFile() (*ConfigsFile, error) {
 	configsPath, err := getConfigsFilePath()
@@ -91,47 +156,14 @@ func loadConfigsFile() (*ConfigsFile, error) {
 	// Create default config if file doesn't exist
 	fileInfo, statErr := os.Stat(configsPath) //nolint:govet // Intentional shadow
 	if os.IsNotExist(statErr) {
-		defaultConfig := &ConfigsFile{
-			Aliases: map[string]ConfigAlias{
-				"vscode": {
-					Path:     "~/Library/Application Support/Code/User/settings.json",
-					JSONPath: "$.mcp.servers",
-					Source:   "VS Code",
-				},
-				"vscode-insiders": {
-					Path:     "~/Library/Application Support/Code - Insiders/User/settings.json",
-					JSONPath: "$.mcp.servers",
-					Source:   "VS Code Insiders",
-				},
-				"windsurf": {
-					Path:     "~/.codeium/windsurf/mcp_config.json",
-					JSONPath: defaultJSONPath,
-					Source:   "Windsurf",
-				},
-				"cursor": {
-					Path:     "~/.cursor/mcp.json",
-					JSONPath: defaultJSONPath,
-					Source:   "Cursor",
-				},
-				"claude-desktop": {
-					Path:     "~/Library/Application Support/Claude/claude_desktop_config.json",
-					JSONPath: defaultJSONPath,
-					Source:   "Claude Desktop",
-				},
-				"claude-code": {
-					Path:     "~/.claude.json",
-					JSONPath: defaultJSONPath,
-					Source:   "Claude Code",
-				},
-			},
-		}
+		defaultConfig := &ConfigsFile{Aliases: defaultConfigAliases()}
 
 		configData, marshalErr := json.MarshalIndent(defaultConfig, "", "  ")
 		if marshalErr != nil {
 			return nil, fmt.Errorf("failed to marshal default config: %w", marshalErr)
 		}
 
-		if writeErr := os.WriteFile(configsPath, configData, filePermissions); writeErr != nil { //nolint:gosec // User config file
+		if writeErr := atomicWriteFile(configsPath, configData, filePermissions); writeErr != nil {
 			return nil, fmt.Errorf("failed to write default config: %w", writeErr)
 		}
 
@@ -164,6 +196,13 @@ func loadConfigsFile() (*ConfigsFile, error) {
 		config.Aliases = make(map[string]ConfigAlias)
 	}
 
+	// Add default aliases introduced after the file was created
+	for name, alias := range defaultConfigAliases() {
+		if _, ok := config.Aliases[name]; !ok {
+			config.Aliases[name] = alias
+		}
+	}
+
 	return &config, nil
 }
 
@@ -179,7 +218,104 @@ func saveConfigsFile(config *ConfigsFile) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	return os.WriteFile(configsPath, data, filePermissions) //nolint:gosec // User config file
+	return atomicWriteFile(configsPath, data, filePermissions)
+}
+
+// writeConfigFile replaces a config file of an editor or client. Unless NoBackupOption is
+// set, the current file is first copied to a timestamped .bak file next to it. Config files
+// are written as plain JSON, so a file with comments or trailing commas, such as the settings
+// of VS Code or Zed, is only replaced when ForceOption is set.
+func writeConfigFile(configFile string, data []byte) error {
+	// Write through symlinks so config files managed as dotfiles stay links
+	if resolved, err := filepath.EvalSymlinks(configFile); err == nil {
+		configFile = resolved
+	}
+
+	existing, err := os.ReadFile(configFile) //nolint:gosec // User config file
+	if err == nil && !ForceOption && isJSONC(existing) {
+		return fmt.Errorf("%s has comments or trailing commas, which rewriting it would remove; use --force to rewrite it anyway", configFile)
+	}
+
+	if err == nil && !NoBackupOption {
+		backupFile := configFile + "." + time.Now().Format("20060102150405") + ".bak"
+		if err := os.WriteFile(backupFile, existing, filePermissions); err != nil { //nolint:gosec // User config file
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	return atomicWriteFile(configFile, data, filePermissions)
+}
+
+// atomicWriteFile writes data to a temporary file next to path and renames it over path, so
+// a failed write never leaves a truncated file behind. An existing file keeps its permissions.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
+
+// appConfigPath returns the path of a file in the per-user application config directory of
+// the current platform: ~/Library/Application Support on macOS, %APPDATA% on Windows and
+// $XDG_CONFIG_HOME (or ~/.config) elsewhere.
+func appConfigPath(elem ...string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return path.Join(append([]string{"~/Library/Application Support"}, elem...)...)
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(append([]string{appData}, elem...)...)
+		}
+		return path.Join(append([]string{"~/AppData/Roaming"}, elem...)...)
+	default:
+		if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+			return filepath.Join(append([]string{configHome}, elem...)...)
+		}
+		return path.Join(append([]string{"~/.config"}, elem...)...)
+	}
+}
+
+// zedSettingsPath returns the path of the Zed settings file, which lives in ~/.config/zed
+// on macOS as well.
+func zedSettingsPath() string {
+	if runtime.GOOS == "darwin" {
+		return "~/.config/zed/settings.json"
+	}
+	if runtime.GOOS == "windows" {
+		return appConfigPath("Zed", "settings.json")
+	}
+	return appConfigPath("zed", "settings.json")
+}
+
+// clineSettingsPath returns the path of the MCP settings file of the Cline VS Code extension.
+func clineSettingsPath() string {
+	return appConfigPath("Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json")
 }
 
 // expandPath expands the ~ in the path.
@@ -215,6 +351,98 @@ func parseKeyValueOption(option string) (map[string]string, error) {
 	return result, nil
 }
 
+// containerRuntime returns "docker" or "podman" when a server command runs a container
+// runtime, and an empty string otherwise.
+func containerRuntime(command string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(command)), ".exe")
+	if name == "docker" || name == "podman" {
+		return name
+	}
+	return ""
+}
+
+// containerValueFlags are the options of docker run and podman run that take a separate value,
+// used to find where the options end and the image begins.
+var containerValueFlags = map[string]bool{
+	"-e": true, "--env": true, "--env-file": true, "-v": true, "--volume": true, "--mount": true,
+	"-p": true, "--publish": true, "--name": true, "--network": true, "--net": true,
+	"-w": true, "--workdir": true, "-u": true, "--user": true, "--entrypoint": true,
+	"-l": true, "--label": true, "--platform": true, "--pull": true, "-m": true, "--memory": true,
+	"--cpus": true, "--add-host": true, "--device": true, "-h": true, "--hostname": true,
+	"--restart": true,
+}
+
+// structureContainerRun rewrites the args of a docker run or podman run server so that its
+// environment is kept in the env map: -e KEY=VALUE options before the image become -e KEY with
+// the value in env, and every env variable is forwarded with -e KEY. It also adds -i, without
+// which the container can't read requests from stdin. It returns the new args, the env and
+// whether -i was added.
+func structureContainerRun(args []string, env map[string]string) ([]string, map[string]string, bool) {
+	if len(args) == 0 || args[0] != "run" {
+		return args, env, false
+	}
+
+	merged := make(map[string]string, len(env))
+	forwarded := make(map[string]bool)
+	interactive := false
+	options := []string{}
+	i := 1
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			break // the image
+		}
+
+		var assignment string
+		switch {
+		case (arg == "-e" || arg == "--env") && i+1 < len(args):
+			i++
+			assignment = args[i]
+		case strings.HasPrefix(arg, "--env="):
+			assignment = strings.TrimPrefix(arg, "--env=")
+		default:
+			if arg == "--interactive" || arg == "--interactive=true" ||
+				(!strings.HasPrefix(arg, "--") && strings.Contains(arg[1:], "i") && !containerValueFlags[arg]) {
+				interactive = true
+			}
+			options = append(options, arg)
+			if containerValueFlags[arg] && i+1 < len(args) {
+				i++
+				options = append(options, args[i])
+			}
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(assignment, "=")
+		if hasValue {
+			merged[key] = value
+		}
+		forwarded[key] = true
+		options = append(options, "-e", key)
+	}
+
+	// Variables given with --env override the ones inline in the command
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		merged[key] = env[key]
+		if !forwarded[key] {
+			options = append(options, "-e", key)
+		}
+	}
+
+	result := []string{"run"}
+	if !interactive {
+		result = append(result, "-i")
+	}
+	result = append(result, options...)
+	result = append(result, args[i:]...)
+	return result, merged, !interactive
+}
+
 // getConfigFileAndPath gets the config file path and json path from an alias or direct file path.
 func getConfigFileAndPath(configs *ConfigsFile, aliasName, configFile string) (string, string, error) {
 	var jsonPath string
@@ -236,89 +464,188 @@ func getConfigFileAndPath(configs *ConfigsFile, aliasName, configFile string) (s
 	return configFile, jsonPath, nil
 }
 
-// readConfigFile reads and parses a config file.
+// readConfigFile reads and parses a config file, returning an empty config if it doesn't
+// exist. A file that exists but can't be parsed is an error, so that it isn't overwritten.
 func readConfigFile(configFile string) (map[string]interface{}, error) {
-	var configData map[string]interface{}
-	if _, err := os.Stat(configFile); err == nil {
-		// File exists, read and parse it
-		data, err := os.ReadFile(configFile) //nolint:gosec // File path is validated earlier
-		if err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
+	data, err := os.ReadFile(configFile) //nolint:gosec // File path is validated earlier
+	if os.IsNotExist(err) {
+		return make(map[string]interface{}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
 
-		if err := json.Unmarshal(data, &configData); err != nil {
-			// Create new empty config if file exists but isn't valid JSON
-			configData = make(map[string]interface{})
-		}
-	} else {
-		// Create new empty config if file doesn't exist
+	var configData map[string]interface{}
+	if err := unmarshalConfig(data, &configData); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s, leaving it unchanged: %w", configFile, err)
+	}
+	if configData == nil {
 		configData = make(map[string]interface{})
 	}
 	return configData, nil
 }
 
-// addServerToConfig adds a server configuration to the config data.
-func addServerToConfig(configData map[string]interface{}, jsonPath, serverName string, serverConfig map[string]interface{}) {
-	if strings.Contains(jsonPath, "mcp.servers") {
-		// VS Code format
-		if _, ok := configData["mcp"]; !ok {
-			configData["mcp"] = map[string]interface{}{}
-		}
+// unmarshalConfig parses the JSON of an editor's config file. Editors such as VS Code and Zed
+// allow comments and trailing commas in their settings, which are removed first.
+func unmarshalConfig(data []byte, v interface{}) error {
+	return json.Unmarshal(stripJSONC(data), v)
+}
 
-		mcpMap, ok := configData["mcp"].(map[string]interface{})
-		if !ok {
-			mcpMap = map[string]interface{}{}
-			configData["mcp"] = mcpMap
+// isJSONC reports whether data has comments or trailing commas, which plain JSON doesn't allow.
+func isJSONC(data []byte) bool {
+	return !bytes.Equal(stripJSONC(data), data)
+}
+
+// stripJSONC turns JSON with comments (JSONC) into plain JSON by removing // and /* */
+// comments and commas before a closing bracket. Comments are replaced with spaces and
+// newlines, so that the offsets of syntax errors stay the same.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				out = append(out, ' ')
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				// Leave an unterminated comment for the parser to report
+				return append(out, data[i:]...)
+			}
+			for _, commented := range data[i : i+2+end+2] {
+				if commented == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+			i += 2 + end + 1
+		default:
+			out = append(out, c)
 		}
+	}
 
-		if _, exists := mcpMap["servers"]; !exists { //nolint:govet // Intentional shadow
-			mcpMap["servers"] = map[string]interface{}{}
+	// Drop the commas that are followed by a closing bracket, now that comments are gone
+	result := out[:0]
+	inString = false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' && i+1 < len(out) {
+				result = append(result, c)
+				i++
+				c = out[i]
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ',':
+			next := i + 1
+			for next < len(out) && (out[next] == ' ' || out[next] == '\t' || out[next] == '\n' || out[next] == '\r') {
+				next++
+			}
+			if next < len(out) && (out[next] == '}' || out[next] == ']') {
+				c = ' '
+			}
 		}
+		result = append(result, c)
+	}
+	return result
+}
 
-		serversMap, exists := mcpMap["servers"].(map[string]interface{}) //nolint:govet // Intentional shadow
-		if !exists {
-			serversMap = map[string]interface{}{}
-			mcpMap["servers"] = serversMap
+// parseJSONPath splits a JSONPath such as "$.mcp.servers" or "$['mcp']['servers']" into its
+// keys. Only child keys are supported, which is all a path to a servers object needs; the
+// leading "$" is optional.
+func parseJSONPath(jsonPath string) ([]string, error) {
+	path := strings.TrimPrefix(strings.TrimSpace(jsonPath), "$")
+	var keys []string
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, "['") || strings.HasPrefix(path, `["`):
+			quote := path[1:2]
+			end := strings.Index(path[2:], quote+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unterminated bracket", jsonPath)
+			}
+			keys = append(keys, path[2:2+end])
+			path = path[2+end+2:]
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", jsonPath)
+			}
+			keys = append(keys, path[:end])
+			path = path[end:]
+		case len(keys) == 0 && !strings.HasPrefix(strings.TrimSpace(jsonPath), "$"):
+			// A path without "$", such as "mcp.servers"
+			path = "." + path
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unsupported syntax at %q", jsonPath, path)
 		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid JSONPath %q: it must point to a key holding the servers", jsonPath)
+	}
+	return keys, nil
+}
 
-		serversMap[serverName] = serverConfig
-		return
+// serversAtPath returns the servers object at jsonPath in the config data. With create, missing
+// or non-object values along the path are replaced with empty objects.
+func serversAtPath(configData map[string]interface{}, jsonPath string, create bool) (map[string]interface{}, bool) {
+	keys, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return nil, false
 	}
 
-	// Other formats with mcpServers
-	if _, ok := configData["mcpServers"]; !ok {
-		configData["mcpServers"] = map[string]interface{}{}
+	current := configData
+	for _, key := range keys {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil, false
+			}
+			next = map[string]interface{}{}
+			current[key] = next
+		}
+		current = next
 	}
+	return current, true
+}
 
-	serversMap, ok := configData["mcpServers"].(map[string]interface{})
+// addServerToConfig adds a server configuration to the config data.
+func addServerToConfig(configData map[string]interface{}, jsonPath, serverName string, serverConfig map[string]interface{}) {
+	serversMap, ok := serversAtPath(configData, jsonPath, true)
 	if !ok {
-		serversMap = map[string]interface{}{}
-		configData["mcpServers"] = serversMap
+		return
 	}
-
 	serversMap[serverName] = serverConfig
 }
 
 // getServerFromConfig gets a server configuration from the config data.
 func getServerFromConfig(configData map[string]interface{}, jsonPath, serverName string) (map[string]interface{}, bool) {
-	if strings.Contains(jsonPath, "mcp.servers") {
-		// VS Code format
-		mcpMap, ok := configData["mcp"].(map[string]interface{})
-		if !ok {
-			return nil, false
-		}
-
-		serversMap, ok := mcpMap["servers"].(map[string]interface{})
-		if !ok {
-			return nil, false
-		}
-
-		server, ok := serversMap[serverName].(map[string]interface{})
-		return server, ok
-	}
-
-	// Other formats with mcpServers
-	serversMap, ok := configData["mcpServers"].(map[string]interface{})
+	serversMap, ok := serversAtPath(configData, jsonPath, false)
 	if !ok {
 		return nil, false
 	}
@@ -329,28 +656,7 @@ func getServerFromConfig(configData map[string]interface{}, jsonPath, serverName
 
 // removeServerFromConfig removes a server configuration from the config data.
 func removeServerFromConfig(configData map[string]interface{}, jsonPath, serverName string) bool {
-	if strings.Contains(jsonPath, "mcp.servers") {
-		// VS Code format
-		mcpMap, ok := configData["mcp"].(map[string]interface{})
-		if !ok {
-			return false
-		}
-
-		serversMap, ok := mcpMap["servers"].(map[string]interface{})
-		if !ok {
-			return false
-		}
-
-		if _, exists := serversMap[serverName]; !exists {
-			return false
-		}
-
-		delete(serversMap, serverName)
-		return true
-	}
-
-	// Other formats with mcpServers
-	serversMap, ok := configData["mcpServers"].(map[string]interface{})
+	serversMap, ok := serversAtPath(configData, jsonPath, false)
 	if !ok {
 		return false
 	}
@@ -363,6 +669,61 @@ func removeServerFromConfig(configData map[string]interface{}, jsonPath, serverN
 	return true
 }
 
+// renameServerInConfig moves a server configuration to a new name in the config data.
+func renameServerInConfig(configData map[string]interface{}, jsonPath, oldName, newName string) error {
+	serverConfig, ok := getServerFromConfig(configData, jsonPath, oldName)
+	if !ok {
+		return fmt.Errorf("server '%s' not found", oldName)
+	}
+	if _, exists := getServerFromConfig(configData, jsonPath, newName); exists {
+		return fmt.Errorf("server '%s' already exists", newName)
+	}
+
+	removeServerFromConfig(configData, jsonPath, oldName)
+	addServerToConfig(configData, jsonPath, newName, serverConfig)
+	return nil
+}
+
+// renameConfigAlias renames an alias of the configs file. Server aliases that refer to the
+// servers of the config by its old name are updated to the new one.
+func renameConfigAlias(configs *ConfigsFile, oldName, newName string) error {
+	oldName, newName = strings.ToLower(oldName), strings.ToLower(newName)
+	configAlias, ok := configs.Aliases[oldName]
+	if !ok {
+		return fmt.Errorf("alias '%s' not found", oldName)
+	}
+	if _, exists := configs.Aliases[newName]; exists {
+		return fmt.Errorf("alias '%s' already exists", newName)
+	}
+
+	delete(configs.Aliases, oldName)
+	if strings.EqualFold(configAlias.Source, oldName) {
+		configAlias.Source = newName
+	}
+	configs.Aliases[newName] = configAlias
+	if err := saveConfigsFile(configs); err != nil {
+		return err
+	}
+
+	serverAliases, err := alias.Load()
+	if err != nil {
+		return err
+	}
+	updated := false
+	oldRef := configRefPrefix + oldName + "/"
+	for name, serverAlias := range serverAliases {
+		if strings.HasPrefix(serverAlias.Command, oldRef) {
+			serverAlias.Command = configRefPrefix + newName + "/" + strings.TrimPrefix(serverAlias.Command, oldRef)
+			serverAliases[name] = serverAlias
+			updated = true
+		}
+	}
+	if updated {
+		return alias.Save(serverAliases)
+	}
+	return nil
+}
+
 // getServersFromConfig extracts all servers from a config file.
 func getServersFromConfig(configFile string, jsonPath string, _ string) (map[string]map[string]interface{}, error) {
 	// Read the config file
@@ -372,29 +733,18 @@ func getServersFromConfig(configFile string, jsonPath string, _ string) (map[str
 	}
 
 	var configData map[string]interface{}
-	if err := json.Unmarshal(data, &configData); err != nil {
+	if err := unmarshalConfig(data, &configData); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	// Extract servers based on JSONPath
-	var serversMap map[string]interface{}
-	if strings.Contains(jsonPath, "mcp.servers") {
-		// VS Code format
-		mcpMap, ok := configData["mcp"].(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("no mcp key found in config")
-		}
-		serversMap, ok = mcpMap["servers"].(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("no mcp.servers key found in config")
-		}
-	} else {
-		// Other formats with mcpServers
-		var ok bool
-		serversMap, ok = configData["mcpServers"].(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("no mcpServers key found in config")
-		}
+	keys, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	serversMap, ok := serversAtPath(configData, jsonPath, false)
+	if !ok {
+		return nil, fmt.Errorf("no %s key found in config", strings.Join(keys, "."))
 	}
 
 	// Convert to map of maps for easier handling
@@ -432,6 +782,409 @@ func areConfigsIdentical(config1, config2 map[string]interface{}) bool {
 	return bytes.Equal(json1, json2)
 }
 
+// mergeServerConfigs deep-merges two definitions of the same server. Env and headers are
+// combined with the first definition winning on duplicate keys, and args are merged when one
+// list extends the other. It returns false when the definitions run different commands or
+// URLs, or have args that can't be merged.
+func mergeServerConfigs(first, second map[string]interface{}) (map[string]interface{}, bool) {
+	for _, key := range []string{"command", "url"} {
+		firstValue, firstOK := first[key]
+		secondValue, secondOK := second[key]
+		if firstOK && secondOK && !reflect.DeepEqual(firstValue, secondValue) {
+			return nil, false
+		}
+	}
+
+	merged := make(map[string]interface{}, len(first)+len(second))
+	for k, v := range second {
+		merged[k] = v
+	}
+	for k, v := range first {
+		merged[k] = v
+	}
+
+	for _, key := range []string{"env", "headers"} {
+		firstMap, firstOK := first[key].(map[string]interface{})
+		secondMap, secondOK := second[key].(map[string]interface{})
+		if !firstOK || !secondOK {
+			continue
+		}
+		combined := make(map[string]interface{}, len(firstMap)+len(secondMap))
+		for k, v := range secondMap {
+			combined[k] = v
+		}
+		for k, v := range firstMap {
+			combined[k] = v
+		}
+		merged[key] = combined
+	}
+
+	firstArgs, _ := first["args"].([]interface{})
+	secondArgs, _ := second["args"].([]interface{})
+	switch {
+	case isArgsPrefix(firstArgs, secondArgs):
+		if secondArgs != nil {
+			merged["args"] = secondArgs
+		}
+	case isArgsPrefix(secondArgs, firstArgs):
+		if firstArgs != nil {
+			merged["args"] = firstArgs
+		}
+	default:
+		return nil, false
+	}
+
+	return merged, true
+}
+
+// isArgsPrefix reports whether prefix is a prefix of args.
+func isArgsPrefix(prefix, args []interface{}) bool {
+	if len(prefix) > len(args) {
+		return false
+	}
+	for i := range prefix {
+		if !reflect.DeepEqual(prefix[i], args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// serverDiff describes how the servers of two configs differ.
+type serverDiff struct {
+	OnlyInFirst  map[string]map[string]interface{}    `json:"onlyInFirst"`
+	OnlyInSecond map[string]map[string]interface{}    `json:"onlyInSecond"`
+	Different    map[string][2]map[string]interface{} `json:"different"`
+}
+
+// diffServers compares two sets of servers by name.
+func diffServers(first, second map[string]map[string]interface{}) serverDiff {
+	diff := serverDiff{
+		OnlyInFirst:  make(map[string]map[string]interface{}),
+		OnlyInSecond: make(map[string]map[string]interface{}),
+		Different:    make(map[string][2]map[string]interface{}),
+	}
+
+	for name, server := range first {
+		other, ok := second[name]
+		switch {
+		case !ok:
+			diff.OnlyInFirst[name] = server
+		case !areConfigsIdentical(server, other):
+			diff.Different[name] = [2]map[string]interface{}{server, other}
+		}
+	}
+	for name, server := range second {
+		if _, ok := first[name]; !ok {
+			diff.OnlyInSecond[name] = server
+		}
+	}
+
+	return diff
+}
+
+// sortedServerNames returns the names of servers in alphabetical order.
+func sortedServerNames[T any](servers map[string]T) []string {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadAliasServers returns the servers configured for an alias. A config file that doesn't
+// exist yet has no servers.
+func loadAliasServers(configs *ConfigsFile, aliasName string) (map[string]map[string]interface{}, error) {
+	aliasConfig, ok := configs.Aliases[strings.ToLower(aliasName)]
+	if !ok {
+		return nil, fmt.Errorf("alias '%s' not found", aliasName)
+	}
+
+	configFile := expandPath(aliasConfig.Path)
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return map[string]map[string]interface{}{}, nil
+	}
+
+	return getServersFromConfig(configFile, aliasConfig.JSONPath, aliasConfig.Source)
+}
+
+// exportServers collects the servers of the given aliases into a single server map.
+// Servers defined identically by several aliases are kept once; for conflicting definitions
+// the first alias wins and the conflict is reported to errOut. Aliases whose config file
+// doesn't exist are skipped, with a warning when they were requested explicitly.
+func exportServers(configs *ConfigsFile, aliasNames []string, warnMissing bool, errOut io.Writer) map[string]map[string]interface{} {
+	allServers := make(map[string]map[string]interface{})
+	serverSources := make(map[string]string)
+
+	for _, aliasName := range aliasNames {
+		aliasConfig, ok := configs.Aliases[strings.ToLower(aliasName)]
+		if !ok {
+			fmt.Fprintf(errOut, "Warning: alias '%s' not found, skipping\n", aliasName)
+			continue
+		}
+
+		configFile := expandPath(aliasConfig.Path)
+		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+			if warnMissing {
+				fmt.Fprintf(errOut, "Warning: config file for alias '%s' not found at %s, skipping\n", aliasName, configFile)
+			}
+			continue
+		}
+
+		servers, err := getServersFromConfig(configFile, aliasConfig.JSONPath, aliasConfig.Source)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: failed to read servers from alias '%s': %v, skipping\n", aliasName, err)
+			continue
+		}
+
+		names := make([]string, 0, len(servers))
+		for name := range servers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			server := servers[name]
+			existing, ok := allServers[name]
+			if !ok {
+				allServers[name] = server
+				serverSources[name] = aliasName
+				continue
+			}
+
+			if !areConfigsIdentical(existing, server) {
+				fmt.Fprintf(errOut, "Conflict for '%s': alias '%s' differs from '%s', keeping the definition from '%s'\n",
+					name, aliasName, serverSources[name], serverSources[name])
+			}
+		}
+	}
+
+	return allServers
+}
+
+// serverTarget is how to start or reach a server defined by a config entry.
+type serverTarget struct {
+	args []string
+	// env holds KEY=VALUE variables for stdio servers
+	env []string
+	// headers holds "Key: Value" headers for HTTP servers
+	headers []string
+	// transport is "sse" for SSE servers, or empty for the default
+	transport string
+}
+
+// serverConfigTarget returns how to start or reach the server described by a config entry.
+// Besides a command string with args and env, the command may be an object with path, args
+// and env, as in the context_servers of Zed.
+func serverConfigTarget(serverConfig map[string]interface{}) (serverTarget, error) {
+	var target serverTarget
+	if url, ok := serverConfig["url"].(string); ok && url != "" {
+		target.args = []string{expandConfigValue(url)}
+		if serverType, _ := serverConfig["type"].(string); serverType == "sse" {
+			target.transport = "sse"
+		}
+		for k, v := range stringMap(serverConfig["headers"]) {
+			target.headers = append(target.headers, k+": "+expandConfigValue(v))
+		}
+		return target, nil
+	}
+
+	command, _ := serverConfig["command"].(string)
+	if commandObject, ok := serverConfig["command"].(map[string]interface{}); ok {
+		serverConfig = commandObject
+		command, _ = commandObject["path"].(string)
+	}
+	if command == "" {
+		return target, fmt.Errorf("server config has neither a command nor a url")
+	}
+	target.args = append(target.args, expandConfigValue(command))
+	switch configArgs := serverConfig["args"].(type) {
+	case []string:
+		for _, arg := range configArgs {
+			target.args = append(target.args, expandConfigValue(arg))
+		}
+	case []interface{}:
+		for _, arg := range configArgs {
+			target.args = append(target.args, expandConfigValue(fmt.Sprint(arg)))
+		}
+	}
+	for k, v := range stringMap(serverConfig["env"]) {
+		target.env = append(target.env, k+"="+expandConfigValue(v))
+	}
+	return target, nil
+}
+
+// expandConfigValue replaces ${env:VAR}, ${VAR} and $VAR in a config value with the
+// variables of the current environment. Unset variables and editor placeholders such
+// as ${workspaceFolder} are kept as written.
+func expandConfigValue(value string) string {
+	return mcpclient.ExpandEnv(value)
+}
+
+// projectConfigFile is the name of the file projects describe their servers in, in the
+// mcpServers format.
+const projectConfigFile = ".mcp.json"
+
+// findProjectConfig returns the path of the .mcp.json in dir or the nearest of its parents,
+// or an empty string if there is none.
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, projectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// resolveProjectServer looks up a server by name in the .mcp.json of the current directory or
+// its parents, which may have comments and trailing commas. An empty name selects the only
+// server of the file. It returns nil if there is no such file or server. A file that can't be
+// read or parsed is only reported for an empty name; otherwise the name is left to be run as
+// a command, so that a broken .mcp.json up the tree doesn't break unrelated commands.
+func resolveProjectServer(name string) (*serverTarget, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil
+	}
+	path := findProjectConfig(wd)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Found by walking up from the working directory
+	if err != nil {
+		if name != "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var configData map[string]interface{}
+	if err := unmarshalConfig(data, &configData); err != nil {
+		if name != "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if name == "" {
+		servers, _ := configData["mcpServers"].(map[string]interface{})
+		if len(servers) == 0 {
+			return nil, nil
+		}
+		if len(servers) > 1 {
+			names := make([]string, 0, len(servers))
+			for serverName := range servers {
+				names = append(names, serverName)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("%s defines several servers (%s); name the one to use", path, strings.Join(names, ", "))
+		}
+		for serverName := range servers {
+			name = serverName
+		}
+	}
+
+	serverConfig, ok := getServerFromConfig(configData, "mcpServers", name)
+	if !ok {
+		return nil, nil
+	}
+	target, err := serverConfigTarget(serverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: server %s: %w", path, name, err)
+	}
+	return &target, nil
+}
+
+// verifyServerConfig starts the server described by a config entry, lists its tools and
+// stops it again. It returns the number of tools the server exposes.
+func verifyServerConfig(serverConfig map[string]interface{}) (int, error) {
+	target, err := serverConfigTarget(serverConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	mcpClient, err := createTargetClientFunc(target)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseWithTimeout(mcpClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return 0, err
+	}
+	return len(resp.Tools), nil
+}
+
+// stringMap converts the headers or env of a server config into a string map.
+func stringMap(value interface{}) map[string]string {
+	switch m := value.(type) {
+	case map[string]string:
+		return m
+	case map[string]interface{}:
+		result := make(map[string]string, len(m))
+		for k, v := range m {
+			result[k] = fmt.Sprint(v)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// readImportedServers reads the servers of a config document, either in the mcpServers
+// format or in the VS Code mcp.servers format.
+func readImportedServers(path string) (map[string]map[string]interface{}, error) {
+	configFile := expandPath(path)
+	data, err := os.ReadFile(configFile) //nolint:gosec // File path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var configData map[string]interface{}
+	if err := json.Unmarshal(data, &configData); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	jsonPath := "mcpServers"
+	if _, ok := configData["mcpServers"]; !ok {
+		if _, ok := configData["mcp"]; ok {
+			jsonPath = "mcp.servers"
+		}
+	}
+
+	return getServersFromConfig(configFile, jsonPath, "")
+}
+
+// importServers adds servers to the servers at jsonPath in configData and returns the names of
+// the added, updated and skipped servers. Existing servers are only replaced when overwrite is
+// set and their definition differs.
+func importServers(configData map[string]interface{}, jsonPath string, servers map[string]map[string]interface{}, overwrite bool) (added, updated, skipped []string) {
+	for _, name := range sortedServerNames(servers) {
+		existing, exists := getServerFromConfig(configData, jsonPath, name)
+		switch {
+		case !exists:
+			added = append(added, name)
+		case areConfigsIdentical(existing, servers[name]), !overwrite:
+			skipped = append(skipped, name)
+			continue
+		default:
+			updated = append(updated, name)
+		}
+		addServerToConfig(configData, jsonPath, name, servers[name])
+	}
+	return added, updated, skipped
+}
+
 // formatSourceGroupedJSON formats servers grouped by source with raw JSON.
 func formatSourceGroupedJSON(servers []ServerConfig) string {
 	if len(servers) == 0 {
@@ -516,7 +1269,7 @@ func formatColoredGroupedServers(servers []ServerConfig) string {
 
 	var buf bytes.Buffer
 	// Check if we're outputting to a terminal (for colors)
-	useColors := term.IsTerminal(int(os.Stdout.Fd()))
+	useColors := jsonutils.UseColors(os.Stdout)
 
 	for _, source := range sourceOrder {
 		// Print source header with bold blue
@@ -542,6 +1295,11 @@ func formatColoredGroupedServers(servers []ServerConfig) string {
 					serverType = "stdio" // nolint:goconst
 				}
 			}
+			if serverType == "stdio" {
+				if runtime := containerRuntime(server.Command); runtime != "" {
+					serverType = runtime
+				}
+			}
 
 			// Print server name and type
 			if useColors {
@@ -639,14 +1397,14 @@ func scanForServers() ([]ServerConfig, error) {
 	var servers []ServerConfig
 
 	// Scan VS Code Insiders
-	vscodeInsidersPath := filepath.Join(homeDir, "Library", "Application Support", "Code - Insiders", "User", "settings.json")
+	vscodeInsidersPath := expandPath(appConfigPath("Code - Insiders", "User", "settings.json"))
 	vscodeServers, err := scanVSCodeConfig(vscodeInsidersPath, "VS Code Insiders")
 	if err == nil {
 		servers = append(servers, vscodeServers...)
 	}
 
 	// Scan VS Code
-	vscodePath := filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "settings.json")
+	vscodePath := expandPath(appConfigPath("Code", "User", "settings.json"))
 	vscodeServers, err = scanVSCodeConfig(vscodePath, "VS Code")
 	if err == nil {
 		servers = append(servers, vscodeServers...)
@@ -667,7 +1425,7 @@ func scanForServers() ([]ServerConfig, error) {
 	}
 
 	// Scan Claude Desktop
-	claudeDesktopPath := filepath.Join(homeDir, "Library", "Application Support", "Claude", "claude_desktop_config.json")
+	claudeDesktopPath := expandPath(appConfigPath("Claude", "claude_desktop_config.json"))
 	claudeServers, err := scanMCPServersConfig(claudeDesktopPath, "Claude Desktop")
 	if err == nil {
 		servers = append(servers, claudeServers...)
@@ -680,6 +1438,18 @@ func scanForServers() ([]ServerConfig, error) {
 		servers = append(servers, claudeCodeServers...)
 	}
 
+	// Scan Zed
+	zedServers, err := scanZedConfig(expandPath(zedSettingsPath()), "Zed")
+	if err == nil {
+		servers = append(servers, zedServers...)
+	}
+
+	// Scan Cline
+	clineServers, err := scanMCPServersConfig(expandPath(clineSettingsPath()), "Cline")
+	if err == nil {
+		servers = append(servers, clineServers...)
+	}
+
 	return servers, nil
 }
 
@@ -691,7 +1461,7 @@ func scanVSCodeConfig(path, source string) ([]ServerConfig, error) {
 	}
 
 	var settings map[string]interface{}
-	if err := json.Unmarshal(data, &settings); err != nil {
+	if err := unmarshalConfig(data, &settings); err != nil {
 		return nil, fmt.Errorf("failed to parse %s settings.json: %w", source, err)
 	}
 
@@ -723,9 +1493,120 @@ func scanVSCodeConfig(path, source string) ([]ServerConfig, error) {
 		}
 
 		// Extract common properties
-		serverType, _ := serverConfig["type"].(string)
+		serverType, _ := serverConfig["type"].(string)
+		command, _ := serverConfig["command"].(string)
+		description, _ := serverConfig["description"].(string)
+		url, _ := serverConfig["url"].(string)
+
+		// Extract args if available
+		var args []string
+		if argsInterface, ok := serverConfig["args"].([]interface{}); ok {
+			for _, arg := range argsInterface {
+				if argStr, ok := arg.(string); ok {
+					args = append(args, argStr)
+				}
+			}
+		}
+
+		// Extract headers if available
+		headers := make(map[string]string)
+		if headersInterface, ok := serverConfig["headers"].(map[string]interface{}); ok {
+			for k, v := range headersInterface {
+				if valStr, ok := v.(string); ok {
+					headers[k] = valStr
+				}
+			}
+		}
+
+		// Extract env if available
+		env := make(map[string]string)
+		if envInterface, ok := serverConfig["env"].(map[string]interface{}); ok {
+			for k, v := range envInterface {
+				if valStr, ok := v.(string); ok {
+					env[k] = valStr
+				}
+			}
+		}
+
+		result = append(result, ServerConfig{
+			Source:      source,
+			Type:        serverType,
+			Command:     command,
+			Args:        args,
+			URL:         url,
+			Headers:     headers,
+			Env:         env,
+			Name:        name,
+			Config:      serverConfig,
+			Description: description,
+		})
+	}
+
+	return result, nil
+}
+
+// scanMCPServersConfig scans a config file with mcpServers as the top-level key.
+func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // File path from user home directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config: %w", source, err)
+	}
+
+	var config map[string]interface{}
+	if err := unmarshalConfig(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s config: %w", source, err)
+	}
+
+	mcpServers, ok := config["mcpServers"]
+	if !ok {
+		return nil, fmt.Errorf("no mcpServers key found in %s config", source)
+	}
+
+	servers, ok := mcpServers.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid mcpServers format in %s config", source)
+	}
+
+	return serverConfigsFromMap(servers, source), nil
+}
+
+// scanJSONPathConfig scans a config file whose servers are in the object at jsonPath, in the
+// same format as mcpServers.
+func scanJSONPathConfig(path, jsonPath, source string) ([]ServerConfig, error) {
+	keys, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // File path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config: %w", source, err)
+	}
+
+	var config map[string]interface{}
+	if err := unmarshalConfig(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s config: %w", source, err)
+	}
+
+	servers, ok := serversAtPath(config, jsonPath, false)
+	if !ok {
+		return nil, fmt.Errorf("no %s key found in %s config", strings.Join(keys, "."), source)
+	}
+
+	return serverConfigsFromMap(servers, source), nil
+}
+
+// serverConfigsFromMap converts a servers object in the mcpServers format to server configs.
+func serverConfigsFromMap(servers map[string]interface{}, source string) []ServerConfig {
+	var result []ServerConfig
+	for name, serverData := range servers {
+		serverConfig, ok := serverData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Extract common properties
 		command, _ := serverConfig["command"].(string)
-		description, _ := serverConfig["description"].(string)
 		url, _ := serverConfig["url"].(string)
 
 		// Extract args if available
@@ -758,43 +1639,44 @@ func scanVSCodeConfig(path, source string) ([]ServerConfig, error) {
 			}
 		}
 
+		// Determine type based on whether URL is present
+		serverType := ""
+		if url != "" {
+			serverType = "sse"
+		}
+
 		result = append(result, ServerConfig{
-			Source:      source,
-			Type:        serverType,
-			Command:     command,
-			Args:        args,
-			URL:         url,
-			Headers:     headers,
-			Env:         env,
-			Name:        name,
-			Config:      serverConfig,
-			Description: description,
+			Source:  source,
+			Type:    serverType,
+			Command: command,
+			Args:    args,
+			URL:     url,
+			Headers: headers,
+			Env:     env,
+			Name:    name,
+			Config:  serverConfig,
 		})
 	}
 
-	return result, nil
+	return result
 }
 
-// scanMCPServersConfig scans a config file with mcpServers as the top-level key.
-func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
+// scanZedConfig scans the context_servers of a Zed settings file. Zed accepts both a plain
+// command with args and env, and a command object with path, args and env.
+func scanZedConfig(path, source string) ([]ServerConfig, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // File path from user home directory
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s config: %w", source, err)
 	}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse %s config: %w", source, err)
-	}
-
-	mcpServers, ok := config["mcpServers"]
-	if !ok {
-		return nil, fmt.Errorf("no mcpServers key found in %s config", source)
+	var settings map[string]interface{}
+	if err := unmarshalConfig(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s settings.json: %w", source, err)
 	}
 
-	servers, ok := mcpServers.(map[string]interface{})
+	servers, ok := settings["context_servers"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid mcpServers format in %s config", source)
+		return nil, fmt.Errorf("no context_servers found in %s settings", source)
 	}
 
 	var result []ServerConfig
@@ -804,13 +1686,17 @@ func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
 			continue
 		}
 
-		// Extract common properties
+		// The command is either a string or an object with path, args and env
+		commandConfig := serverConfig
 		command, _ := serverConfig["command"].(string)
+		if commandObject, ok := serverConfig["command"].(map[string]interface{}); ok {
+			commandConfig = commandObject
+			command, _ = commandObject["path"].(string)
+		}
 		url, _ := serverConfig["url"].(string)
 
-		// Extract args if available
 		var args []string
-		if argsInterface, ok := serverConfig["args"].([]interface{}); ok {
+		if argsInterface, ok := commandConfig["args"].([]interface{}); ok {
 			for _, arg := range argsInterface {
 				if argStr, ok := arg.(string); ok {
 					args = append(args, argStr)
@@ -818,7 +1704,6 @@ func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
 			}
 		}
 
-		// Extract headers if available
 		headers := make(map[string]string)
 		if headersInterface, ok := serverConfig["headers"].(map[string]interface{}); ok {
 			for k, v := range headersInterface {
@@ -828,9 +1713,8 @@ func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
 			}
 		}
 
-		// Extract env if available
 		env := make(map[string]string)
-		if envInterface, ok := serverConfig["env"].(map[string]interface{}); ok {
+		if envInterface, ok := commandConfig["env"].(map[string]interface{}); ok {
 			for k, v := range envInterface {
 				if valStr, ok := v.(string); ok {
 					env[k] = valStr
@@ -838,10 +1722,9 @@ func scanMCPServersConfig(path, source string) ([]ServerConfig, error) {
 			}
 		}
 
-		// Determine type based on whether URL is present
 		serverType := ""
 		if url != "" {
-			serverType = "sse"
+			serverType = "http"
 		}
 
 		result = append(result, ServerConfig{
@@ -873,7 +1756,7 @@ func ConfigsCmd() *cobra.Command { //nolint:gocyclo // This is a large command w
 		Use:   "scan",
 		Short: "Scan for available MCP servers in various configurations",
 		Long: `Scan for available MCP servers in various configuration files of these Applications on macOS:
-VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
+VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code, Zed, Cline`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			servers, err := scanForServers()
 			if err != nil {
@@ -925,6 +1808,10 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 
 			// All mode - scan all aliases (same as previous scan command)
 			if AllOption {
+				if JSONPathOption != "" {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: --json-path can't be combined with --all\n")
+					return
+				}
 				if len(args) > 0 {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: ignoring specified alias/path when using --all flag\n")
 				}
@@ -947,9 +1834,12 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 						source = titleCase.String(alias) // Use capitalized alias name if source not provided
 					}
 
-					if strings.Contains(config.JSONPath, "mcp.servers") {
+					switch {
+					case strings.Contains(config.JSONPath, "mcp.servers"):
 						configServers, scanErr = scanVSCodeConfig(expandedPath, source)
-					} else if strings.Contains(config.JSONPath, "mcpServers") {
+					case strings.Contains(config.JSONPath, "context_servers"):
+						configServers, scanErr = scanZedConfig(expandedPath, source)
+					case strings.Contains(config.JSONPath, "mcpServers"):
 						configServers, scanErr = scanMCPServersConfig(expandedPath, source)
 					}
 
@@ -992,9 +1882,14 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 				var configServers []ServerConfig
 				var scanErr error
 
-				if strings.Contains(jsonPath, "mcp.servers") {
+				switch {
+				case JSONPathOption != "":
+					configServers, scanErr = scanJSONPathConfig(expandedPath, JSONPathOption, source)
+				case strings.Contains(jsonPath, "mcp.servers"):
 					configServers, scanErr = scanVSCodeConfig(expandedPath, source)
-				} else {
+				case strings.Contains(jsonPath, "context_servers"):
+					configServers, scanErr = scanZedConfig(expandedPath, source)
+				default:
 					configServers, scanErr = scanMCPServersConfig(expandedPath, source)
 				}
 
@@ -1038,6 +1933,7 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 
 	// Add --all flag to view command
 	viewCmd.Flags().BoolVar(&AllOption, "all", false, "View all configured aliases")
+	viewCmd.Flags().StringVar(&JSONPathOption, "json-path", "", "JSONPath of the servers object, overriding the alias (e.g. $.tools.mcp.servers)")
 
 	// Add ls command as an alias for view --all
 	lsCmd := &cobra.Command{
@@ -1070,6 +1966,10 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 			var configFile string
 			var headers string
 			var env string
+			var jsonPathOverride string
+			var verify bool
+			var noBackup bool
+			var force bool
 
 			// Create cleaned arguments (without our flags)
 			cleanedArgs := make([]string, 0, len(args))
@@ -1110,6 +2010,34 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					continue
 				}
 
+				if strings.HasPrefix(arg, "--json-path=") {
+					jsonPathOverride = strings.TrimPrefix(arg, "--json-path=")
+					i++
+					continue
+				} else if arg == "--json-path" && i+1 < len(args) {
+					jsonPathOverride = args[i+1]
+					i += 2
+					continue
+				}
+
+				if arg == "--verify" {
+					verify = true
+					i++
+					continue
+				}
+
+				if arg == "--no-backup" {
+					noBackup = true
+					i++
+					continue
+				}
+
+				if arg == "--force" {
+					force = true
+					i++
+					continue
+				}
+
 				// If none of our flags, add to cleaned args
 				cleanedArgs = append(cleanedArgs, arg)
 				i++
@@ -1125,6 +2053,16 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 			ConfigFileOption = configFile
 			HeadersOption = headers
 			EnvOption = env
+			JSONPathOption = jsonPathOverride
+			NoBackupOption = noBackup
+			ForceOption = force
+
+			if JSONPathOption != "" {
+				if _, err := parseJSONPath(JSONPathOption); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					return
+				}
+			}
 
 			// Load configs
 			configs, err := loadConfigsFile()
@@ -1140,6 +2078,7 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 			// Split aliases by comma
 			aliasList := strings.Split(aliasInput, ",")
 			successCount := 0
+			var writtenConfig map[string]interface{}
 
 			// Process each alias
 			for _, aliasName := range aliasList {
@@ -1154,6 +2093,9 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					fmt.Fprintf(cmd.ErrOrStderr(), "Error for alias '%s': %v\n", aliasName, err)
 					continue
 				}
+				if JSONPathOption != "" {
+					jsonPath = JSONPathOption
+				}
 
 				// Read the target config file
 				configData, err := readConfigFile(configFile)
@@ -1241,12 +2183,13 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					continue
 				}
 
-				if writeErr := os.WriteFile(configFile, data, filePermissions); writeErr != nil { //nolint:gosec // User config file
+				if writeErr := writeConfigFile(configFile, data); writeErr != nil {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Error writing config file for alias '%s': %v\n", aliasName, writeErr)
 					continue
 				}
 
 				successCount++
+				writtenConfig = serverConfig
 				if exists {
 					fmt.Fprintf(cmd.OutOrStdout(), "Server '%s' updated for alias '%s' in %s\n", serverName, aliasName, configFile)
 				} else {
@@ -1258,6 +2201,16 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 			if len(aliasList) > 1 {
 				fmt.Fprintf(cmd.OutOrStdout(), "\nSummary: Successfully processed %d of %d aliases\n", successCount, len(aliasList))
 			}
+
+			// Start the server once to check that the written config works
+			if verify && writtenConfig != nil {
+				toolCount, verifyErr := verifyServerConfig(writtenConfig)
+				if verifyErr != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Verification failed for server '%s': %v\n", serverName, verifyErr)
+					return
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Verified server '%s': %d tools available\n", serverName, toolCount)
+			}
 		},
 	}
 
@@ -1265,6 +2218,10 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 	setCmd.Flags().StringVar(&ConfigFileOption, "config", "", "Path to the configuration file")
 	setCmd.Flags().StringVar(&HeadersOption, "headers", "", "Headers for URL-based servers (comma-separated key=value pairs)")
 	setCmd.Flags().StringVar(&EnvOption, "env", "", "Environment variables (comma-separated key=value pairs)")
+	setCmd.Flags().StringVar(&JSONPathOption, "json-path", "", "JSONPath of the servers object, overriding the alias (e.g. $.tools.mcp.servers)")
+	setCmd.Flags().Bool("verify", false, "Start the server after writing the config and list its tools")
+	setCmd.Flags().BoolVar(&NoBackupOption, "no-backup", false, "Don't keep a backup of the config file")
+	setCmd.Flags().BoolVar(&ForceOption, "force", false, "Rewrite the config file even if its comments and trailing commas would be lost")
 
 	// Add the remove subcommand
 	removeCmd := &cobra.Command{
@@ -1273,6 +2230,13 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 		Long:  `Remove an MCP server configuration from a config file. Multiple aliases can be specified with commas.`,
 		Args:  cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
+			if JSONPathOption != "" {
+				if _, err := parseJSONPath(JSONPathOption); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					return
+				}
+			}
+
 			// Load configs
 			configs, err := loadConfigsFile()
 			if err != nil {
@@ -1301,6 +2265,9 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					fmt.Fprintf(cmd.ErrOrStderr(), "Error for alias '%s': %v\n", aliasName, err)
 					continue
 				}
+				if JSONPathOption != "" {
+					jsonPath = JSONPathOption
+				}
 
 				// Read the target config file
 				configData, err := readConfigFile(configFile)
@@ -1323,7 +2290,7 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					continue
 				}
 
-				if writeErr := os.WriteFile(configFile, data, filePermissions); writeErr != nil { //nolint:gosec // User config file
+				if writeErr := writeConfigFile(configFile, data); writeErr != nil {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Error writing config file for alias '%s': %v\n", aliasName, writeErr)
 					continue
 				}
@@ -1341,6 +2308,82 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 
 	// Add flag to remove command
 	removeCmd.Flags().StringVar(&ConfigFileOption, "config", "", "Path to the configuration file")
+	removeCmd.Flags().StringVar(&JSONPathOption, "json-path", "", "JSONPath of the servers object, overriding the alias (e.g. $.tools.mcp.servers)")
+	removeCmd.Flags().BoolVar(&NoBackupOption, "no-backup", false, "Don't keep a backup of the config file")
+	removeCmd.Flags().BoolVar(&ForceOption, "force", false, "Rewrite the config file even if its comments and trailing commas would be lost")
+
+	// Add the rename subcommand
+	renameAliasOption := false
+	renameCmd := &cobra.Command{
+		Use:   "rename [alias] [server] [new-name]",
+		Short: "Rename an MCP server configuration or a config alias",
+		Long: `Rename a server of a config file, keeping its configuration. With --alias, rename a config
+alias instead; server aliases that refer to its servers with config:<alias>/<server> are
+updated to the new name.
+
+  mcp configs rename cursor filesystem fs
+  mcp configs rename --alias myapp work`,
+		Args: cobra.RangeArgs(2, 3),
+		Run: func(cmd *cobra.Command, args []string) {
+			configs, err := loadConfigsFile()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configs: %v\n", err)
+				return
+			}
+
+			if renameAliasOption {
+				if len(args) != 2 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: --alias takes the old and new name of the alias\n")
+					return
+				}
+				if err := renameConfigAlias(configs, args[0], args[1]); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					return
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Alias '%s' renamed to '%s'\n", args[0], args[1])
+				return
+			}
+
+			if len(args) != 3 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: expected an alias, a server and its new name\n")
+				return
+			}
+			aliasName, oldName, newName := args[0], args[1], args[2]
+
+			configFile, jsonPath, err := getConfigFileAndPath(configs, aliasName, ConfigFileOption)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error for alias '%s': %v\n", aliasName, err)
+				return
+			}
+			configData, err := readConfigFile(configFile)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error for alias '%s': %v\n", aliasName, err)
+				return
+			}
+
+			if err := renameServerInConfig(configData, jsonPath, oldName, newName); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v for alias '%s' in %s\n", err, aliasName, configFile)
+				return
+			}
+
+			data, err := json.MarshalIndent(configData, "", "  ")
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error marshaling config for alias '%s': %v\n", aliasName, err)
+				return
+			}
+			if writeErr := writeConfigFile(configFile, data); writeErr != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error writing config file for alias '%s': %v\n", aliasName, writeErr)
+				return
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Server '%s' renamed to '%s' for alias '%s' in %s\n", oldName, newName, aliasName, configFile)
+		},
+	}
+
+	renameCmd.Flags().BoolVar(&renameAliasOption, "alias", false, "Rename a config alias instead of a server")
+	renameCmd.Flags().StringVar(&ConfigFileOption, "config", "", "Path to the configuration file")
+	renameCmd.Flags().BoolVar(&NoBackupOption, "no-backup", false, "Don't keep a backup of the config file")
+	renameCmd.Flags().BoolVar(&ForceOption, "force", false, "Rewrite the config file even if its comments and trailing commas would be lost")
 
 	// Add the alias subcommand
 	aliasCmd := &cobra.Command{
@@ -1388,8 +2431,10 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 	syncCmd := &cobra.Command{
 		Use:   "sync [alias1] [alias2] [...]",
 		Short: "Synchronize and merge MCP server configurations",
-		Long:  `Synchronize and merge MCP server configurations from multiple alias sources with interactive conflict resolution.`,
-		Args:  cobra.MinimumNArgs(1),
+		Long: `Synchronize and merge MCP server configurations from multiple alias sources with interactive conflict resolution.
+Use --default to resolve conflicts automatically: 'first' or 'second' pick a source, 'newest' picks the most recently
+modified source, and 'merge' combines env, headers and args, only prompting when the commands or URLs differ.`,
+		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Load configs
 			configs, err := loadConfigsFile()
@@ -1459,10 +2504,20 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 
 			// Determine default choice for conflicts
 			defaultChoice := strings.ToLower(DefaultChoiceOption)
-			if defaultChoice != "first" && defaultChoice != "second" && defaultChoice != "interactive" {
+			switch defaultChoice {
+			case "first", "second", "newest", "merge", "interactive":
+			default:
 				defaultChoice = "interactive"
 			}
 
+			// Track when each source was last modified for the newest strategy
+			modTimes := make(map[string]time.Time)
+			for i, aliasName := range aliasNames {
+				if info, statErr := os.Stat(aliasFiles[i]); statErr == nil {
+					modTimes[aliasName] = info.ModTime()
+				}
+			}
+
 			// Collect all servers
 			allServers := make(map[string]map[string]interface{})
 			serverSources := make(map[string]string) // track where each server comes from
@@ -1523,6 +2578,29 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 						allServers[name] = conflictingConfigs[1]
 						fmt.Fprintf(cmd.OutOrStdout(), "Conflict for '%s': automatically selected version from '%s'\n", name, sources[1])
 						continue
+					} else if defaultChoice == "newest" {
+						newest := 0
+						for i := range sources {
+							if modTimes[sources[i]].After(modTimes[sources[newest]]) {
+								newest = i
+							}
+						}
+						allServers[name] = conflictingConfigs[newest]
+						fmt.Fprintf(cmd.OutOrStdout(), "Conflict for '%s': selected newest version from '%s'\n", name, sources[newest])
+						continue
+					} else if defaultChoice == "merge" {
+						merged, ok := conflictingConfigs[0], true
+						for _, config := range conflictingConfigs[1:] {
+							if merged, ok = mergeServerConfigs(merged, config); !ok {
+								break
+							}
+						}
+						if ok {
+							allServers[name] = merged
+							fmt.Fprintf(cmd.OutOrStdout(), "Conflict for '%s': merged versions from %s\n", name, strings.Join(sources, ", "))
+							continue
+						}
+						fmt.Fprintf(cmd.OutOrStdout(), "Conflict for '%s': versions run different commands and can't be merged\n", name)
 					}
 
 					// Interactive resolution
@@ -1573,40 +2651,21 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 				jsonPath := aliasConfig.JSONPath
 
 				// Read the existing file to preserve its structure
-				var configData map[string]interface{}
-				if _, err := os.Stat(configFile); err == nil {
-					// File exists, read and parse it
-					data, err := os.ReadFile(configFile) //nolint:gosec // File path is validated earlier
-					if err == nil {
-						if unmarshalErr := json.Unmarshal(data, &configData); unmarshalErr != nil {
-							// Handle unmarshaling error
-							configData = make(map[string]interface{})
-						}
-					}
+				configData, err := readConfigFile(configFile)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error reading config for '%s': %v\n", aliasName, err)
+					continue
 				}
 
-				// If we couldn't read the file or it was empty, create a new structure
-				if configData == nil {
-					configData = make(map[string]interface{})
+				// Replace the servers at the alias's JSONPath, keeping the rest of the file
+				servers, ok := serversAtPath(configData, jsonPath, true)
+				if !ok {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid JSONPath %q for alias '%s'\n", jsonPath, aliasName)
+					continue
 				}
-
-				// Structure the output based on the JSONPath
-				if strings.Contains(jsonPath, "mcp.servers") {
-					// VS Code format
-					if _, ok := configData["mcp"]; !ok {
-						configData["mcp"] = map[string]interface{}{}
-					}
-
-					mcpMap, ok := configData["mcp"].(map[string]interface{})
-					if !ok {
-						mcpMap = map[string]interface{}{}
-						configData["mcp"] = mcpMap
-					}
-
-					mcpMap["servers"] = allServers
-				} else {
-					// Other formats with mcpServers (default)
-					configData["mcpServers"] = allServers
+				clear(servers)
+				for name, serverConfig := range allServers {
+					servers[name] = serverConfig
 				}
 
 				// Write the merged config
@@ -1616,7 +2675,7 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 					continue
 				}
 
-				if err := os.WriteFile(configFile, data, filePermissions); err != nil {
+				if err := writeConfigFile(configFile, data); err != nil {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Error writing merged config to %s: %v\n", configFile, err)
 					continue
 				}
@@ -1632,10 +2691,12 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 
 	// Add flags to the sync command
 	syncCmd.Flags().StringVar(&OutputAliasOption, "output", "", "Output alias (defaults to first alias)")
-	syncCmd.Flags().StringVar(&DefaultChoiceOption, "default", "interactive", "Default choice for conflicts: 'first', 'second', or 'interactive'")
+	syncCmd.Flags().StringVar(&DefaultChoiceOption, "default", "interactive", "Default choice for conflicts: 'first', 'second', 'newest', 'merge', or 'interactive'")
+	syncCmd.Flags().BoolVar(&NoBackupOption, "no-backup", false, "Don't keep backups of the config files")
+	syncCmd.Flags().BoolVar(&ForceOption, "force", false, "Rewrite the config files even if its comments and trailing commas would be lost")
 
 	// Add subcommands to the configs command
-	cmd.AddCommand(lsCmd, viewCmd, setCmd, removeCmd, aliasCmd, syncCmd, scanCmd)
+	cmd.AddCommand(lsCmd, viewCmd, setCmd, removeCmd, renameCmd, aliasCmd, syncCmd, scanCmd)
 
 	// Add the as-json subcommand
 	asJSONCmd := &cobra.Command{
@@ -1694,6 +2755,13 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 			firstArg := cleanedArgs[0]
 			isURL := strings.HasPrefix(firstArg, "http://") || strings.HasPrefix(firstArg, "https://")
 
+			// Parse environment variables if provided (for both URL and command)
+			envMap, err := parseKeyValueOption(env)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error parsing environment variables: %v\n", err)
+				return
+			}
+
 			// Create the server configuration
 			serverConfig := make(map[string]interface{})
 
@@ -1716,22 +2784,23 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 				// Command-based server
 				serverConfig["command"] = firstArg
 
+				commandArgs := cleanedArgs[1:]
+				if runtime := containerRuntime(firstArg); runtime != "" {
+					var addedInteractive bool
+					commandArgs, envMap, addedInteractive = structureContainerRun(commandArgs, envMap)
+					if addedInteractive {
+						fmt.Fprintf(cmd.ErrOrStderr(), "Note: added -i to %s run so the server can read requests from stdin\n", runtime)
+					}
+				}
+
 				// Add command args if provided
-				if len(cleanedArgs) > 1 {
-					serverConfig["args"] = cleanedArgs[1:]
+				if len(commandArgs) > 0 {
+					serverConfig["args"] = commandArgs
 				}
 			}
 
-			// Parse environment variables if provided (for both URL and command)
-			if env != "" {
-				envMap, err := parseKeyValueOption(env)
-				if err != nil {
-					fmt.Fprintf(cmd.ErrOrStderr(), "Error parsing environment variables: %v\n", err)
-					return
-				}
-				if len(envMap) > 0 {
-					serverConfig["env"] = envMap
-				}
+			if len(envMap) > 0 {
+				serverConfig["env"] = envMap
 			}
 
 			// Output the JSON configuration
@@ -1752,5 +2821,194 @@ VS Code, VS Code Insiders, Windsurf, Cursor, Claude Desktop, Claude Code`,
 	// Add the as-json command to the main command
 	cmd.AddCommand(asJSONCmd)
 
+	// Add the diff subcommand
+	diffCmd := &cobra.Command{
+		Use:   "diff [alias1] [alias2]",
+		Short: "Show how the MCP servers of two aliases differ",
+		Long: `Compare the MCP servers of two aliases, listing the servers that only exist in one of them
+and the servers that exist in both with different configurations. Use --format json for
+machine-readable output.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			configs, err := loadConfigsFile()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configs: %v\n", err)
+				return
+			}
+
+			first, err := loadAliasServers(configs, args[0])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return
+			}
+			second, err := loadAliasServers(configs, args[1])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return
+			}
+
+			diff := diffServers(first, second)
+
+			if strings.ToLower(FormatOption) == formatJSON {
+				output, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error generating JSON: %v\n", err)
+					return
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(output))
+				return
+			}
+
+			out := cmd.OutOrStdout()
+			if len(diff.OnlyInFirst)+len(diff.OnlyInSecond)+len(diff.Different) == 0 {
+				fmt.Fprintf(out, "No differences between '%s' and '%s'\n", args[0], args[1])
+				return
+			}
+
+			if len(diff.OnlyInFirst) > 0 {
+				fmt.Fprintf(out, "Only in '%s':\n", args[0])
+				for _, name := range sortedServerNames(diff.OnlyInFirst) {
+					fmt.Fprintf(out, "  %s\n", name)
+				}
+				fmt.Fprintln(out)
+			}
+
+			if len(diff.OnlyInSecond) > 0 {
+				fmt.Fprintf(out, "Only in '%s':\n", args[1])
+				for _, name := range sortedServerNames(diff.OnlyInSecond) {
+					fmt.Fprintf(out, "  %s\n", name)
+				}
+				fmt.Fprintln(out)
+			}
+
+			for _, name := range sortedServerNames(diff.Different) {
+				fmt.Fprintf(out, "Different: %s\n", name)
+				fmt.Fprintf(out, "--- %s\n%s\n", args[0], formatJSONForComparison(diff.Different[name][0]))
+				fmt.Fprintf(out, "+++ %s\n%s\n\n", args[1], formatJSONForComparison(diff.Different[name][1]))
+			}
+		},
+	}
+	cmd.AddCommand(diffCmd)
+
+	// Add the export subcommand
+	var exportAliases string
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export MCP servers from configured aliases as a single config",
+		Long: `Export the MCP servers of the given aliases (or all aliases) as a single portable
+{"mcpServers": {...}} document on stdout. Servers defined identically by several aliases are
+exported once; for conflicting definitions of the same server name the first alias wins and
+the conflict is reported on stderr.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			configs, err := loadConfigsFile()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configs: %v\n", err)
+				return
+			}
+
+			var aliasNames []string
+			explicit := exportAliases != ""
+			if explicit {
+				for _, aliasName := range strings.Split(exportAliases, ",") {
+					if aliasName = strings.TrimSpace(aliasName); aliasName != "" {
+						aliasNames = append(aliasNames, aliasName)
+					}
+				}
+			} else {
+				for aliasName := range configs.Aliases {
+					aliasNames = append(aliasNames, aliasName)
+				}
+				sort.Strings(aliasNames)
+			}
+
+			servers := exportServers(configs, aliasNames, explicit, cmd.ErrOrStderr())
+
+			output, err := json.MarshalIndent(map[string]interface{}{"mcpServers": servers}, "", "  ")
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error generating JSON: %v\n", err)
+				return
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		},
+	}
+	exportCmd.Flags().StringVar(&exportAliases, "aliases", "", "Comma-separated aliases to export (defaults to all)")
+	cmd.AddCommand(exportCmd)
+
+	// Add the import subcommand
+	var importOverwrite bool
+	var importSkipExisting bool
+	importCmd := &cobra.Command{
+		Use:   "import [alias] [file]",
+		Short: "Import MCP servers from a config file into an alias",
+		Long: `Import the servers of a {"mcpServers": {...}} document, such as the output of "configs export",
+or a VS Code settings file with "mcp.servers", into the config file of an alias.
+Servers that already exist in the target are skipped unless --overwrite is given.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if importOverwrite && importSkipExisting {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --overwrite and --skip-existing cannot be used together\n")
+				return
+			}
+
+			aliasName := args[0]
+			servers, err := readImportedServers(args[1])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error reading %s: %v\n", args[1], err)
+				return
+			}
+
+			configs, err := loadConfigsFile()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configs: %v\n", err)
+				return
+			}
+
+			configFile, jsonPath, err := getConfigFileAndPath(configs, aliasName, "")
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return
+			}
+
+			configData, err := readConfigFile(configFile)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return
+			}
+
+			added, updated, skipped := importServers(configData, jsonPath, servers, importOverwrite)
+			if len(added)+len(updated) > 0 {
+				data, err := json.MarshalIndent(configData, "", "  ")
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error marshaling config: %v\n", err)
+					return
+				}
+
+				if err := writeConfigFile(configFile, data); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error writing config file: %v\n", err)
+					return
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			for _, name := range added {
+				fmt.Fprintf(out, "Added: %s\n", name)
+			}
+			for _, name := range updated {
+				fmt.Fprintf(out, "Updated: %s\n", name)
+			}
+			for _, name := range skipped {
+				fmt.Fprintf(out, "Skipped: %s\n", name)
+			}
+			fmt.Fprintf(out, "\nSummary: %d added, %d updated, %d skipped in %s\n", len(added), len(updated), len(skipped), configFile)
+		},
+	}
+	importCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "Replace servers that already exist in the target")
+	importCmd.Flags().BoolVar(&importSkipExisting, "skip-existing", false, "Keep servers that already exist in the target (default)")
+	importCmd.Flags().BoolVar(&NoBackupOption, "no-backup", false, "Don't keep a backup of the config file")
+	importCmd.Flags().BoolVar(&ForceOption, "force", false, "Rewrite the config file even if its comments and trailing commas would be lost")
+	cmd.AddCommand(importCmd)
+
 	return cmd
 }
@@ -0,0 +1,821 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/f/mcptools/pkg/alias"
+	"github.com/mark3labs/mcp-go/client"
+)
+
+func TestDiffServers(t *testing.T) {
+	first := map[string]map[string]interface{}{
+		"shared":  {"command": "npx", "args": []interface{}{"-y", "server"}},
+		"changed": {"command": "uvx", "args": []interface{}{"old"}},
+		"first":   {"url": "https://example.com/mcp"},
+	}
+	second := map[string]map[string]interface{}{
+		"shared":  {"args": []interface{}{"-y", "server"}, "command": "npx"},
+		"changed": {"command": "uvx", "args": []interface{}{"new"}},
+		"second":  {"command": "docker"},
+	}
+
+	diff := diffServers(first, second)
+
+	if got := sortedServerNames(diff.OnlyInFirst); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Errorf("OnlyInFirst = %v, want [first]", got)
+	}
+	if got := sortedServerNames(diff.OnlyInSecond); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("OnlyInSecond = %v, want [second]", got)
+	}
+	if got := sortedServerNames(diff.Different); !reflect.DeepEqual(got, []string{"changed"}) {
+		t.Errorf("Different = %v, want [changed]", got)
+	}
+	if !reflect.DeepEqual(diff.Different["changed"][1], second["changed"]) {
+		t.Errorf("Different[changed] second = %v, want %v", diff.Different["changed"][1], second["changed"])
+	}
+}
+
+func TestExportServers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	configs := &ConfigsFile{Aliases: map[string]ConfigAlias{
+		"cursor": {
+			Path:     writeConfig("cursor.json", `{"mcpServers": {"shared": {"command": "npx", "args": ["server"]}, "conflict": {"command": "uvx", "args": ["first"]}}}`),
+			JSONPath: "mcpServers",
+		},
+		"vscode": {
+			Path:     writeConfig("vscode.json", `{"mcp": {"servers": {"shared": {"args": ["server"], "command": "npx"}, "conflict": {"command": "uvx", "args": ["second"]}, "vscode-only": {"url": "https://example.com/mcp"}}}}`),
+			JSONPath: "mcp.servers",
+		},
+		"missing": {
+			Path:     filepath.Join(dir, "missing.json"),
+			JSONPath: "mcpServers",
+		},
+	}}
+
+	testCases := []struct { //nolint:govet
+		name        string
+		aliasNames  []string
+		warnMissing bool
+		expected    map[string][]interface{}
+		errOut      []string
+	}{
+		{
+			name:       "identical servers are exported once",
+			aliasNames: []string{"cursor", "vscode"},
+			expected: map[string][]interface{}{
+				"shared":      {"server"},
+				"conflict":    {"first"},
+				"vscode-only": nil,
+			},
+			errOut: []string{"Conflict for 'conflict': alias 'vscode' differs from 'cursor', keeping the definition from 'cursor'"},
+		},
+		{
+			name:       "the first alias wins a conflict",
+			aliasNames: []string{"vscode", "cursor"},
+			expected: map[string][]interface{}{
+				"shared":      {"server"},
+				"conflict":    {"second"},
+				"vscode-only": nil,
+			},
+			errOut: []string{"Conflict for 'conflict': alias 'cursor' differs from 'vscode', keeping the definition from 'vscode'"},
+		},
+		{
+			name:       "missing config files are skipped silently",
+			aliasNames: []string{"missing", "cursor"},
+			expected: map[string][]interface{}{
+				"shared":   {"server"},
+				"conflict": {"first"},
+			},
+		},
+		{
+			name:        "missing config files are reported when requested",
+			aliasNames:  []string{"missing"},
+			warnMissing: true,
+			expected:    map[string][]interface{}{},
+			errOut:      []string{"Warning: config file for alias 'missing' not found"},
+		},
+		{
+			name:       "unknown aliases are reported",
+			aliasNames: []string{"unknown", "Cursor"},
+			expected: map[string][]interface{}{
+				"shared":   {"server"},
+				"conflict": {"first"},
+			},
+			errOut: []string{"Warning: alias 'unknown' not found, skipping"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var errOut strings.Builder
+			servers := exportServers(configs, tc.aliasNames, tc.warnMissing, &errOut)
+
+			if got, want := sortedServerNames(servers), sortedServerNames(tc.expected); !reflect.DeepEqual(got, want) {
+				t.Errorf("exportServers() names = %v, want %v", got, want)
+			}
+			for name, args := range tc.expected {
+				if args == nil {
+					continue
+				}
+				if got := servers[name]["args"]; !reflect.DeepEqual(got, args) {
+					t.Errorf("exportServers()[%s] args = %v, want %v", name, got, args)
+				}
+			}
+
+			if len(tc.errOut) == 0 && errOut.Len() > 0 {
+				t.Errorf("exportServers() errOut = %q, want nothing", errOut.String())
+			}
+			for _, want := range tc.errOut {
+				if !strings.Contains(errOut.String(), want) {
+					t.Errorf("exportServers() errOut = %q, want it to contain %q", errOut.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadImportedServers(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "mcpServers", content: `{"mcpServers": {"github": {"command": "npx"}}}`},
+		{name: "VS Code mcp.servers", content: `{"editor.fontSize": 14, "mcp": {"servers": {"github": {"command": "npx"}}}}`},
+		{name: "mcpServers wins over mcp", content: `{"mcp": {"servers": {"other": {}}}, "mcpServers": {"github": {"command": "npx"}}}`},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.Repeat("x", i+1)+".json")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			servers, err := readImportedServers(path)
+			if err != nil {
+				t.Fatalf("readImportedServers() error = %v", err)
+			}
+			if got := sortedServerNames(servers); !reflect.DeepEqual(got, []string{"github"}) || servers["github"]["command"] != "npx" {
+				t.Errorf("readImportedServers() = %v, want the github server", servers)
+			}
+		})
+	}
+
+	path := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(path, []byte(`{"servers": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readImportedServers(path); err == nil {
+		t.Error("readImportedServers() without servers error = nil, want an error")
+	}
+}
+
+func TestImportServers(t *testing.T) {
+	servers := map[string]map[string]interface{}{
+		"new":       {"command": "npx", "args": []interface{}{"new"}},
+		"same":      {"command": "npx", "args": []interface{}{"same"}},
+		"different": {"command": "npx", "args": []interface{}{"imported"}},
+	}
+
+	testCases := []struct { //nolint:govet
+		name      string
+		overwrite bool
+		added     []string
+		updated   []string
+		skipped   []string
+		different []interface{}
+	}{
+		{
+			name:      "existing servers are skipped by default",
+			added:     []string{"new"},
+			skipped:   []string{"different", "same"},
+			different: []interface{}{"existing"},
+		},
+		{
+			name:      "differing servers are replaced with overwrite",
+			overwrite: true,
+			added:     []string{"new"},
+			updated:   []string{"different"},
+			skipped:   []string{"same"},
+			different: []interface{}{"imported"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configData := map[string]interface{}{
+				"mcp": map[string]interface{}{
+					"servers": map[string]interface{}{
+						"same":      map[string]interface{}{"args": []interface{}{"same"}, "command": "npx"},
+						"different": map[string]interface{}{"command": "npx", "args": []interface{}{"existing"}},
+					},
+				},
+			}
+
+			added, updated, skipped := importServers(configData, "mcp.servers", servers, tc.overwrite)
+
+			if !reflect.DeepEqual(added, tc.added) || !reflect.DeepEqual(updated, tc.updated) || !reflect.DeepEqual(skipped, tc.skipped) {
+				t.Errorf("importServers() = %v, %v, %v, want %v, %v, %v", added, updated, skipped, tc.added, tc.updated, tc.skipped)
+			}
+			if server, ok := getServerFromConfig(configData, "mcp.servers", "new"); !ok || server["command"] != "npx" {
+				t.Errorf("new server = %v, want it added", server)
+			}
+			if server, _ := getServerFromConfig(configData, "mcp.servers", "different"); !reflect.DeepEqual(server["args"], tc.different) {
+				t.Errorf("different server args = %v, want %v", server["args"], tc.different)
+			}
+		})
+	}
+}
+
+func TestMergeServerConfigs(t *testing.T) {
+	testCases := []struct { //nolint:govet
+		name     string
+		first    map[string]interface{}
+		second   map[string]interface{}
+		expected map[string]interface{}
+		ok       bool
+	}{
+		{
+			name: "env is combined with the first definition winning",
+			first: map[string]interface{}{
+				"command": "npx",
+				"env":     map[string]interface{}{"TOKEN": "first", "DEBUG": "1"},
+			},
+			second: map[string]interface{}{
+				"command": "npx",
+				"env":     map[string]interface{}{"TOKEN": "second", "REGION": "eu"},
+			},
+			expected: map[string]interface{}{
+				"command": "npx",
+				"env":     map[string]interface{}{"TOKEN": "first", "DEBUG": "1", "REGION": "eu"},
+			},
+			ok: true,
+		},
+		{
+			name:     "headers are combined",
+			first:    map[string]interface{}{"url": "https://example.com/mcp", "headers": map[string]interface{}{"A": "1"}},
+			second:   map[string]interface{}{"url": "https://example.com/mcp", "headers": map[string]interface{}{"B": "2"}},
+			expected: map[string]interface{}{"url": "https://example.com/mcp", "headers": map[string]interface{}{"A": "1", "B": "2"}},
+			ok:       true,
+		},
+		{
+			name:     "longer args extending the other are kept",
+			first:    map[string]interface{}{"command": "npx", "args": []interface{}{"-y", "server"}},
+			second:   map[string]interface{}{"command": "npx", "args": []interface{}{"-y", "server", "~/dir"}},
+			expected: map[string]interface{}{"command": "npx", "args": []interface{}{"-y", "server", "~/dir"}},
+			ok:       true,
+		},
+		{
+			name:     "missing args take the other args",
+			first:    map[string]interface{}{"command": "npx", "args": []interface{}{"server"}},
+			second:   map[string]interface{}{"command": "npx", "env": map[string]interface{}{"A": "1"}},
+			expected: map[string]interface{}{"command": "npx", "args": []interface{}{"server"}, "env": map[string]interface{}{"A": "1"}},
+			ok:       true,
+		},
+		{
+			name:   "diverging args can't be merged",
+			first:  map[string]interface{}{"command": "npx", "args": []interface{}{"server-a"}},
+			second: map[string]interface{}{"command": "npx", "args": []interface{}{"server-b"}},
+			ok:     false,
+		},
+		{
+			name:   "different commands can't be merged",
+			first:  map[string]interface{}{"command": "npx"},
+			second: map[string]interface{}{"command": "uvx"},
+			ok:     false,
+		},
+		{
+			name:   "different urls can't be merged",
+			first:  map[string]interface{}{"url": "https://a.example.com/mcp"},
+			second: map[string]interface{}{"url": "https://b.example.com/mcp"},
+			ok:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, ok := mergeServerConfigs(tc.first, tc.second)
+			if ok != tc.ok {
+				t.Fatalf("mergeServerConfigs() ok = %v, want %v", ok, tc.ok)
+			}
+			if ok && !reflect.DeepEqual(merged, tc.expected) {
+				t.Errorf("mergeServerConfigs() = %v, want %v", merged, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	originalNoBackup := NoBackupOption
+	defer func() { NoBackupOption = originalNoBackup }()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(target, []byte(`{"old":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing through a symlink updates the target and keeps the link
+	link := filepath.Join(dir, "link.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	NoBackupOption = false
+	if err := writeConfigFile(link, []byte(`{"new":true}`)); err != nil {
+		t.Fatalf("writeConfigFile() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(target)
+	if string(data) != `{"new":true}` {
+		t.Errorf("target content = %s", data)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link was replaced by a regular file")
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0o644 {
+		t.Errorf("permissions = %v, want 0644", info.Mode().Perm())
+	}
+
+	backups, _ := filepath.Glob(target + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != `{"old":true}` {
+		t.Errorf("backup content = %s", data)
+	}
+
+	NoBackupOption = true
+	if err := os.Remove(backups[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeConfigFile(target, []byte(`{"newer":true}`)); err != nil {
+		t.Fatalf("writeConfigFile() error = %v", err)
+	}
+	if backups, _ := filepath.Glob(target + ".*.bak"); len(backups) != 0 {
+		t.Errorf("expected no backup with NoBackupOption, got %v", backups)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestWriteConfigFileKeepsComments(t *testing.T) {
+	originalForce, originalNoBackup := ForceOption, NoBackupOption
+	defer func() { ForceOption, NoBackupOption = originalForce, originalNoBackup }()
+	ForceOption, NoBackupOption = false, false
+
+	settings := filepath.Join(t.TempDir(), "settings.json")
+	jsonc := "{\n  // Editor\n  \"editor.fontSize\": 14,\n  \"mcp\": {\n    \"servers\": {},\n  },\n}\n"
+	if err := os.WriteFile(settings, []byte(jsonc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding a server reads the settings, but writing them back would drop the comments
+	configData, err := readConfigFile(settings)
+	if err != nil {
+		t.Fatalf("readConfigFile() error = %v", err)
+	}
+	addServerToConfig(configData, "mcp.servers", "github", map[string]interface{}{"command": "npx"})
+	data, err := json.MarshalIndent(configData, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeConfigFile(settings, data); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("writeConfigFile() error = %v, want a refusal mentioning --force", err)
+	}
+	if got, _ := os.ReadFile(settings); string(got) != jsonc {
+		t.Errorf("settings were changed to %s", got)
+	}
+	if backups, _ := filepath.Glob(settings + ".*.bak"); len(backups) != 0 {
+		t.Errorf("expected no backup for a refused write, got %v", backups)
+	}
+
+	// With --force the settings are rewritten as plain JSON
+	ForceOption = true
+	if err := writeConfigFile(settings, data); err != nil {
+		t.Fatalf("writeConfigFile() with ForceOption error = %v", err)
+	}
+	configData, err = readConfigFile(settings)
+	if err != nil {
+		t.Fatalf("readConfigFile() error = %v", err)
+	}
+	if _, ok := getServerFromConfig(configData, "mcp.servers", "github"); !ok || configData["editor.fontSize"] != float64(14) {
+		t.Errorf("rewritten settings = %v", configData)
+	}
+}
+
+func TestServerConfigTargetExpandsEnv(t *testing.T) {
+	t.Setenv("MCPT_TEST_DATA", "/srv/data")
+	t.Setenv("MCPT_TEST_TOKEN", "secret")
+
+	serverConfig := map[string]interface{}{
+		"command": "node",
+		"args":    []interface{}{"${env:MCPT_TEST_DATA}/index", "$MCPT_TEST_DATA", "${workspaceFolder}", "$MCPT_TEST_UNSET"},
+		"env":     map[string]interface{}{"TOKEN": "Bearer ${MCPT_TEST_TOKEN}"},
+	}
+	target, err := serverConfigTarget(serverConfig)
+	if err != nil {
+		t.Fatalf("serverConfigTarget() error = %v", err)
+	}
+
+	wantArgs := []string{"node", "/srv/data/index", "/srv/data", "${workspaceFolder}", "$MCPT_TEST_UNSET"}
+	if !reflect.DeepEqual(target.args, wantArgs) {
+		t.Errorf("args = %q, want %q", target.args, wantArgs)
+	}
+	if !reflect.DeepEqual(target.env, []string{"TOKEN=Bearer secret"}) {
+		t.Errorf("env = %q", target.env)
+	}
+
+	// The config itself is left as written
+	if arg := serverConfig["args"].([]interface{})[0]; arg != "${env:MCPT_TEST_DATA}/index" {
+		t.Errorf("config was modified: %v", arg)
+	}
+}
+
+func TestServerConfigTargetZedCommand(t *testing.T) {
+	serverConfig := map[string]interface{}{
+		"command": map[string]interface{}{
+			"path": "uvx",
+			"args": []interface{}{"mcp-server-time"},
+			"env":  map[string]interface{}{"TZ": "UTC"},
+		},
+		"settings": map[string]interface{}{},
+	}
+	target, err := serverConfigTarget(serverConfig)
+	if err != nil {
+		t.Fatalf("serverConfigTarget() error = %v", err)
+	}
+	if !reflect.DeepEqual(target.args, []string{"uvx", "mcp-server-time"}) {
+		t.Errorf("args = %q", target.args)
+	}
+	if !reflect.DeepEqual(target.env, []string{"TZ=UTC"}) {
+		t.Errorf("env = %q", target.env)
+	}
+
+	if _, err := serverConfigTarget(map[string]interface{}{"command": map[string]interface{}{}}); err == nil {
+		t.Error("expected an error for a command object without a path")
+	}
+}
+
+func TestVerifyServerConfig(t *testing.T) {
+	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		if method != "tools/list" {
+			t.Errorf("Expected method 'tools/list', got %q", method)
+		}
+		return map[string]any{
+			"tools": []any{map[string]any{"name": "read"}, map[string]any{"name": "write"}},
+		}, nil
+	})
+	defer cleanup()
+
+	// The server is reached with the target of its config entry
+	var got serverTarget
+	mockTargetFunc := createTargetClientFunc
+	createTargetClientFunc = func(target serverTarget) (*client.Client, error) {
+		got = target
+		return mockTargetFunc(target)
+	}
+
+	originalTransport, originalHeaders, originalEnv := TransportOption, HeaderOptions, ServerEnvOptions
+	defer func() {
+		TransportOption, HeaderOptions, ServerEnvOptions = originalTransport, originalHeaders, originalEnv
+	}()
+	TransportOption, HeaderOptions, ServerEnvOptions = "http", []string{"X-Flag: 1"}, nil
+
+	toolCount, err := verifyServerConfig(map[string]interface{}{
+		"type":    "sse",
+		"url":     "https://example.com/sse",
+		"headers": map[string]interface{}{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("verifyServerConfig() error = %v", err)
+	}
+	if toolCount != 2 {
+		t.Errorf("verifyServerConfig() = %d tools, want 2", toolCount)
+	}
+	if got.transport != "sse" || !reflect.DeepEqual(got.args, []string{"https://example.com/sse"}) {
+		t.Errorf("verifyServerConfig() target = %+v", got)
+	}
+
+	// The flags of the command are left as they were
+	if TransportOption != "http" || !reflect.DeepEqual(HeaderOptions, []string{"X-Flag: 1"}) || ServerEnvOptions != nil {
+		t.Errorf("verifyServerConfig() changed the flags to %q, %q, %q", TransportOption, HeaderOptions, ServerEnvOptions)
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain JSON", input: `{"a":[1,2]}`, expected: `{"a":[1,2]}`},
+		{name: "line comment", input: "{\"a\":1 // note\n}", expected: "{\"a\":1        \n}"},
+		{name: "block comment", input: "{/* a\nb */\"a\":1}", expected: "{    \n    \"a\":1}"},
+		{name: "trailing commas", input: `{"a":[1,2,],"b":{"c":1,},}`, expected: `{"a":[1,2 ],"b":{"c":1 } }`},
+		{name: "trailing comma before comment", input: "{\"a\":1, // last\n}", expected: "{\"a\":1         \n}"},
+		{name: "comment markers in strings", input: `{"url":"http://x/*y*/","s":"a,]"}`, expected: `{"url":"http://x/*y*/","s":"a,]"}`},
+		{name: "escaped quote", input: `{"s":"a\"//b",}`, expected: `{"s":"a\"//b" }`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(stripJSONC([]byte(tc.input))); got != tc.expected {
+				t.Errorf("stripJSONC(%q) = %q, want %q", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Zed settings have comments and trailing commas
+	settings := filepath.Join(dir, "settings.json")
+	jsonc := "// Zed settings\n{\n  \"theme\": \"One Dark\", /* dark */\n  \"context_servers\": {},\n}\n"
+	if err := os.WriteFile(settings, []byte(jsonc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configData, err := readConfigFile(settings)
+	if err != nil {
+		t.Fatalf("readConfigFile() error = %v", err)
+	}
+	if configData["theme"] != "One Dark" {
+		t.Errorf("readConfigFile() = %v", configData)
+	}
+
+	// A missing file is an empty config
+	if configData, err := readConfigFile(filepath.Join(dir, "missing.json")); err != nil || len(configData) != 0 {
+		t.Errorf("readConfigFile() of a missing file = %v, %v", configData, err)
+	}
+
+	// A file that can't be parsed is an error rather than an empty config to overwrite it with
+	broken := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"theme": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readConfigFile(broken); err == nil || !strings.Contains(err.Error(), "leaving it unchanged") {
+		t.Errorf("readConfigFile() of an invalid file error = %v", err)
+	}
+}
+
+func TestResolveProjectServer(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+
+	// Without a .mcp.json nothing is resolved
+	if target, err := resolveProjectServer("fs"); target != nil || err != nil {
+		t.Fatalf("resolveProjectServer() = %v, %v without a project config", target, err)
+	}
+
+	config := `{"mcpServers": {"fs": {"command": "npx", "args": ["-y", "server-filesystem", "."], "env": {"DEBUG": "1"}}}}`
+	if err := os.WriteFile(filepath.Join(root, ".mcp.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := resolveProjectServer("fs")
+	if err != nil || target == nil {
+		t.Fatalf("resolveProjectServer() = %v, %v", target, err)
+	}
+	if !reflect.DeepEqual(target.args, []string{"npx", "-y", "server-filesystem", "."}) || !reflect.DeepEqual(target.env, []string{"DEBUG=1"}) {
+		t.Errorf("target = %+v", target)
+	}
+
+	if target, err := resolveProjectServer("other"); target != nil || err != nil {
+		t.Errorf("resolveProjectServer() = %v, %v for a server the project doesn't define", target, err)
+	}
+
+	// The only server is used when none is named
+	if target, err := resolveProjectServer(""); err != nil || target == nil || target.args[0] != "npx" {
+		t.Errorf("resolveProjectServer(\"\") = %v, %v", target, err)
+	}
+
+	config = `{"mcpServers": {"fs": {"command": "a"}, "db": {"command": "b"}}}`
+	if err := os.WriteFile(filepath.Join(nested, ".mcp.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolveProjectServer(""); err == nil || !strings.Contains(err.Error(), "db, fs") {
+		t.Errorf("resolveProjectServer(\"\") error = %v, want the servers to choose from", err)
+	}
+	if target, err := resolveProjectServer("db"); err != nil || target == nil || target.args[0] != "b" {
+		t.Errorf("resolveProjectServer() = %v, %v, want the nearest .mcp.json", target, err)
+	}
+
+	// Comments and trailing commas are accepted
+	config = `{
+		// The database server
+		"mcpServers": {"db": {"command": "b"},},
+	}`
+	if err := os.WriteFile(filepath.Join(nested, ".mcp.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := resolveProjectServer("db"); err != nil || target == nil || target.args[0] != "b" {
+		t.Errorf("resolveProjectServer() = %v, %v for a JSONC .mcp.json", target, err)
+	}
+
+	// A broken file leaves a named server to be run as a command, and is only reported when no
+	// server is named
+	if err := os.WriteFile(filepath.Join(nested, ".mcp.json"), []byte(`{"mcpServers": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := resolveProjectServer("/bin/true"); target != nil || err != nil {
+		t.Errorf("resolveProjectServer() = %v, %v for a broken .mcp.json", target, err)
+	}
+	if _, err := resolveProjectServer(""); err == nil || !strings.Contains(err.Error(), "failed to parse") {
+		t.Errorf("resolveProjectServer(\"\") error = %v, want the parse error", err)
+	}
+}
+
+func TestRenameServerInConfig(t *testing.T) {
+	configData := map[string]interface{}{
+		"mcp": map[string]interface{}{"servers": map[string]interface{}{
+			"filesystem": map[string]interface{}{"command": "npx"},
+			"db":         map[string]interface{}{"command": "db-server"},
+		}},
+	}
+
+	if err := renameServerInConfig(configData, "mcp.servers", "filesystem", "fs"); err != nil {
+		t.Fatalf("renameServerInConfig() error = %v", err)
+	}
+	if _, ok := getServerFromConfig(configData, "mcp.servers", "filesystem"); ok {
+		t.Error("old server is still present")
+	}
+	if server, ok := getServerFromConfig(configData, "mcp.servers", "fs"); !ok || server["command"] != "npx" {
+		t.Errorf("renamed server = %v, %v", server, ok)
+	}
+
+	if err := renameServerInConfig(configData, "mcp.servers", "missing", "x"); err == nil {
+		t.Error("renameServerInConfig() returned no error for a missing server")
+	}
+	if err := renameServerInConfig(configData, "mcp.servers", "fs", "db"); err == nil {
+		t.Error("renameServerInConfig() returned no error when the new name is taken")
+	}
+}
+
+func TestRenameConfigAlias(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configs := &ConfigsFile{Aliases: map[string]ConfigAlias{
+		"myapp": {Path: "/tmp/myapp.json", JSONPath: "$.mcpServers", Source: "myapp"},
+	}}
+	if err := alias.Save(alias.Aliases{
+		"fs":   {Command: "config:myapp/filesystem"},
+		"echo": {Command: "npx echo-server"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := renameConfigAlias(configs, "MyApp", "work"); err != nil {
+		t.Fatalf("renameConfigAlias() error = %v", err)
+	}
+
+	saved, err := loadConfigsFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.Aliases["myapp"]; ok {
+		t.Error("old alias is still present")
+	}
+	if got := saved.Aliases["work"]; got.Path != "/tmp/myapp.json" || got.Source != "work" {
+		t.Errorf("renamed alias = %+v", got)
+	}
+
+	serverAliases, err := alias.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serverAliases["fs"].Command != "config:work/filesystem" || serverAliases["echo"].Command != "npx echo-server" {
+		t.Errorf("server aliases = %+v", serverAliases)
+	}
+
+	if err := renameConfigAlias(configs, "missing", "x"); err == nil {
+		t.Error("renameConfigAlias() returned no error for a missing alias")
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "$.mcpServers", want: []string{"mcpServers"}},
+		{path: "$.tools.mcp.servers", want: []string{"tools", "mcp", "servers"}},
+		{path: "mcp.servers", want: []string{"mcp", "servers"}},
+		{path: "$['my.tool']['servers']", want: []string{"my.tool", "servers"}},
+		{path: "$.tools[\"mcp\"].servers", want: []string{"tools", "mcp", "servers"}},
+		{path: "$", wantErr: true},
+		{path: "$.tools..servers", wantErr: true},
+		{path: "$.servers[0]", wantErr: true},
+		{path: "$['servers'", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseJSONPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseJSONPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestServersAtCustomJSONPath(t *testing.T) {
+	configData := map[string]interface{}{"theme": "dark"}
+	jsonPath := "$.tools.mcp.servers"
+
+	addServerToConfig(configData, jsonPath, "fs", map[string]interface{}{"command": "npx"})
+	tools, _ := configData["tools"].(map[string]interface{})
+	mcp, _ := tools["mcp"].(map[string]interface{})
+	servers, _ := mcp["servers"].(map[string]interface{})
+	if _, ok := servers["fs"]; !ok {
+		t.Fatalf("server not added at %s: %v", jsonPath, configData)
+	}
+
+	if server, ok := getServerFromConfig(configData, jsonPath, "fs"); !ok || server["command"] != "npx" {
+		t.Errorf("getServerFromConfig() = %v, %v", server, ok)
+	}
+	if _, ok := getServerFromConfig(configData, "$.mcpServers", "fs"); ok {
+		t.Error("server found at the default path")
+	}
+
+	if !removeServerFromConfig(configData, jsonPath, "fs") {
+		t.Error("removeServerFromConfig() = false")
+	}
+	if removeServerFromConfig(configData, jsonPath, "fs") {
+		t.Error("removeServerFromConfig() removed a missing server")
+	}
+	if configData["theme"] != "dark" {
+		t.Errorf("other settings changed: %v", configData)
+	}
+}
+
+func TestStructureContainerRun(t *testing.T) {
+	args, env, addedInteractive := structureContainerRun(
+		[]string{"run", "--rm", "-e", "TOKEN=abc", "--env=REGION", "-v", "/data:/data", "image", "-e", "server-flag"},
+		map[string]string{"DEBUG": "1"},
+	)
+
+	wantArgs := []string{"run", "-i", "--rm", "-e", "TOKEN", "-e", "REGION", "-v", "/data:/data", "-e", "DEBUG", "image", "-e", "server-flag"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %q, want %q", args, wantArgs)
+	}
+	wantEnv := map[string]string{"TOKEN": "abc", "DEBUG": "1"}
+	if !reflect.DeepEqual(env, wantEnv) {
+		t.Errorf("env = %v, want %v", env, wantEnv)
+	}
+	if !addedInteractive {
+		t.Error("-i was not reported as added")
+	}
+
+	args, _, addedInteractive = structureContainerRun([]string{"run", "-it", "image"}, nil)
+	if addedInteractive || !reflect.DeepEqual(args, []string{"run", "-it", "image"}) {
+		t.Errorf("args with -it = %q, added -i = %v", args, addedInteractive)
+	}
+
+	args, _, _ = structureContainerRun([]string{"exec", "-e", "A=b", "container"}, nil)
+	if !reflect.DeepEqual(args, []string{"exec", "-e", "A=b", "container"}) {
+		t.Errorf("args of docker exec = %q, want them unchanged", args)
+	}
+}
+
+func TestFormatColoredGroupedServersContainerBadge(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	output := formatColoredGroupedServers([]ServerConfig{
+		{Source: "Cursor", Name: "github", Command: "/usr/local/bin/docker", Args: []string{"run", "-i", "image"}},
+		{Source: "Cursor", Name: "fs", Command: "npx", Args: []string{"fs"}},
+	})
+
+	if !strings.Contains(output, "github (docker):") {
+		t.Errorf("docker server not labeled as docker:\n%s", output)
+	}
+	if !strings.Contains(output, "fs (stdio):") {
+		t.Errorf("npx server not labeled as stdio:\n%s", output)
+	}
+}
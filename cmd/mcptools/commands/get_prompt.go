@@ -5,16 +5,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/itchyny/gojq"
+	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
 )
 
 // GetPromptCmd creates the get-prompt command.
 func GetPromptCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:                "get-prompt prompt [command args...]",
-		Short:              "Get a prompt on the MCP server",
+	return addRawFlag(&cobra.Command{
+		Use:   "get-prompt prompt [command args...]",
+		Short: "Get a prompt on the MCP server",
+		Long: `Get a prompt on an MCP server and print the messages it returns.
+
+--params takes a JSON object or a key=value pair and can be repeated, as for call. Use
+--render to print only the text of the messages, ready to be piped into another tool.
+Consecutive messages of the same role are joined by a blank line, and when the prompt has
+messages of several roles each group is introduced by its role and separated by ---.
+
+Use --query to run the result through a jq expression before it is formatted.
+
+Use --interactive to be asked in turn for each argument the prompt declares that wasn't
+given with --params.`,
 		DisableFlagParsing: true,
 		SilenceUsage:       true,
 		Run: func(thisCmd *cobra.Command, args []string) {
@@ -36,6 +51,11 @@ func GetPromptCmd() *cobra.Command {
 			parsedArgs := []string{}
 			promptName := ""
 
+			var query *gojq.Code
+			var paramValues []string
+			render := false
+			interactive := false
+
 			i := 0
 			promptExtracted := false
 
@@ -45,11 +65,38 @@ func GetPromptCmd() *cobra.Command {
 					FormatOption = cmdArgs[i+1]
 					i += 2
 				case (cmdArgs[i] == FlagParams || cmdArgs[i] == FlagParamsShort) && i+1 < len(cmdArgs):
-					ParamsString = cmdArgs[i+1]
+					paramValues = append(paramValues, cmdArgs[i+1])
 					i += 2
+				case cmdArgs[i] == FlagRender:
+					render = true
+					i++
+				case cmdArgs[i] == FlagInteractive:
+					interactive = true
+					i++
 				case cmdArgs[i] == FlagServerLogs:
 					ShowServerLogs = true
 					i++
+				case cmdArgs[i] == FlagQuery && i+1 < len(cmdArgs):
+					query = parseQueryFlag(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagRaw:
+					RawOutput = true
+					i++
+				case cmdArgs[i] == FlagIndent && i+1 < len(cmdArgs):
+					setIndentOption(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagCompact:
+					CompactOption = true
+					i++
+				case cmdArgs[i] == FlagNoColor:
+					setColorOption(string(jsonutils.ColorNever))
+					i++
+				case cmdArgs[i] == FlagColor && i+1 < len(cmdArgs):
+					setColorOption(cmdArgs[i+1])
+					i += 2
+				case strings.HasPrefix(cmdArgs[i], FlagColor+"="):
+					setColorOption(strings.TrimPrefix(cmdArgs[i], FlagColor+"="))
+					i++
 				case !promptExtracted:
 					promptName = cmdArgs[i]
 					promptExtracted = true
@@ -69,12 +116,23 @@ func GetPromptCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
-			var params map[string]any
-			if ParamsString != "" {
-				if jsonErr := json.Unmarshal([]byte(ParamsString), &params); jsonErr != nil {
-					fmt.Fprintf(os.Stderr, "Error: invalid JSON for params: %v\n", jsonErr)
-					os.Exit(1)
-				}
+			if RawOutput && (query != nil) {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s\n", FlagRaw, FlagQuery)
+				os.Exit(1)
+			}
+
+			if render && (RawOutput || query != nil) {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s or %s\n", FlagRender, FlagRaw, FlagQuery)
+				os.Exit(1)
+			}
+
+			if len(paramValues) == 0 && ParamsString != "" {
+				paramValues = []string{ParamsString}
+			}
+			params, paramsErr := parseCallParams(paramValues)
+			if paramsErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", paramsErr)
+				os.Exit(1)
 			}
 
 			mcpClient, clientErr := CreateClientFunc(parsedArgs)
@@ -86,8 +144,28 @@ func GetPromptCmd() *cobra.Command {
 
 			request := mcp.GetPromptRequest{}
 			request.Params.Name = promptName
+			request.Params.Arguments = promptArguments(params)
+			if interactive {
+				arguments, askErr := askPromptArguments(mcpClient, promptName, request.Params.Arguments)
+				if askErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", askErr)
+					CloseWithTimeout(mcpClient)
+					os.Exit(1)
+				}
+				request.Params.Arguments = arguments
+			}
+			if argsErr := checkPromptArguments(mcpClient, promptName, request.Params.Arguments); argsErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", argsErr)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
 			resp, execErr := mcpClient.GetPrompt(context.Background(), request)
 
+			if execErr == nil && render {
+				fmt.Fprintln(thisCmd.OutOrStdout(), renderPromptText(resp.Messages))
+				return
+			}
+
 			var responseMap map[string]any
 			if execErr == nil && resp != nil {
 				responseMap = ConvertJSONToMap(resp)
@@ -95,10 +173,134 @@ func GetPromptCmd() *cobra.Command {
 				responseMap = map[string]any{}
 			}
 
-			if formatErr := FormatAndPrintResponse(thisCmd, responseMap, execErr); formatErr != nil {
+			if execErr == nil && RawOutput {
+				if rawErr := printRawResult(thisCmd, mcpClient, responseMap); rawErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", rawErr)
+					os.Exit(1)
+				}
+				return
+			}
+
+			var output any = responseMap
+			if execErr == nil && query != nil {
+				output, execErr = applyQuery(query, responseMap)
+			}
+
+			if formatErr := FormatAndPrintResponse(thisCmd, output, execErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
 		},
+	})
+}
+
+// promptArguments converts params to the string arguments of a prompt. Values that aren't
+// strings are passed as JSON.
+func promptArguments(params map[string]any) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	arguments := make(map[string]string, len(params))
+	for name, value := range params {
+		if s, ok := value.(string); ok {
+			arguments[name] = s
+			continue
+		}
+		data, _ := json.Marshal(value)
+		arguments[name] = string(data)
+	}
+	return arguments
+}
+
+// askPromptArguments asks on the terminal for the declared arguments of a prompt that aren't
+// in arguments, and returns all of them.
+func askPromptArguments(mcpClient *client.Client, promptName string, arguments map[string]string) (map[string]string, error) {
+	prompt, err := findPrompt(mcpClient, promptName)
+	if err != nil {
+		return nil, err
+	}
+
+	ask, restore := newTerminalAsk()
+	answers, err := askFields(ask, os.Stderr, promptWizardFields(prompt, arguments))
+	restore()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]string, len(arguments)+len(answers))
+	for name, value := range arguments {
+		merged[name] = value
+	}
+	for name, value := range answers {
+		merged[name] = value
+	}
+	return merged, nil
+}
+
+// checkPromptArguments reports the required arguments that the named prompt declares in
+// prompts/list but that aren't in arguments. Prompts that can't be listed or found are left
+// for the server to check.
+func checkPromptArguments(mcpClient *client.Client, promptName string, arguments map[string]string) error {
+	prompt, err := findPrompt(mcpClient, promptName)
+	if err != nil {
+		return nil
+	}
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if _, ok := arguments[arg.Name]; arg.Required && !ok {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prompt %s is missing required arguments: %s (pass them with %s name=value)",
+			promptName, strings.Join(missing, ", "), FlagParams)
+	}
+	return nil
+}
+
+// renderPromptText returns the text content of prompt messages. Consecutive messages of the
+// same role are joined by a blank line; if there are several roles, each group is headed by
+// its role and the groups are separated by a --- line.
+func renderPromptText(messages []mcp.PromptMessage) string {
+	type group struct {
+		role  mcp.Role
+		texts []string
+	}
+	var groups []group
+	roles := make(map[mcp.Role]bool)
+	for _, message := range messages {
+		text, ok := promptContentText(message.Content)
+		if !ok {
+			continue
+		}
+		roles[message.Role] = true
+		if len(groups) > 0 && groups[len(groups)-1].role == message.Role {
+			groups[len(groups)-1].texts = append(groups[len(groups)-1].texts, text)
+			continue
+		}
+		groups = append(groups, group{role: message.Role, texts: []string{text}})
+	}
+
+	rendered := make([]string, len(groups))
+	for i, g := range groups {
+		rendered[i] = strings.Join(g.texts, "\n\n")
+		if len(roles) > 1 {
+			rendered[i] = string(g.role) + ":\n" + rendered[i]
+		}
+	}
+	return strings.Join(rendered, "\n\n---\n\n")
+}
+
+// promptContentText returns the text of a prompt message's content, which is either text or
+// an embedded text resource.
+func promptContentText(content mcp.Content) (string, bool) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, true
+	case mcp.EmbeddedResource:
+		if resource, ok := c.Resource.(mcp.TextResourceContents); ok {
+			return resource.Text, true
+		}
 	}
+	return "", false
 }
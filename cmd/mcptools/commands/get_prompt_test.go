@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var summarizePrompts = map[string]any{
+	"prompts": []any{map[string]any{
+		"name": "summarize",
+		"arguments": []any{
+			map[string]any{"name": "topic", "required": true},
+			map[string]any{"name": "audience", "required": true},
+			map[string]any{"name": "words"},
+		},
+	}},
+}
+
+func TestGetPromptCmdRun_Render(t *testing.T) {
+	var arguments map[string]string
+	cleanup := setupMockClient(func(method string, params any) (map[string]any, error) {
+		if method == "prompts/list" {
+			return summarizePrompts, nil
+		}
+		if method != "prompts/get" {
+			t.Errorf("Expected method 'prompts/get', got %q", method)
+		}
+		data, _ := json.Marshal(params)
+		var request struct {
+			Arguments map[string]string `json:"arguments"`
+		}
+		_ = json.Unmarshal(data, &request)
+		arguments = request.Arguments
+		return map[string]any{
+			"messages": []any{
+				map[string]any{"role": "user", "content": map[string]any{"type": "text", "text": "Summarize x."}},
+				map[string]any{"role": "user", "content": map[string]any{"type": "text", "text": "Be brief."}},
+			},
+		}, nil
+	})
+	defer cleanup()
+
+	cmd := GetPromptCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"summarize", "-p", "topic=x", "-p", "audience=devs", "-p", `{"words":50}`, "--render", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	assertEquals(t, buf.String(), "Summarize x.\n\nBe brief.\n")
+	if want := map[string]string{"topic": "x", "audience": "devs", "words": "50"}; !reflect.DeepEqual(arguments, want) {
+		t.Errorf("arguments = %v, want %v", arguments, want)
+	}
+}
+
+func TestRenderPromptText(t *testing.T) {
+	messages := []mcp.PromptMessage{
+		{Role: mcp.RoleUser, Content: mcp.NewTextContent("Review this file:")},
+		{Role: mcp.RoleUser, Content: mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "file:///a.go", Text: "package a"})},
+		{Role: mcp.RoleUser, Content: mcp.NewImageContent("aGk=", "image/png")},
+		{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("Looks good.")},
+	}
+
+	want := "user:\nReview this file:\n\npackage a\n\n---\n\nassistant:\nLooks good."
+	assertEquals(t, renderPromptText(messages), want)
+}
+
+func TestCheckPromptArguments(t *testing.T) {
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return summarizePrompts, nil
+	})
+	defer cleanup()
+	mockClient, _ := CreateClientFunc(nil)
+
+	err := checkPromptArguments(mockClient, "summarize", map[string]string{"words": "50"})
+	if err == nil || !strings.Contains(err.Error(), "missing required arguments: topic, audience") {
+		t.Errorf("checkPromptArguments() error = %v, want topic and audience reported", err)
+	}
+
+	if err := checkPromptArguments(mockClient, "summarize", map[string]string{"topic": "x", "audience": "devs"}); err != nil {
+		t.Errorf("checkPromptArguments() error = %v", err)
+	}
+
+	// Prompts that aren't listed are left for the server to check
+	if err := checkPromptArguments(mockClient, "other", nil); err != nil {
+		t.Errorf("checkPromptArguments() error = %v for an unlisted prompt", err)
+	}
+}
@@ -3,10 +3,12 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/f/mcptools/pkg/alias"
 	"github.com/f/mcptools/pkg/guard"
+	"github.com/f/mcptools/pkg/logfile"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +18,8 @@ const (
 	FlagAllowShort = "-a"
 	FlagDeny       = "--deny"
 	FlagDenyShort  = "-d"
+	FlagLogMaxSize = "--log-max-size"
+	FlagLogBackups = "--log-backups"
 )
 
 var entityTypes = []string{
@@ -39,6 +43,9 @@ Examples:
 Patterns can include wildcards:
   * matches any sequence of characters
 
+Requests are logged to ~/.mcpt/logs/guard.log, which is rotated once it reaches --log-max-size
+bytes (10MB by default, 0 to never rotate), keeping --log-backups old files (3 by default).
+
 Entity types:
   tools: filter available tools
   prompts: filter available prompts
@@ -51,6 +58,12 @@ Entity types:
 				return
 			}
 
+			logOptions, args, err := extractLogOptions(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			// Process and extract the allow and deny patterns
 			allowPatterns, denyPatterns, cmdArgs := extractPatterns(args)
 
@@ -100,7 +113,7 @@ Entity types:
 
 			// Run the guard proxy with the filtered environment
 			fmt.Fprintf(os.Stderr, "Running command with filtered environment: %s\n", strings.Join(parsedArgs, " "))
-			if err := guard.RunFilterServer(guardAllowPatterns, guardDenyPatterns, parsedArgs); err != nil {
+			if err := guard.RunFilterServer(guardAllowPatterns, guardDenyPatterns, parsedArgs, logOptions); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -143,6 +156,33 @@ func extractPatterns(args []string) (map[string][]string, map[string][]string, [
 	return allowPatterns, denyPatterns, cmdArgs
 }
 
+// extractLogOptions extracts the --log-max-size and --log-backups flags from the arguments.
+func extractLogOptions(args []string) (logfile.Options, []string, error) {
+	options := logfile.DefaultOptions()
+	remainingArgs := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == FlagLogMaxSize && i+1 < len(args):
+			size, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || size < 0 {
+				return options, nil, fmt.Errorf("%s must be a number of bytes, got %q", FlagLogMaxSize, args[i+1])
+			}
+			options.MaxSize = size
+			i++
+		case args[i] == FlagLogBackups && i+1 < len(args):
+			backups, err := strconv.Atoi(args[i+1])
+			if err != nil || backups < 0 {
+				return options, nil, fmt.Errorf("%s must be a number of files, got %q", FlagLogBackups, args[i+1])
+			}
+			options.Backups = backups
+			i++
+		default:
+			remainingArgs = append(remainingArgs, args[i])
+		}
+	}
+	return options, remainingArgs, nil
+}
+
 // processPatternString processes a comma-separated pattern string.
 func processPatternString(patternsStr string, patternMap map[string][]string) {
 	patterns := strings.Split(patternsStr, ",")
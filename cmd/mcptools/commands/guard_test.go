@@ -3,6 +3,7 @@ package commands
 import (
 	"testing"
 
+	"github.com/f/mcptools/pkg/logfile"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -141,3 +142,18 @@ func TestProcessPatternString(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractLogOptions(t *testing.T) {
+	options, args, err := extractLogOptions([]string{"--allow", "tools:read_*", "--log-max-size", "1024", "--log-backups", "0", "npx", "server"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), options.MaxSize)
+	assert.Equal(t, 0, options.Backups)
+	assert.Equal(t, []string{"--allow", "tools:read_*", "npx", "server"}, args)
+
+	options, _, err = extractLogOptions([]string{"npx", "server"})
+	assert.NoError(t, err)
+	assert.Equal(t, logfile.DefaultOptions(), options)
+
+	_, _, err = extractLogOptions([]string{"--log-max-size", "10MB", "npx"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// knownCapabilities are the server capabilities defined by the MCP specification, in the
+// order they are shown.
+var knownCapabilities = []string{"tools", "resources", "prompts", "logging", "completions"}
+
+// InfoCmd creates the info command.
+func InfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info [command args...]",
+		Short: "Show what an MCP server supports",
+		Long: `Connect to an MCP server and show the server name and version, the protocol version that was
+negotiated, and the capabilities the server advertised in its initialize response, followed by
+the client info and capabilities that were sent, which --client-name, --client-version,
+--capability and --init-params change. Use -- to separate the server command.
+
+  mcp info -- npx -y @modelcontextprotocol/server-filesystem ~
+  mcp info --capability sampling --capability roots -- npx -y @modelcontextprotocol/server-everything`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			parsedArgs := ProcessFlags(args)
+			if len(parsedArgs) > 0 && parsedArgs[0] == "--" {
+				parsedArgs = parsedArgs[1:]
+			}
+
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp info -- npx -y @modelcontextprotocol/server-filesystem ~\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			info, ok := initializeResult(mcpClient)
+			if !ok {
+				fmt.Fprintln(os.Stderr, "Error: the server's initialize response is not available")
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+
+			if jsonutils.ParseFormat(FormatOption) != jsonutils.FormatTable {
+				if formatErr := FormatAndPrintResponse(thisCmd, info, nil); formatErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+					os.Exit(1)
+				}
+				return
+			}
+
+			initRequest, err := newInitializeRequest()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+			fmt.Fprint(thisCmd.OutOrStdout(), formatServerInfo(info, initRequest))
+		},
+	}
+}
+
+// formatServerInfo formats an initialize result as a table of the server, the protocol
+// version and each capability with the features it enables, followed by the client info and
+// capabilities of the initialize request it answered.
+func formatServerInfo(info map[string]any, request mcp.InitializeRequest) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	serverInfo, _ := info["serverInfo"].(map[string]any)
+	name, _ := serverInfo["name"].(string)
+	version, _ := serverInfo["version"].(string)
+	fmt.Fprintf(w, "Server:\t%s\n", strings.TrimSpace(name+" "+version))
+	protocolVersion, _ := info["protocolVersion"].(string)
+	fmt.Fprintf(w, "Protocol version:\t%s\n", protocolVersion)
+
+	fmt.Fprintln(w, "Capabilities:")
+	capabilities, _ := info["capabilities"].(map[string]any)
+	names := append([]string{}, knownCapabilities...)
+	var others []string
+	for capability := range capabilities {
+		if !slices.Contains(knownCapabilities, capability) {
+			others = append(others, capability)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	for _, capability := range names {
+		fmt.Fprintf(w, "  %s\t%s\n", capability, describeCapability(capabilities[capability]))
+	}
+	_ = w.Flush()
+
+	fmt.Fprintln(&buf, "\nClient:")
+	w = tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	clientInfo := request.Params.ClientInfo
+	fmt.Fprintf(w, "  name\t%s\n", strings.TrimSpace(clientInfo.Name+" "+clientInfo.Version))
+	fmt.Fprintf(w, "  capabilities\t%s\n", describeClientCapabilities(request.Params.Capabilities))
+	_ = w.Flush()
+
+	if instructions, _ := info["instructions"].(string); instructions != "" {
+		fmt.Fprintf(&buf, "\nInstructions:\n%s\n", instructions)
+	}
+	return buf.String()
+}
+
+// describeClientCapabilities lists the capabilities a client declared, such as
+// "roots (listChanged), sampling", or "none".
+func describeClientCapabilities(capabilities mcp.ClientCapabilities) string {
+	var names []string
+	if capabilities.Roots != nil {
+		if capabilities.Roots.ListChanged {
+			names = append(names, "roots (listChanged)")
+		} else {
+			names = append(names, "roots")
+		}
+	}
+	if capabilities.Sampling != nil {
+		names = append(names, "sampling")
+	}
+	for name := range capabilities.Experimental {
+		names = append(names, "experimental."+name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// describeCapability describes an advertised capability by the flags it sets, such as
+// "subscribe, listChanged", or "yes" when it sets none. Missing capabilities are "no".
+func describeCapability(value any) string {
+	if value == nil {
+		return "no"
+	}
+
+	settings, ok := value.(map[string]any)
+	if !ok || len(settings) == 0 {
+		return "yes"
+	}
+
+	var flags []string
+	for flag, enabled := range settings {
+		if enabled == true {
+			flags = append(flags, flag)
+		}
+	}
+	if len(flags) == 0 {
+		return "yes"
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, ", ")
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFormatServerInfo(t *testing.T) {
+	info := map[string]any{
+		"protocolVersion": "2025-03-26",
+		"serverInfo":      map[string]any{"name": "example", "version": "1.2.3"},
+		"capabilities": map[string]any{
+			"tools":       map[string]any{"listChanged": true},
+			"resources":   map[string]any{"subscribe": true, "listChanged": true},
+			"logging":     map[string]any{},
+			"completions": map[string]any{},
+			"sampling":    map[string]any{},
+		},
+		"instructions": "Use the search tool first.",
+	}
+
+	expected := `Server:            example 1.2.3
+Protocol version:  2025-03-26
+Capabilities:
+  tools        listChanged
+  resources    listChanged, subscribe
+  prompts      no
+  logging      yes
+  completions  yes
+  sampling     yes
+
+Client:
+  name          mcptools 1.0.0
+  capabilities  none
+
+Instructions:
+Use the search tool first.
+`
+	request, err := newInitializeRequest()
+	if err != nil {
+		t.Fatalf("newInitializeRequest() error = %v", err)
+	}
+	if output := formatServerInfo(info, request); output != expected {
+		t.Errorf("formatServerInfo() =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestInfoCmdRun(t *testing.T) {
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "table"
+
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	defer cleanup()
+
+	mockClient, _ := CreateClientFunc(nil)
+	result := &mcp.InitializeResult{ProtocolVersion: "2024-11-05", ServerInfo: mcp.Implementation{Name: "mock", Version: "0.1"}}
+	// The mock transport answers initialize with an empty result, so store the one to show
+	initializeResults.Store(mockClient, ConvertJSONToMap(result))
+
+	cmd := InfoCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	assertContains(t, buf.String(), "Server:            mock 0.1")
+	assertContains(t, buf.String(), "Protocol version:  2024-11-05")
+	assertContains(t, buf.String(), "tools        no")
+}
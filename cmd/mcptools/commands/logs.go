@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// logLevels are the MCP logging levels, from least to most severe.
+var logLevels = []mcp.LoggingLevel{
+	mcp.LoggingLevelDebug,
+	mcp.LoggingLevelInfo,
+	mcp.LoggingLevelNotice,
+	mcp.LoggingLevelWarning,
+	mcp.LoggingLevelError,
+	mcp.LoggingLevelCritical,
+	mcp.LoggingLevelAlert,
+	mcp.LoggingLevelEmergency,
+}
+
+// LogsCmd creates the logs command.
+func LogsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logs [--level level] [command args...]",
+		Short: "Stream the log messages of an MCP server",
+		Long: `Ask an MCP server that supports logging to send log messages at the given level or above,
+and print them to stderr until interrupted. The level is one of debug, info, notice, warning,
+error, critical, alert and emergency, and defaults to info. --level must come before the server;
+use -- to separate the server command.
+
+  mcp logs --level debug -- npx -y @modelcontextprotocol/server-everything`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			level, remainingArgs, err := parseLogsArgs(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			parsedArgs := ProcessFlags(remainingArgs)
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp logs --level debug -- npx -y @modelcontextprotocol/server-everything\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			out := thisCmd.ErrOrStderr()
+			useColors := jsonutils.UseColors(os.Stderr)
+			mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+				printLogMessage(out, notification, useColors)
+			})
+
+			// Replace the handler that exits as soon as the process is interrupted, so that
+			// the client is closed before returning
+			signal.Reset(os.Interrupt, syscall.SIGTERM)
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			request := mcp.SetLevelRequest{}
+			request.Params.Level = level
+			if setErr := mcpClient.SetLevel(ctx, request); setErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to set the log level: %v\n", setErr)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+
+			fmt.Fprintf(out, "Streaming %s and more severe log messages, press Ctrl-C to stop\n", level)
+			<-ctx.Done()
+		},
+	}
+}
+
+// parseLogsArgs takes --level from the arguments of the logs command and returns the level and
+// the remaining arguments. --level comes before the server, whose command may take a flag of
+// the same name, such as bash -l, so parsing stops at -- or the first positional argument.
+func parseLogsArgs(args []string) (mcp.LoggingLevel, []string, error) {
+	level := mcp.LoggingLevelInfo
+	remainingArgs := []string{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case (args[i] == "--level" || args[i] == "-l") && i+1 < len(args):
+			level = mcp.LoggingLevel(strings.ToLower(args[i+1]))
+			if !slices.Contains(logLevels, level) {
+				return "", nil, errors.New("--level must be one of debug, info, notice, warning, error, critical, alert or emergency")
+			}
+			i++
+		case args[i] == "--":
+			// Allow separating the server command with --
+			return level, append(remainingArgs, args[i+1:]...), nil
+		case !strings.HasPrefix(args[i], "-"):
+			return level, append(remainingArgs, args[i:]...), nil
+		default:
+			remainingArgs = append(remainingArgs, args[i])
+		}
+	}
+	return level, remainingArgs, nil
+}
+
+// printLogMessage prints a notifications/message notification to out, ignoring other
+// notifications.
+func printLogMessage(out io.Writer, notification mcp.JSONRPCNotification, useColors bool) {
+	if notification.Method != "notifications/message" {
+		return
+	}
+
+	data, err := json.Marshal(notification.Params)
+	if err != nil {
+		return
+	}
+	var message mcp.LoggingMessageNotification
+	if err := json.Unmarshal(data, &message.Params); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, formatLogMessage(message.Params.Level, message.Params.Logger, message.Params.Data, useColors))
+}
+
+// formatLogMessage formats a log message as "[level] logger: data", coloring the level by
+// its severity. Data that isn't a string is printed as JSON.
+func formatLogMessage(level mcp.LoggingLevel, logger string, data any, useColors bool) string {
+	text, ok := data.(string)
+	if !ok {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(data))
+		}
+		text = string(encoded)
+	}
+	if logger != "" {
+		text = logger + ": " + text
+	}
+
+	label := "[" + string(level) + "]"
+	if useColors {
+		label = logLevelColor(level) + label + jsonutils.ColorReset
+	}
+	return label + " " + text
+}
+
+// logLevelColor returns the color a log level is shown in.
+func logLevelColor(level mcp.LoggingLevel) string {
+	switch level {
+	case mcp.LoggingLevelDebug:
+		return jsonutils.ColorGray
+	case mcp.LoggingLevelInfo, mcp.LoggingLevelNotice:
+		return jsonutils.ColorCyan
+	case mcp.LoggingLevelWarning:
+		return jsonutils.ColorYellow
+	default:
+		return jsonutils.ColorRed
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFormatLogMessage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		level     mcp.LoggingLevel
+		logger    string
+		data      any
+		useColors bool
+		expected  string
+	}{
+		{"string", mcp.LoggingLevelInfo, "", "started", false, "[info] started"},
+		{"logger", mcp.LoggingLevelWarning, "db", "slow query", false, "[warning] db: slow query"},
+		{"object", mcp.LoggingLevelError, "", map[string]any{"code": 5}, false, `[error] {"code":5}`},
+		{"colors", mcp.LoggingLevelCritical, "", "down", true, jsonutils.ColorRed + "[critical]" + jsonutils.ColorReset + " down"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := formatLogMessage(tc.level, tc.logger, tc.data, tc.useColors); got != tc.expected {
+				t.Errorf("formatLogMessage() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPrintLogMessage(t *testing.T) {
+	var out bytes.Buffer
+
+	message := mcp.JSONRPCNotification{JSONRPC: "2.0"}
+	message.Method = "notifications/message"
+	message.Params.AdditionalFields = map[string]any{"level": "debug", "logger": "cache", "data": "miss"}
+	printLogMessage(&out, message, false)
+
+	other := mcp.JSONRPCNotification{JSONRPC: "2.0"}
+	other.Method = "notifications/progress"
+	printLogMessage(&out, other, false)
+
+	if out.String() != "[debug] cache: miss\n" {
+		t.Errorf("output = %q, want only the log message", out.String())
+	}
+}
+
+func TestParseLogsArgs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		level     mcp.LoggingLevel
+		remaining []string
+	}{
+		{"default level", []string{"server"}, mcp.LoggingLevelInfo, []string{"server"}},
+		{"level", []string{"--level", "DEBUG", "-f", "json", "server"}, mcp.LoggingLevelDebug, []string{"-f", "json", "server"}},
+		{"flag after --", []string{"--", "bash", "-l", "server.sh"}, mcp.LoggingLevelInfo, []string{"bash", "-l", "server.sh"}},
+		{"flag after the server", []string{"-l", "error", "bash", "-l", "server.sh"}, mcp.LoggingLevelError, []string{"bash", "-l", "server.sh"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level, remaining, err := parseLogsArgs(tc.args)
+			if err != nil {
+				t.Fatalf("parseLogsArgs() error = %v", err)
+			}
+			if level != tc.level || !reflect.DeepEqual(remaining, tc.remaining) {
+				t.Errorf("parseLogsArgs() = %q, %v, want %q, %v", level, remaining, tc.level, tc.remaining)
+			}
+		})
+	}
+
+	if _, _, err := parseLogsArgs([]string{"--level", "loud", "server"}); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// bridgeRequestIDBase offsets the IDs of forwarded requests so that they never collide with
+// the IDs the client assigns to its own requests.
+const bridgeRequestIDBase = 1 << 40
+
+// bridgeMessage is a JSON-RPC request or notification posted to the MCP endpoint. The ID is
+// kept raw so that it is echoed back exactly as the caller sent it.
+type bridgeMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// bridgeError is the error object of a JSON-RPC response.
+type bridgeError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// bridgeResponse is a JSON-RPC response written by the MCP endpoint.
+type bridgeResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *bridgeError    `json:"error,omitempty"`
+}
+
+// handleMCP serves an MCP-over-HTTP endpoint that forwards each JSON-RPC message posted to it
+// to the server mcpClient is connected to, and writes back the server's response. Requests,
+// including initialize, are passed through unchanged apart from their IDs. The client is
+// dedicated to the endpoint, so that the session state the caller builds up, such as resource
+// subscriptions, stays on one connection instead of being spread over the web client pool.
+func handleMCP(mcpClient *client.Client) http.HandlerFunc {
+	var nextID atomic.Int64
+	nextID.Store(bridgeRequestIDBase)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var message bridgeMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			writeBridgeResponse(w, http.StatusBadRequest, bridgeResponse{
+				Error: &bridgeError{Code: mcp.PARSE_ERROR, Message: "Parse error: " + err.Error()},
+			})
+			return
+		}
+		if message.JSONRPC != mcp.JSONRPC_VERSION || message.Method == "" {
+			writeBridgeResponse(w, http.StatusBadRequest, bridgeResponse{
+				ID:    message.ID,
+				Error: &bridgeError{Code: mcp.INVALID_REQUEST, Message: "Invalid request"},
+			})
+			return
+		}
+
+		// Messages without an ID are notifications, which get no response
+		if len(message.ID) == 0 {
+			notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+			notification.Method = message.Method
+			if len(message.Params) > 0 {
+				if err := json.Unmarshal(message.Params, &notification.Params); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			}
+
+			if err := mcpClient.GetTransport().SendNotification(r.Context(), notification); err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		request := transport.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      nextID.Add(1),
+			Method:  message.Method,
+		}
+		if len(message.Params) > 0 {
+			request.Params = message.Params
+		}
+
+		resp, err := mcpClient.GetTransport().SendRequest(r.Context(), request)
+
+		response := bridgeResponse{ID: message.ID}
+		switch {
+		case err != nil:
+			response.Error = &bridgeError{Code: mcp.INTERNAL_ERROR, Message: err.Error()}
+		case resp.Error != nil:
+			response.Error = &bridgeError{Code: resp.Error.Code, Message: resp.Error.Message, Data: resp.Error.Data}
+		default:
+			response.Result = resp.Result
+		}
+		writeBridgeResponse(w, http.StatusOK, response)
+	}
+}
+
+// writeBridgeResponse writes a JSON-RPC response with the given HTTP status.
+func writeBridgeResponse(w http.ResponseWriter, status int, response bridgeResponse) {
+	response.JSONRPC = mcp.JSONRPC_VERSION
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errcheck,gosec // No need to handle error from Encode in this context
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+)
+
+func TestHandleMCP(t *testing.T) {
+	var methods []string
+	mockClient := client.NewClient(&MockTransport{
+		ExecuteFunc: func(method string, _ any) (map[string]any, error) {
+			methods = append(methods, method)
+			if method == "tools/call" {
+				return nil, errors.New("server went away")
+			}
+			return map[string]any{"tools": []any{}}, nil
+		},
+	})
+	handler := handleMCP(mockClient)
+
+	testCases := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "request keeps the caller's id",
+			method:         http.MethodPost,
+			body:           `{"jsonrpc":"2.0","id":"abc","method":"tools/list"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","id":"abc","result":{"tools":[]}}`,
+		},
+		{
+			name:           "forwarding failure",
+			method:         http.MethodPost,
+			body:           `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"x"}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","id":7,"error":{"code":-32603,"message":"server went away"}}`,
+		},
+		{
+			name:           "notification",
+			method:         http.MethodPost,
+			body:           `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "parse error",
+			method:         http.MethodPost,
+			body:           `{"jsonrpc":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"code":-32700`,
+		},
+		{
+			name:           "invalid request",
+			method:         http.MethodPost,
+			body:           `{"id":1,"method":"tools/list"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"Invalid request"}}`,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler(recorder, httptest.NewRequest(tc.method, "/mcp", strings.NewReader(tc.body)))
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, recorder.Code)
+			}
+			body := strings.TrimSpace(recorder.Body.String())
+			if tc.expectedBody == "" && body != "" {
+				t.Errorf("Expected an empty body, got %s", body)
+			}
+			assertContains(t, body, tc.expectedBody)
+		})
+	}
+
+	if strings.Join(methods, ",") != "tools/list,tools/call" {
+		t.Errorf("Expected tools/list and tools/call to be forwarded, got %v", methods)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/f/mcptools/pkg/logfile"
 	"github.com/f/mcptools/pkg/mock"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +27,10 @@ The mock server implements the MCP protocol with:
 - Standard error codes (-32601 for method not found)
 - Detailed request/response logging to ~/.mcpt/logs/mock.log
 
+Use --debug to also write a line to stderr for every request, or --quiet to limit stderr to
+errors and the line saying the server started. The log is rotated once it reaches
+--log-max-size bytes (0 to never rotate), keeping --log-backups old files.
+
 Available types:
 - tool <name> <description>
 - prompt <name> <description> <template>
@@ -37,7 +42,11 @@ Example:
          prompt welcome "A welcome prompt" "Hello {{name}}, welcome to {{location}}!" \
          resource docs:readme "Documentation" "# Mock MCP Server\nThis is a mock server"`,
 		Args: cobra.MinimumNArgs(2),
-		Run: func(_ *cobra.Command, args []string) {
+		Run: func(cmd *cobra.Command, args []string) {
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			debug, _ := cmd.Flags().GetBool("debug")
+			logMaxSize, _ := cmd.Flags().GetInt64("log-max-size")
+			logBackups, _ := cmd.Flags().GetInt("log-backups")
 			tools := make(map[string]string)
 			prompts := make(map[string]map[string]string)
 			resources := make(map[string]map[string]string)
@@ -58,7 +67,9 @@ Example:
 					toolName := args[i]
 					toolDescription := args[i+1]
 					tools[toolName] = toolDescription
-					fmt.Fprintf(os.Stderr, "Added tool: %s - %s\n", toolName, toolDescription)
+					if !quiet {
+						fmt.Fprintf(os.Stderr, "Added tool: %s - %s\n", toolName, toolDescription)
+					}
 					i += 2
 
 				case EntityTypePrompt:
@@ -77,7 +88,9 @@ Example:
 						"template":    promptTemplate,
 					}
 
-					fmt.Fprintf(os.Stderr, "Added prompt: %s - %s\n", promptName, promptDescription)
+					if !quiet {
+						fmt.Fprintf(os.Stderr, "Added prompt: %s - %s\n", promptName, promptDescription)
+					}
 					i += 3
 
 				case EntityTypeRes:
@@ -96,7 +109,9 @@ Example:
 						"content":     resourceContent,
 					}
 
-					fmt.Fprintf(os.Stderr, "Added resource: %s - %s\n", resourceURI, resourceDescription)
+					if !quiet {
+						fmt.Fprintf(os.Stderr, "Added resource: %s - %s\n", resourceURI, resourceDescription)
+					}
 					i += 3
 
 				default:
@@ -111,16 +126,28 @@ Example:
 				os.Exit(1)
 			}
 
-			fmt.Fprintf(os.Stderr, "Starting mock MCP server with %d tool(s), %d prompt(s), and %d resource(s)\n",
-				len(tools), len(prompts), len(resources))
-			fmt.Fprintf(os.Stderr, "Use Ctrl+C to exit\n")
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Starting mock MCP server with %d tool(s), %d prompt(s), and %d resource(s)\n",
+					len(tools), len(prompts), len(resources))
+				fmt.Fprintf(os.Stderr, "Use Ctrl+C to exit\n")
+			}
 
-			if err := mock.RunMockServer(tools, prompts, resources); err != nil {
+			options := mock.Options{
+				Quiet: quiet,
+				Debug: debug,
+				Log:   logfile.Options{MaxSize: logMaxSize, Backups: logBackups},
+			}
+			if err := mock.RunMockServer(tools, prompts, resources, options); err != nil {
 				fmt.Fprintf(os.Stderr, "Error running mock server: %v\n", err)
 				os.Exit(1)
 			}
 		},
 	}
 
+	cmd.Flags().Bool("quiet", false, "Only write errors and the startup line to stderr")
+	cmd.Flags().Bool("debug", false, "Write a line to stderr for every request and response")
+	cmd.Flags().Int64("log-max-size", logfile.DefaultMaxSize, "Size in bytes at which the log file is rotated (0 to never rotate)")
+	cmd.Flags().Int("log-backups", logfile.DefaultBackups, "Number of rotated log files to keep")
+
 	return cmd
 }
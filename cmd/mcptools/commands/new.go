@@ -18,6 +18,13 @@ const (
 	transportHTTP  = "http"
 )
 
+// defaultComponent is scaffolded when no component is given, so that the generated server
+// has an example tool.
+const defaultComponent = "tool:example"
+
+// componentMarker marks where component initializations are inserted in a server template.
+const componentMarker = "// COMPONENT_INITIALIZATION"
+
 // NewCmd returns a new 'new' command for scaffolding MCP projects.
 func NewCmd() *cobra.Command {
 	var sdkFlag string
@@ -28,14 +35,18 @@ func NewCmd() *cobra.Command {
 		Short: "Create a new MCP project component",
 		Long: `Create a new MCP component (tool, resource, or prompt) from a template.
 
+The project's server uses the transport chosen with --transport: stdio (the default), sse, or
+http for streamable HTTP. Without components, the server is generated with an example tool.
+
 Examples:
+  mcp new --transport=sse
   mcp new tool:hello_world resource:file prompt:hello
   mcp new tool:hello_world --sdk=ts
   mcp new tool:hello_world --transport=stdio|sse|http`,
 		SilenceUsage: true,
 		RunE: func(_ *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				return fmt.Errorf("at least one component must be specified (e.g., tool:hello_world)")
+				args = []string{defaultComponent}
 			}
 
 			// Validate SDK flag
@@ -235,13 +246,17 @@ func appendImport(indexFile, componentName string) error {
 	importStatement := fmt.Sprintf("import %s from \"./%s.js\";\n", componentName, componentName)
 	updatedContent := fileContent[:lastImportIndex] + importStatement + fileContent[lastImportIndex:]
 
-	// Find the position to insert component initialization
-	transportPattern := regexp.MustCompile(`(?m)^const\s+transport\s*=`)
-	match := transportPattern.FindStringIndex(updatedContent)
+	// Find the position to insert component initialization: the marker of the template, or
+	// else the transport line
+	match := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(componentMarker) + `$`).FindStringIndex(updatedContent)
+	if match == nil {
+		transportPattern := regexp.MustCompile(`(?m)^const\s+transport\s*=`)
+		match = transportPattern.FindStringIndex(updatedContent)
+	}
 
 	var finalContent string
 	if match != nil {
-		// Insert component initialization before the transport line
+		// Insert component initialization before the marker or the transport line
 		componentInit := fmt.Sprintf("// Initialize the %s component\n%s(server);\n\n", componentName, componentName)
 		finalContent = updatedContent[:match[0]] + componentInit + updatedContent[match[0]:]
 	} else {
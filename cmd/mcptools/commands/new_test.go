@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewCmdTransports(t *testing.T) {
+	templatesPath, err := filepath.Abs(filepath.Join("..", "..", "..", "templates"))
+	if err != nil {
+		t.Fatalf("filepath.Abs() error = %v", err)
+	}
+	origTemplatesPath := TemplatesPath
+	defer func() { TemplatesPath = origTemplatesPath }()
+	TemplatesPath = templatesPath
+	t.Setenv("HOME", t.TempDir())
+
+	testCases := []struct {
+		transport string
+		args      []string
+		tool      string
+		expected  string
+	}{
+		{transport: transportStdio, args: []string{"tool:hello"}, tool: "hello", expected: "StdioServerTransport"},
+		{transport: transportSSE, args: nil, tool: "example", expected: "transport.handlePostMessage"},
+		{transport: transportHTTP, args: []string{"tool:hello"}, tool: "hello", expected: "StreamableHTTPServerTransport"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.transport, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+
+			cmd := NewCmd()
+			cmd.SetArgs(append(tc.args, "--transport="+tc.transport))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("cmd.Execute() error = %v", err)
+			}
+
+			index, err := os.ReadFile(filepath.Join("src", "index.ts"))
+			if err != nil {
+				t.Fatalf("reading index.ts: %v", err)
+			}
+			assertContains(t, string(index), tc.expected)
+			assertContains(t, string(index), `import `+tc.tool+` from "./`+tc.tool+`.js";`)
+
+			// Components are initialized before the server starts listening
+			initIndex := strings.Index(string(index), tc.tool+"(server);")
+			if initIndex < 0 || initIndex > strings.Index(string(index), "const transport") {
+				t.Errorf("expected %s to be initialized before the transport:\n%s", tc.tool, index)
+			}
+
+			tool, err := os.ReadFile(filepath.Join("src", tc.tool+".ts"))
+			if err != nil {
+				t.Fatalf("reading %s.ts: %v", tc.tool, err)
+			}
+			assertContains(t, string(tool), `"`+tc.tool+`"`)
+			assertContains(t, string(tool), `z.number().describe(`)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/f/mcptools/pkg/openapi"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// OpenAPICmd creates the openapi command.
+func OpenAPICmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "openapi [--title title] [--server-url url] [--path-prefix prefix] [command args...]",
+		Short: "Generate an OpenAPI document from the tools of an MCP server",
+		Long: `List the tools of an MCP server and print an OpenAPI 3.1 document that describes them as an
+HTTP API. Each tool becomes a POST operation at /tools/<name>, or under --path-prefix, whose JSON
+request body is the tool's input schema and whose response is a tool call result. The title and
+version of the API are the server's name and version unless --title is given, and --server-url
+lists the URL the API is served at. Use -- to separate the server command.
+
+  mcp openapi -- npx -y @modelcontextprotocol/server-filesystem ~ > openapi.json
+  mcp openapi --server-url http://localhost:8080 -- npx -y @modelcontextprotocol/server-everything`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			options := openapi.Options{}
+			remainingArgs := []string{}
+			for i := 0; i < len(args); i++ {
+				switch {
+				case args[i] == "--title" && i+1 < len(args):
+					options.Title = args[i+1]
+					i++
+				case args[i] == "--server-url" && i+1 < len(args):
+					options.ServerURL = args[i+1]
+					i++
+				case args[i] == "--path-prefix" && i+1 < len(args):
+					options.PathPrefix = args[i+1]
+					i++
+				case args[i] == "--" && len(remainingArgs) == 0:
+					// Allow separating the server command with --
+				default:
+					remainingArgs = append(remainingArgs, args[i])
+				}
+			}
+
+			parsedArgs := ProcessFlags(remainingArgs)
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp openapi -- npx -y @modelcontextprotocol/server-filesystem ~\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			document, err := generateOpenAPI(context.Background(), mcpClient, options)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+
+			data, err := json.MarshalIndent(document, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+			fmt.Fprintln(thisCmd.OutOrStdout(), string(data))
+		},
+	}
+}
+
+// generateOpenAPI lists every tool of the server and describes them as an OpenAPI document,
+// titled after the server unless options has a title.
+func generateOpenAPI(ctx context.Context, mcpClient *client.Client, options openapi.Options) (map[string]any, error) {
+	resp, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	info, _ := initializeResult(mcpClient)
+	serverInfo, _ := info["serverInfo"].(map[string]any)
+	if options.Title == "" {
+		options.Title, _ = serverInfo["name"].(string)
+	}
+	if options.Version == "" {
+		options.Version, _ = serverInfo["version"].(string)
+	}
+
+	return openapi.Generate(resp.Tools, options)
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPICmdRun(t *testing.T) {
+	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		if method != "tools/list" {
+			t.Errorf("Expected method 'tools/list', got %q", method)
+		}
+		return map[string]any{
+			"tools": []any{
+				map[string]any{
+					"name":        "search",
+					"description": "Search things",
+					"inputSchema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"query": map[string]any{"type": "string"}},
+						"required":   []any{"query"},
+					},
+				},
+			},
+		}, nil
+	})
+	defer cleanup()
+
+	cmd := OpenAPICmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--title", "Search API", "--path-prefix", "/api", "--", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	var document struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("expected a JSON document, got %q: %v", buf.String(), err)
+	}
+	if document.OpenAPI != "3.1.0" || document.Info.Title != "Search API" {
+		t.Errorf("unexpected document: %s", buf.String())
+	}
+	if _, ok := document.Paths["/api/search"]; !ok {
+		t.Errorf("expected a /api/search path, got %v", document.Paths)
+	}
+}
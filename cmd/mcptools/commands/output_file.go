@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/spf13/cobra"
+)
+
+// writeOutputFile writes a result to path, as JSON or, with decodeBase64, as the decoded
+// bytes of its only base64 content item. It returns the number of bytes written.
+func writeOutputFile(path string, result any, decodeBase64 bool) (int, error) {
+	var data []byte
+	if decodeBase64 {
+		resp, _ := result.(map[string]any)
+		encoded, err := base64Content(resp)
+		if err != nil {
+			return 0, err
+		}
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return 0, fmt.Errorf("content is not valid base64: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = json.Marshal(result); err != nil {
+			return 0, fmt.Errorf("error encoding result: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // Output file chosen by the user
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// printOutputFile writes a result to path and prints a summary of what was written instead of
+// the result itself.
+func printOutputFile(thisCmd *cobra.Command, path string, result any, decodeBase64 bool) {
+	written, err := writeOutputFile(path, result, decodeBase64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(thisCmd.OutOrStdout(), "Wrote %d bytes to %s\n", written, path)
+}
+
+// base64Content returns the base64 data of the only binary content item of a result: an image
+// or audio item or embedded resource blob of a tool result, or a blob of a resource.
+func base64Content(resp map[string]any) (string, error) {
+	var blobs []string
+
+	content, _ := resp["content"].([]any)
+	for _, item := range content {
+		contentItem, _ := item.(map[string]any)
+		if data, ok := contentItem["data"].(string); ok {
+			blobs = append(blobs, data)
+		}
+		resource, _ := contentItem["resource"].(map[string]any)
+		if blob, ok := resource["blob"].(string); ok {
+			blobs = append(blobs, blob)
+		}
+	}
+
+	contents, _ := resp["contents"].([]any)
+	for _, item := range contents {
+		resource, _ := item.(map[string]any)
+		if blob, ok := resource["blob"].(string); ok {
+			blobs = append(blobs, blob)
+		}
+	}
+
+	if len(blobs) != 1 {
+		return "", fmt.Errorf("%s needs exactly one base64 content item, found %d", FlagDecodeBase64, len(blobs))
+	}
+	return blobs[0], nil
+}
+
+// diffResultFile compares the JSON result saved in path, for example with --output-file, to
+// result and returns their differences.
+func diffResultFile(path string, result any) ([]jsonutils.Difference, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // File chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	var saved any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+
+	// Round-trip the result so both sides hold the same types
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding result: %w", err)
+	}
+	var current any
+	if err := json.Unmarshal(encoded, &current); err != nil {
+		return nil, fmt.Errorf("error encoding result: %w", err)
+	}
+
+	return jsonutils.Diff(saved, current), nil
+}
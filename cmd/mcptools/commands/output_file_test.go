@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/f/mcptools/pkg/jsonutils"
+)
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name         string
+		result       map[string]any
+		decodeBase64 bool
+		expected     string
+		wantErr      bool
+	}{
+		{
+			name:     "raw result as JSON",
+			result:   map[string]any{"content": []any{map[string]any{"type": "text", "text": "hi"}}},
+			expected: `{"content":[{"text":"hi","type":"text"}]}`,
+		},
+		{
+			name:         "image content",
+			result:       map[string]any{"content": []any{map[string]any{"type": "image", "data": "AAEC/w=="}}},
+			decodeBase64: true,
+			expected:     "\x00\x01\x02\xff",
+		},
+		{
+			name: "embedded resource blob",
+			result: map[string]any{"content": []any{
+				map[string]any{"type": "text", "text": "here it is"},
+				map[string]any{"type": "resource", "resource": map[string]any{"uri": "x://a", "blob": "aGk="}},
+			}},
+			decodeBase64: true,
+			expected:     "hi",
+		},
+		{
+			name:         "resource contents blob",
+			result:       map[string]any{"contents": []any{map[string]any{"uri": "x://a", "blob": "aGk="}}},
+			decodeBase64: true,
+			expected:     "hi",
+		},
+		{
+			name:         "no binary content",
+			result:       map[string]any{"content": []any{map[string]any{"type": "text", "text": "hi"}}},
+			decodeBase64: true,
+			wantErr:      true,
+		},
+		{
+			name: "several binary items",
+			result: map[string]any{"content": []any{
+				map[string]any{"type": "image", "data": "aGk="},
+				map[string]any{"type": "audio", "data": "aGk="},
+			}},
+			decodeBase64: true,
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			written, err := writeOutputFile(path, tc.result, tc.decodeBase64)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("writeOutputFile() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeOutputFile() error = %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != tc.expected || written != len(tc.expected) {
+				t.Errorf("wrote %d bytes %q, want %q", written, data, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffResultFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "before.json")
+	before := map[string]any{"content": []any{map[string]any{"type": "text", "text": "3 files"}}, "count": 3}
+	if _, err := writeOutputFile(path, before, false); err != nil {
+		t.Fatal(err)
+	}
+
+	after := map[string]any{"content": []any{map[string]any{"type": "text", "text": "4 files"}}, "count": 3}
+	diffs, err := diffResultFile(path, after)
+	if err != nil {
+		t.Fatalf("diffResultFile() error = %v", err)
+	}
+	want := []jsonutils.Difference{{Path: "content.0.text", Kind: jsonutils.DiffChanged, Old: "3 files", New: "4 files"}}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("diffResultFile() = %+v, want %+v", diffs, want)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := diffResultFile(path, after); err == nil {
+		t.Error("diffResultFile() returned no error for a file that isn't JSON")
+	}
+}
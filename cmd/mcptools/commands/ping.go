@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// pingTimeout is how long a single ping waits for the server to answer.
+const pingTimeout = 10 * time.Second
+
+// PingCmd creates the ping command.
+func PingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ping [--count N] [--interval duration] [command args...]",
+		Short: "Check that an MCP server responds and measure its round-trip time",
+		Long: `Connect to an MCP server and send it ping requests, printing the round-trip time of each.
+Servers that don't support ping are sent an initialize request instead. The command exits with
+a non-zero status if the server can't be reached or any ping fails. --count and --interval must
+come before the server, so that they aren't taken from its command.`,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			count := 1
+			interval := time.Second
+			remainingArgs := []string{}
+
+			// ping's flags come before the server, whose command may take flags of the same
+			// name, such as docker run -i
+			for i := 0; i < len(args); i++ {
+				switch {
+				case args[i] == "--count" && i+1 < len(args):
+					value, err := strconv.Atoi(args[i+1])
+					if err != nil || value < 1 {
+						fmt.Fprintf(os.Stderr, "Error: --count must be a positive integer\n")
+						os.Exit(1)
+					}
+					count = value
+					i++
+				case args[i] == "--interval" && i+1 < len(args):
+					value, err := time.ParseDuration(args[i+1])
+					if err != nil || value < 0 {
+						fmt.Fprintf(os.Stderr, "Error: --interval must be a duration such as 1s or 500ms\n")
+						os.Exit(1)
+					}
+					interval = value
+					i++
+				case args[i] == "--":
+					// Allow separating the server command with --
+					remainingArgs = append(remainingArgs, args[i+1:]...)
+					i = len(args)
+				case !strings.HasPrefix(args[i], "-"):
+					remainingArgs = append(remainingArgs, args[i:]...)
+					i = len(args)
+				default:
+					remainingArgs = append(remainingArgs, args[i])
+				}
+			}
+
+			parsedArgs := ProcessFlags(remainingArgs)
+			server := strings.Join(parsedArgs, " ")
+
+			connectStart := time.Now()
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp ping npx -y @modelcontextprotocol/server-filesystem ~\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			out := thisCmd.OutOrStdout()
+			fmt.Fprintf(out, "Connected to %s in %v\n", server, roundLatency(time.Since(connectStart)))
+
+			var latencies []time.Duration
+			failures := 0
+			for i := 1; i <= count; i++ {
+				if i > 1 {
+					time.Sleep(interval)
+				}
+
+				latency, method, pingErr := pingServer(mcpClient)
+				if pingErr != nil {
+					failures++
+					fmt.Fprintf(out, "%s seq=%d failed: %v\n", method, i, pingErr)
+					continue
+				}
+				latencies = append(latencies, latency)
+				fmt.Fprintf(out, "%s seq=%d time=%v\n", method, i, roundLatency(latency))
+			}
+
+			stats := summarizeLatencies(latencies)
+			fmt.Fprintf(out, "\n%d requests, %d succeeded, %d failed", count, len(latencies), failures)
+			if len(latencies) > 0 {
+				fmt.Fprintf(out, ", min/avg/max = %v/%v/%v", roundLatency(stats.Min), roundLatency(stats.Mean), roundLatency(stats.Max))
+			}
+			fmt.Fprintln(out)
+
+			if failures > 0 {
+				CloseWithTimeout(mcpClient)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// pingServer sends a ping request and returns its round-trip time. Servers that don't
+// implement ping are sent an initialize request instead. It also returns the method used.
+func pingServer(mcpClient *client.Client) (time.Duration, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rpcCtx := mcpclient.WithRPCError(ctx)
+	err := mcpclient.WrapRPCError(rpcCtx, mcpClient.Ping(rpcCtx))
+	if err == nil {
+		return time.Since(start), "ping", nil
+	}
+	var rpcErr *mcpclient.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.METHOD_NOT_FOUND {
+		return 0, "ping", err
+	}
+
+	start = time.Now()
+	initRequest, err := newInitializeRequest()
+	if err != nil {
+		return 0, "initialize", err
+	}
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		return 0, "initialize", err
+	}
+	return time.Since(start), "initialize", nil
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPingCmd(t *testing.T) {
+	var methods []string
+	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		methods = append(methods, method)
+		return map[string]any{}, nil
+	})
+	defer cleanup()
+
+	cmd := PingCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--count", "2", "--interval", "0s", "--", "server", "arg"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	if len(methods) != 2 || methods[0] != "ping" || methods[1] != "ping" {
+		t.Errorf("methods = %v, want two pings", methods)
+	}
+
+	output := buf.String()
+	assertContains(t, output, "Connected to server arg")
+	assertContains(t, output, "ping seq=1 time=")
+	assertContains(t, output, "ping seq=2 time=")
+	assertContains(t, output, "2 requests, 2 succeeded, 0 failed")
+}
+
+func TestPingCmdServerFlags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "after --",
+			args:     []string{"--", "sh", "-c", "exit 0"},
+			expected: "Connected to sh -c exit 0",
+		},
+		{
+			name:     "after the server",
+			args:     []string{"--count", "1", "docker", "run", "-i", "--rm", "mcp/time"},
+			expected: "Connected to docker run -i --rm mcp/time",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+				return map[string]any{}, nil
+			})
+			defer cleanup()
+
+			cmd := PingCmd()
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("cmd.Execute() error = %v", err)
+			}
+			assertContains(t, buf.String(), tc.expected)
+		})
+	}
+}
+
+func TestPingServerFailsOnOtherErrors(t *testing.T) {
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return nil, errors.New("method not found in the transport")
+	})
+	defer cleanup()
+
+	mcpClient, err := CreateClientFunc(nil)
+	if err != nil {
+		t.Fatalf("CreateClientFunc() error = %v", err)
+	}
+
+	if _, method, err := pingServer(mcpClient); err == nil || method != "ping" {
+		t.Errorf("pingServer() = %q, %v, want the ping error", method, err)
+	}
+}
+
+func TestPingServerFallsBackToInitialize(t *testing.T) {
+	// The server answers ping with a JSON-RPC method not found error
+	mcpClient := client.NewClient(mcpclient.NewRecording(&rpcErrorTransport{}))
+	_, _ = mcpClient.Initialize(context.Background(), mcp.InitializeRequest{})
+
+	_, method, err := pingServer(mcpClient)
+	if err != nil {
+		t.Fatalf("pingServer() error = %v", err)
+	}
+	if method != "initialize" {
+		t.Errorf("method = %q, want initialize", method)
+	}
+}
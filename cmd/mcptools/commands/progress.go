@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/term"
+)
+
+// callProgressToken is attached to tool calls so the server can report progress on them.
+const callProgressToken = "mcptools-call"
+
+// progressPrinter prints the progress notifications of a request as a single line that is
+// updated in place on a terminal, or as one line per update otherwise.
+type progressPrinter struct {
+	out      io.Writer
+	token    mcp.ProgressToken
+	inPlace  bool
+	mu       sync.Mutex
+	printed  bool
+	finished bool
+}
+
+// watchProgress prints the progress notifications the server sends for token to stderr.
+// The returned function ends the progress line once the request completes.
+func watchProgress(mcpClient *client.Client, token mcp.ProgressToken) func() {
+	printer := &progressPrinter{
+		out:     os.Stderr,
+		token:   token,
+		inPlace: term.IsTerminal(int(os.Stderr.Fd())),
+	}
+	mcpClient.OnNotification(printer.handle)
+	return printer.finish
+}
+
+// handle prints a notification if it reports progress for the watched token.
+func (p *progressPrinter) handle(notification mcp.JSONRPCNotification) {
+	if notification.Method != "notifications/progress" {
+		return
+	}
+
+	data, err := json.Marshal(notification.Params)
+	if err != nil {
+		return
+	}
+	var progress mcp.ProgressNotification
+	if err := json.Unmarshal(data, &progress.Params); err != nil {
+		return
+	}
+	if fmt.Sprint(progress.Params.ProgressToken) != fmt.Sprint(p.token) {
+		return
+	}
+
+	line := formatProgress(progress.Params.Progress, progress.Params.Total, progress.Params.Message)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	if p.inPlace {
+		fmt.Fprintf(p.out, "\r\033[K%s", line)
+	} else {
+		fmt.Fprintln(p.out, line)
+	}
+	p.printed = true
+}
+
+// finish ends the in-place progress line, if one was printed.
+func (p *progressPrinter) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inPlace && p.printed && !p.finished {
+		fmt.Fprintln(p.out)
+	}
+	p.finished = true
+}
+
+// formatProgress formats a progress update, as a percentage when the total is known.
+func formatProgress(progress, total float64, message string) string {
+	var b strings.Builder
+	b.WriteString("Progress: ")
+	if total > 0 {
+		fmt.Fprintf(&b, "%.0f%% (%g/%g)", progress/total*100, progress, total)
+	} else {
+		fmt.Fprintf(&b, "%g", progress)
+	}
+	if message != "" {
+		b.WriteString(" ")
+		b.WriteString(message)
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFormatProgress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		progress float64
+		total    float64
+		message  string
+		expected string
+	}{
+		{name: "with total", progress: 25, total: 100, expected: "Progress: 25% (25/100)"},
+		{name: "with total and message", progress: 1, total: 4, message: "indexing", expected: "Progress: 25% (1/4) indexing"},
+		{name: "without total", progress: 3, expected: "Progress: 3"},
+		{name: "without total with message", progress: 0.5, message: "working", expected: "Progress: 0.5 working"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := formatProgress(tc.progress, tc.total, tc.message); got != tc.expected {
+				t.Errorf("formatProgress() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestProgressPrinterFiltersToken(t *testing.T) {
+	var out bytes.Buffer
+	printer := &progressPrinter{out: &out, token: callProgressToken}
+
+	notification := func(token any, progress float64) mcp.JSONRPCNotification {
+		n := mcp.JSONRPCNotification{JSONRPC: "2.0"}
+		n.Method = "notifications/progress"
+		n.Params.AdditionalFields = map[string]any{"progressToken": token, "progress": progress, "total": 2}
+		return n
+	}
+
+	printer.handle(notification("other-request", 1))
+	printer.handle(notification(callProgressToken, 1))
+	printer.finish()
+	printer.handle(notification(callProgressToken, 2))
+
+	if got, expected := out.String(), "Progress: 50% (1/2)\n"; got != expected {
+		t.Errorf("output = %q, want %q", got, expected)
+	}
+}
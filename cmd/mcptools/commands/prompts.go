@@ -32,15 +32,22 @@ func PromptsCmd() *cobra.Command {
 		}
 		defer CloseWithTimeout(mcpClient)
 
-			resp, listErr := mcpClient.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+			// The client follows the server's pagination cursors to list every prompt
+			listPrompts := mcpClient.ListPrompts
+			if FirstPageOnly {
+				listPrompts = mcpClient.ListPromptsByPage
+			}
+			resp, listErr := listPrompts(context.Background(), mcp.ListPromptsRequest{})
+			if listErr == nil && resp != nil && FirstPageOnly {
+				warnMorePages("prompts", resp.NextCursor)
+			}
 
 			var prompts []any
 			if listErr == nil && resp != nil {
-				prompts = ConvertJSONToSlice(resp.Prompts)
+				prompts = sortEntities(filterEntities(ConvertJSONToSlice(resp.Prompts)))
 			}
 
-			promptsMap := map[string]any{"prompts": prompts}
-			if formatErr := FormatAndPrintResponse(thisCmd, promptsMap, listErr); formatErr != nil {
+			if formatErr := printEntityList(thisCmd, "prompts", "prompt", prompts, listErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/f/mcptools/pkg/logfile"
 	"github.com/f/mcptools/pkg/proxy"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,7 +23,8 @@ func ProxyCmd() *cobra.Command {
 		Long: `Proxy MCP tool requests to shell scripts.
 
 This command allows you to register shell scripts as MCP tools and proxy MCP requests to them.
-The scripts will receive tool parameters as environment variables.
+The scripts will receive tool parameters as environment variables, and the full arguments
+object as JSON on stdin.
 
 Examples:
   # Register a shell script as an MCP tool
@@ -51,7 +55,9 @@ Parameters should be specified in the format "name:type,name:type,..." where typ
 - float: Floating-point numbers
 - bool: Boolean values (true/false)
 
-The script or command will receive parameters as environment variables.
+The script or command will receive parameters as environment variables. The complete
+arguments object is also written as JSON to its stdin, which is the only reliable way to
+receive nested objects and arrays (e.g. read it with: jq -r .name).
 
 You can either provide a script file path or use the -e flag to specify an inline command.
 Example with script:
@@ -60,6 +66,26 @@ Example with script:
 Example with inline command:
   mcp proxy tool add_op "Adds given numbers" "a:int,b:int" -e "echo \"total is $a + $b = ${$a+$b}\""
 
+To describe parameters with a full JSON schema (enums, per-parameter descriptions, array items),
+register the tool from a schema file with --tool name=schema.json. The flag can be repeated to
+register several tools backed by the same command; the MCP_TOOL_NAME environment variable tells
+the command which tool was called. A top-level "description" in the schema is used as the tool
+description:
+  mcp proxy tool --tool search=search.schema.json --command './search.sh'
+
+Scripts and commands are killed when they run longer than --timeout, which is a duration such as
+90s or a number of seconds, and defaults to 60s. Use --timeout 0 to let a tool run for as long as
+it needs:
+  mcp proxy tool build "Builds the project" "" ./build.sh --timeout 10m
+
+Scripts that print JSON can be registered with --output json. Their output is parsed and returned
+as structured content, and the call fails if it isn't valid JSON:
+  mcp proxy tool stats "Repository statistics" "" -e 'jq -n "{files: 3}"' --output json
+
+Use --cwd to run the script or command in a given directory, which also resolves a relative
+script path. A leading ~ is expanded to the home directory:
+  mcp proxy tool test "Runs the tests" "" ./test.sh --cwd ~/src/project
+
 To unregister a tool, use the --unregister flag:
   mcp proxy tool --unregister tool_name`,
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -70,6 +96,10 @@ To unregister a tool, use the --unregister flag:
 				}
 				return nil
 			}
+			schemaTools, _ := cmd.Flags().GetStringArray("tool")
+			if len(schemaTools) > 0 {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
 			return cobra.RangeArgs(3, 4)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -99,6 +129,35 @@ To unregister a tool, use the --unregister flag:
 				return nil
 			}
 
+			// Get the inline command from the -e or --command flag
+			command, _ := cmd.Flags().GetString("execute")
+			if command == "" {
+				command, _ = cmd.Flags().GetString("command")
+			}
+
+			timeout, _ := cmd.Flags().GetString("timeout")
+			if timeout != "" {
+				if _, timeoutErr := proxy.ParseToolTimeout(timeout); timeoutErr != nil {
+					return timeoutErr
+				}
+			}
+
+			output, _ := cmd.Flags().GetString("output")
+			if output != "" && output != proxy.OutputText && output != proxy.OutputJSON {
+				return fmt.Errorf("invalid --output %q, expected %s or %s", output, proxy.OutputText, proxy.OutputJSON)
+			}
+			cwd, _ := cmd.Flags().GetString("cwd")
+			settings := map[string]string{"timeout": timeout, "output": output, "cwd": cwd}
+
+			schemaTools, _ := cmd.Flags().GetStringArray("tool")
+			if len(schemaTools) > 0 {
+				scriptPath := ""
+				if len(args) > 0 {
+					scriptPath = args[0]
+				}
+				return registerSchemaTools(schemaTools, scriptPath, command, settings)
+			}
+
 			name := args[0]
 			description := args[1]
 			parameters := args[2]
@@ -107,9 +166,6 @@ To unregister a tool, use the --unregister flag:
 				scriptPath = args[3]
 			}
 
-			// Get the inline command from the -e flag
-			command, _ := cmd.Flags().GetString("execute")
-
 			// Either script path or command must be provided
 			if scriptPath == "" && command == "" {
 				return fmt.Errorf("either script path or command (-e) must be provided")
@@ -128,6 +184,7 @@ To unregister a tool, use the --unregister flag:
 				"script":      scriptPath,
 				"command":     command,
 			}
+			addToolSettings(config[name], settings)
 
 			// Save updated config
 			if saveErr := SaveProxyConfig(config); saveErr != nil {
@@ -140,10 +197,83 @@ To unregister a tool, use the --unregister flag:
 	}
 
 	cmd.Flags().StringP("execute", "e", "", "Inline command to execute instead of a script file")
+	cmd.Flags().String("command", "", "Inline command to execute (same as -e)")
+	cmd.Flags().StringArray("tool", nil, "Register a tool from a JSON schema file as name=schema.json (repeatable)")
+	cmd.Flags().String("timeout", "", "Kill the script or command after this long, e.g. 90s (default 60s, 0 for no limit)")
+	cmd.Flags().String("output", "", "How to return the output: text (default) or json for structured content")
+	cmd.Flags().String("cwd", "", "Directory to run the script or command in (default the directory of proxy start)")
 	cmd.Flags().Bool("unregister", false, "Unregister a tool")
 	return cmd
 }
 
+// addToolSettings adds the optional settings of a tool, such as its timeout, to its config,
+// skipping those that weren't given.
+func addToolSettings(toolConfig, settings map[string]string) {
+	for key, value := range settings {
+		if value != "" {
+			toolConfig[key] = value
+		}
+	}
+}
+
+// registerSchemaTools registers tools described by JSON schema files given as name=schema.json.
+// settings holds optional settings such as the timeout, which are applied to every tool.
+func registerSchemaTools(specs []string, scriptPath, command string, settings map[string]string) error {
+	// Either script path or command must be provided
+	if scriptPath == "" && command == "" {
+		return fmt.Errorf("either script path or command (--command) must be provided")
+	}
+
+	config, loadErr := LoadProxyConfig()
+	if loadErr != nil {
+		return fmt.Errorf("error loading config: %w", loadErr)
+	}
+
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		name, schemaPath, found := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || schemaPath == "" {
+			return fmt.Errorf("invalid --tool value %q, expected name=schema.json", spec)
+		}
+
+		schemaData, err := os.ReadFile(filepath.Clean(schemaPath))
+		if err != nil {
+			return fmt.Errorf("error reading schema for tool %s: %w", name, err)
+		}
+
+		var schema map[string]interface{}
+		if err := json.Unmarshal(schemaData, &schema); err != nil {
+			return fmt.Errorf("error parsing schema for tool %s: %w", name, err)
+		}
+
+		// Store the schema inline so the config doesn't depend on the file staying around
+		compactSchema, err := json.Marshal(schema)
+		if err != nil {
+			return fmt.Errorf("error encoding schema for tool %s: %w", name, err)
+		}
+
+		description, _ := schema["description"].(string)
+		config[name] = map[string]string{
+			"description": description,
+			"schema":      string(compactSchema),
+			"script":      scriptPath,
+			"command":     command,
+		}
+		addToolSettings(config[name], settings)
+		names = append(names, name)
+	}
+
+	if saveErr := SaveProxyConfig(config); saveErr != nil {
+		return fmt.Errorf("error saving config: %w", saveErr)
+	}
+
+	for _, name := range names {
+		fmt.Printf("Registered tool: %s\n", name)
+	}
+	return nil
+}
+
 // ProxyStartCmd creates the proxy start command.
 func ProxyStartCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -153,9 +283,42 @@ func ProxyStartCmd() *cobra.Command {
 
 The server reads tool configurations from $HOME/.mcpt/proxy_config.json.
 
+Script output is returned in full unless --max-output-size is given, in which case only that
+many bytes are kept; anything beyond the limit is dropped and replaced by a truncation marker.
+
+With --dry-run, tool calls don't run anything. They return the command that would have run,
+with the tool's variables expanded, along with its environment variables and stdin, which
+helps to debug tool definitions. Dry runs are also recorded in the proxy log.
+
+Arguments are exported to tools as MCP_ARG_<name> variables, with characters other than letters,
+digits and underscores in the name replaced by underscores. They are also exported under the
+parameter name itself when it is a valid variable name that doesn't start with MCP_ and isn't
+already set, such as PATH or HOME. Use --prefixed-env to only export the MCP_ARG_ variables.
+
+Tools run in the directory set with proxy tool --cwd, or else in --working-dir, or else in
+the current directory. Relative script paths are resolved against that directory.
+
+Requests are logged to ~/.mcpt/logs/proxy.log. Use --debug to also write a line to stderr for
+every request, or --quiet to limit stderr to errors and the line saying the server started.
+The log is rotated once it reaches --log-max-size bytes (0 to never rotate), keeping
+--log-backups old files as proxy.log.1 to proxy.log.N.
+
 Example:
-  mcp proxy start`,
-		Run: func(_ *cobra.Command, _ []string) {
+  mcp proxy start
+  mcp proxy start --max-output-size 1048576
+  mcp proxy start --dry-run
+  mcp proxy start --working-dir ~/src/project
+  mcp proxy start --quiet`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			maxOutputSize, _ := cmd.Flags().GetInt64("max-output-size")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			workingDir, _ := cmd.Flags().GetString("working-dir")
+			prefixedEnv, _ := cmd.Flags().GetBool("prefixed-env")
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			debug, _ := cmd.Flags().GetBool("debug")
+			logMaxSize, _ := cmd.Flags().GetInt64("log-max-size")
+			logBackups, _ := cmd.Flags().GetInt("log-backups")
+
 			// Load tool configurations
 			viper.SetConfigName("proxy_config")
 			viper.SetConfigType("json")
@@ -171,13 +334,33 @@ Example:
 			}
 
 			// Run proxy server
-			fmt.Fprintln(os.Stderr, "Starting proxy server...")
-			if err := proxy.RunProxyServer(config); err != nil {
+			if !quiet {
+				fmt.Fprintln(os.Stderr, "Starting proxy server...")
+			}
+			options := proxy.Options{
+				MaxOutputSize:   maxOutputSize,
+				DryRun:          dryRun,
+				WorkingDir:      workingDir,
+				PrefixedEnvOnly: prefixedEnv,
+				Quiet:           quiet,
+				Debug:           debug,
+				Log:             logfile.Options{MaxSize: logMaxSize, Backups: logBackups},
+			}
+			if err := proxy.RunProxyServer(config, options); err != nil {
 				log.Fatalf("Error running proxy server: %v", err)
 			}
 		},
 	}
 
+	cmd.Flags().Int64("max-output-size", 0, "Maximum bytes of script output to return (0 for no limit)")
+	cmd.Flags().Bool("dry-run", false, "Return the command each tool call would run instead of running it")
+	cmd.Flags().Bool("prefixed-env", false, "Only export arguments as MCP_ARG_<name> environment variables")
+	cmd.Flags().String("working-dir", "", "Directory to run tools in when they don't set their own --cwd")
+	cmd.Flags().Bool("quiet", false, "Only write errors and the startup line to stderr")
+	cmd.Flags().Bool("debug", false, "Write a line to stderr for every request and response")
+	cmd.Flags().Int64("log-max-size", logfile.DefaultMaxSize, "Size in bytes at which the log file is rotated (0 to never rotate)")
+	cmd.Flags().Int("log-backups", logfile.DefaultBackups, "Number of rotated log files to keep")
+
 	return cmd
 }
 
@@ -2,18 +2,30 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/itchyny/gojq"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
 )
 
 // ReadResourceCmd creates the read-resource command.
 func ReadResourceCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:                "read-resource resource [command args...]",
-		Short:              "Read a resource on the MCP server",
+	return addRawFlag(&cobra.Command{
+		Use:   "read-resource resource [command args...]",
+		Short: "Read a resource on the MCP server",
+		Long: `Read a resource on the MCP server.
+
+The resource may be a URI template listed by resource-templates, such as file:///{path}, with its
+variables given as a JSON object in --params:
+
+  mcp read-resource 'file:///{path}' --params '{"path":"README.md"}' npx -y @modelcontextprotocol/server-filesystem ~
+
+Use --query to run the result through a jq expression before it is formatted.`,
 		DisableFlagParsing: true,
 		SilenceUsage:       true,
 		Run: func(thisCmd *cobra.Command, args []string) {
@@ -35,6 +47,10 @@ func ReadResourceCmd() *cobra.Command {
 			parsedArgs := []string{}
 			resourceName := ""
 
+			var query *gojq.Code
+			outputFile := ""
+			decodeBase64 := false
+
 			i := 0
 			resourceExtracted := false
 
@@ -46,6 +62,33 @@ func ReadResourceCmd() *cobra.Command {
 				case (cmdArgs[i] == FlagParams || cmdArgs[i] == FlagParamsShort) && i+1 < len(cmdArgs):
 					ParamsString = cmdArgs[i+1]
 					i += 2
+				case cmdArgs[i] == FlagQuery && i+1 < len(cmdArgs):
+					query = parseQueryFlag(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagOutputFile && i+1 < len(cmdArgs):
+					outputFile = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagDecodeBase64:
+					decodeBase64 = true
+					i++
+				case cmdArgs[i] == FlagRaw:
+					RawOutput = true
+					i++
+				case cmdArgs[i] == FlagIndent && i+1 < len(cmdArgs):
+					setIndentOption(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagCompact:
+					CompactOption = true
+					i++
+				case cmdArgs[i] == FlagNoColor:
+					setColorOption(string(jsonutils.ColorNever))
+					i++
+				case cmdArgs[i] == FlagColor && i+1 < len(cmdArgs):
+					setColorOption(cmdArgs[i+1])
+					i += 2
+				case strings.HasPrefix(cmdArgs[i], FlagColor+"="):
+					setColorOption(strings.TrimPrefix(cmdArgs[i], FlagColor+"="))
+					i++
 				case !resourceExtracted:
 					resourceName = cmdArgs[i]
 					resourceExtracted = true
@@ -65,6 +108,30 @@ func ReadResourceCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
+			if RawOutput && (query != nil || outputFile != "") {
+				fmt.Fprintf(os.Stderr, "Error: %s can't be used with %s or %s\n", FlagRaw, FlagQuery, FlagOutputFile)
+				os.Exit(1)
+			}
+
+			if decodeBase64 && outputFile == "" {
+				fmt.Fprintf(os.Stderr, "Error: %s requires %s\n", FlagDecodeBase64, FlagOutputFile)
+				os.Exit(1)
+			}
+
+			if ParamsString != "" && ParamsString != "{}" {
+				var vars map[string]any
+				if jsonErr := json.Unmarshal([]byte(ParamsString), &vars); jsonErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: invalid JSON for params: %v\n", jsonErr)
+					os.Exit(1)
+				}
+				expanded, expandErr := expandResourceURI(resourceName, vars)
+				if expandErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", expandErr)
+					os.Exit(1)
+				}
+				resourceName = expanded
+			}
+
 			mcpClient, clientErr := CreateClientFunc(parsedArgs)
 			if clientErr != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", clientErr)
@@ -83,10 +150,28 @@ func ReadResourceCmd() *cobra.Command {
 				responseMap = map[string]any{}
 			}
 
-			if formatErr := FormatAndPrintResponse(thisCmd, responseMap, execErr); formatErr != nil {
+			if execErr == nil && RawOutput {
+				if rawErr := printRawResult(thisCmd, mcpClient, responseMap); rawErr != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", rawErr)
+					os.Exit(1)
+				}
+				return
+			}
+
+			var output any = responseMap
+			if execErr == nil && query != nil {
+				output, execErr = applyQuery(query, responseMap)
+			}
+
+			if execErr == nil && outputFile != "" {
+				printOutputFile(thisCmd, outputFile, output, decodeBase64)
+				return
+			}
+
+			if formatErr := FormatAndPrintResponse(thisCmd, output, execErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
 		},
-	}
+	})
 }
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -67,3 +68,53 @@ func TestReadResourceCmdRun_Success(t *testing.T) {
 	assertContains(t, output, "text/plain")
 	assertContains(t, output, "bar")
 }
+
+func TestReadResourceCmdRun_Query(t *testing.T) {
+	cmd := ReadResourceCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"server", "--query", ".contents[].uri", "-f", "json", "arg"})
+
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return map[string]any{
+			"contents": []any{
+				map[string]any{"uri": "test://foo", "text": "foo"},
+				map[string]any{"uri": "test://bar", "text": "bar"},
+			},
+		}, nil
+	})
+	defer cleanup()
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("cmd.Execute() error = %v", err)
+	}
+
+	if output := strings.TrimSpace(buf.String()); output != `["test://foo","test://bar"]` {
+		t.Errorf("output = %s, want the queried uris", output)
+	}
+}
+
+func TestReadResourceCmdRun_QueryJqBuiltins(t *testing.T) {
+	cmd := ReadResourceCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"server", "--query", `[.contents[] | .uri | ltrimstr("test://")] | sort | join(",")`, "-f", "json", "arg"})
+
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return map[string]any{
+			"contents": []any{
+				map[string]any{"uri": "test://foo", "text": "foo"},
+				map[string]any{"uri": "test://bar", "text": "bar"},
+			},
+		}, nil
+	})
+	defer cleanup()
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("cmd.Execute() error = %v", err)
+	}
+
+	if output := strings.TrimSpace(buf.String()); output != `"bar,foo"` {
+		t.Errorf("output = %s, want the joined uris", output)
+	}
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// ResourceTemplatesCmd creates the resource-templates command.
+func ResourceTemplatesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:                "resource-templates [command args...]",
+		Short:              "List available resource templates on the MCP server",
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		Run: func(thisCmd *cobra.Command, args []string) {
+			if len(args) == 1 && (args[0] == FlagHelp || args[0] == FlagHelpShort) {
+				_ = thisCmd.Help()
+				return
+			}
+
+			parsedArgs := ProcessFlags(args)
+
+			mcpClient, err := CreateClientFunc(parsedArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Example: mcp resource-templates npx -y @modelcontextprotocol/server-everything\n")
+				os.Exit(1)
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			// The client follows the server's pagination cursors to list every template
+			listTemplates := mcpClient.ListResourceTemplates
+			if FirstPageOnly {
+				listTemplates = mcpClient.ListResourceTemplatesByPage
+			}
+			resp, listErr := listTemplates(context.Background(), mcp.ListResourceTemplatesRequest{})
+			if listErr == nil && resp != nil && FirstPageOnly {
+				warnMorePages("resource templates", resp.NextCursor)
+			}
+
+			var templates []any
+			if listErr == nil && resp != nil {
+				templates = ConvertJSONToSlice(resp.ResourceTemplates)
+			}
+
+			if formatErr := printEntityList(thisCmd, "resourceTemplates", "resource template", templates, listErr); formatErr != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// templateExpression matches the expressions of a URI template, such as {path} or {?q,page}.
+var templateExpression = regexp.MustCompile(`\{([^}]*)\}`)
+
+// expandResourceURI expands a resource URI template, such as file:///{path}, with the given
+// variables. Strings, numbers and booleans fill simple variables, arrays fill list variables
+// and objects fill associative ones. Query variables like {?q} may be left out.
+func expandResourceURI(uri string, vars map[string]any) (string, error) {
+	template, err := uritemplate.New(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI template %q: %w", uri, err)
+	}
+
+	for _, match := range templateExpression.FindAllStringSubmatch(uri, -1) {
+		if strings.HasPrefix(match[1], "?") || strings.HasPrefix(match[1], "&") {
+			continue
+		}
+		for _, spec := range strings.Split(strings.TrimLeft(match[1], "+#./;"), ",") {
+			name, _, _ := strings.Cut(strings.TrimSuffix(spec, "*"), ":")
+			if _, ok := vars[name]; !ok {
+				return "", fmt.Errorf("no value for template variable %q", name)
+			}
+		}
+	}
+
+	values := uritemplate.Values{}
+	for _, name := range template.Varnames() {
+		value, ok := vars[name]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				list = append(list, templateValueString(item))
+			}
+			values.Set(name, uritemplate.List(list...))
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			kv := make([]string, 0, 2*len(v))
+			for _, key := range keys {
+				kv = append(kv, key, templateValueString(v[key]))
+			}
+			values.Set(name, uritemplate.KV(kv...))
+		default:
+			values.Set(name, uritemplate.String(templateValueString(v)))
+		}
+	}
+
+	return template.Expand(values)
+}
+
+// templateValueString formats a JSON value for a URI template, writing numbers without
+// exponents.
+func templateValueString(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
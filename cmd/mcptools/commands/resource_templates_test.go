@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResourceTemplatesCmdRun(t *testing.T) {
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "table"
+
+	cleanup := setupMockClient(func(method string, _ any) (map[string]any, error) {
+		if method != "resources/templates/list" {
+			t.Errorf("Expected method 'resources/templates/list', got %q", method)
+		}
+		return map[string]any{
+			"resourceTemplates": []any{
+				map[string]any{
+					"name":        "file",
+					"uriTemplate": "file:///{path}",
+					"description": "A file on disk",
+				},
+			},
+		}, nil
+	})
+	defer cleanup()
+
+	cmd := ResourceTemplatesCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"server", "args"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("cmd.Execute() error = %v", err)
+	}
+
+	output := buf.String()
+	assertContains(t, output, "URI TEMPLATE")
+	assertContains(t, output, "file:///{path}")
+	assertContains(t, output, "A file on disk")
+}
+
+func TestExpandResourceURI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		uri      string
+		vars     map[string]any
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "simple variable is escaped",
+			uri:      "repo://{owner}/{repo}",
+			vars:     map[string]any{"owner": "f", "repo": "mcp tools"},
+			expected: "repo://f/mcp%20tools",
+		},
+		{
+			name:     "reserved expansion keeps slashes",
+			uri:      "file:///{+path}",
+			vars:     map[string]any{"path": "docs/README.md"},
+			expected: "file:///docs/README.md",
+		},
+		{
+			name:     "numbers and lists",
+			uri:      "issues://{number}{?labels}",
+			vars:     map[string]any{"number": float64(1200000), "labels": []any{"bug", "ui"}},
+			expected: "issues://1200000?labels=bug,ui",
+		},
+		{
+			name:     "query variables are optional",
+			uri:      "issues://{number}{?state}",
+			vars:     map[string]any{"number": float64(7)},
+			expected: "issues://7",
+		},
+		{
+			name:    "missing path variable",
+			uri:     "file:///{path}",
+			vars:    map[string]any{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			uri:     "file:///{path",
+			vars:    map[string]any{"path": "a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandResourceURI(tc.uri, tc.vars)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expandResourceURI() = %q, expected an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandResourceURI() error = %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expandResourceURI() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -32,15 +32,22 @@ func ResourcesCmd() *cobra.Command {
 		}
 		defer CloseWithTimeout(mcpClient)
 
-			resp, listErr := mcpClient.ListResources(context.Background(), mcp.ListResourcesRequest{})
+			// The client follows the server's pagination cursors to list every resource
+			listResources := mcpClient.ListResources
+			if FirstPageOnly {
+				listResources = mcpClient.ListResourcesByPage
+			}
+			resp, listErr := listResources(context.Background(), mcp.ListResourcesRequest{})
+			if listErr == nil && resp != nil && FirstPageOnly {
+				warnMorePages("resources", resp.NextCursor)
+			}
 
 			var resources []any
 			if listErr == nil && resp != nil {
-				resources = ConvertJSONToSlice(resp.Resources)
+				resources = sortEntities(filterEntities(ConvertJSONToSlice(resp.Resources)))
 			}
 
-			resourcesMap := map[string]any{"resources": resources}
-			if formatErr := FormatAndPrintResponse(thisCmd, resourcesMap, listErr); formatErr != nil {
+			if formatErr := printEntityList(thisCmd, "resources", "resource", resources, listErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
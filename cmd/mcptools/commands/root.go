@@ -4,21 +4,65 @@ Package commands implements individual commands for the MCP CLI.
 package commands
 
 import (
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/f/mcptools/pkg/jsonutils"
 	"github.com/spf13/cobra"
 )
 
 // flags.
 const (
-	FlagFormat         = "--format"
-	FlagFormatShort    = "-f"
-	FlagParams         = "--params"
-	FlagParamsShort    = "-p"
-	FlagHelp           = "--help"
-	FlagHelpShort      = "-h"
-	FlagServerLogs     = "--server-logs"
-	FlagTransport      = "--transport"
-	FlagAuthUser       = "--auth-user"
-	FlagAuthHeader     = "--auth-header"
+	FlagFormat           = "--format"
+	FlagFormatShort      = "-f"
+	FlagParams           = "--params"
+	FlagParamsShort      = "-p"
+	FlagHelp             = "--help"
+	FlagHelpShort        = "-h"
+	FlagServerLogs       = "--server-logs"
+	FlagTransport        = "--transport"
+	FlagAuthUser         = "--auth-user"
+	FlagAuthHeader       = "--auth-header"
+	FlagMaxRetries       = "--max-retries"
+	FlagHeader           = "--header"
+	FlagEnv              = "--env"
+	FlagRepeat           = "--repeat"
+	FlagConcurrency      = "--concurrency"
+	FlagLogFile          = "--log-file"
+	FlagVerboseRPC       = "--verbose-rpc"
+	FlagNoPrettyEmbedded = "--no-pretty-embedded"
+	FlagSaveImages       = "--save-images"
+	FlagFields           = "--fields"
+	FlagQuery            = "--query"
+	FlagFirstPageOnly    = "--first-page-only"
+	FlagOutputFile       = "--output-file"
+	FlagDecodeBase64     = "--decode-base64"
+	FlagRaw              = "--raw"
+	FlagNoColor          = "--no-color"
+	FlagColor            = "--color"
+	FlagWidth            = "--width"
+	FlagProtocolVersion  = "--protocol-version"
+	FlagInitParams       = "--init-params"
+	FlagClientName       = "--client-name"
+	FlagClientVersion    = "--client-version"
+	FlagCapability       = "--capability"
+	FlagRoot             = "--root"
+	FlagConnectTimeout   = "--connect-timeout"
+	FlagNoCache          = "--no-cache"
+	FlagRetries          = "--retries"
+	FlagRetryDelay       = "--retry-delay"
+	FlagFilter           = "--filter"
+	FlagSearch           = "--search"
+	FlagSort             = "--sort"
+	FlagReverse          = "--reverse"
+	FlagIndent           = "--indent"
+	FlagCompact          = "--compact"
+	FlagArgFile          = "--arg-file"
+	FlagNoCoerce         = "--no-coerce"
+	FlagRender           = "--render"
+	FlagInteractive      = "--interactive"
+	FlagTemplate         = "--template"
+	FlagSaveTemplate     = "--save-template"
+	FlagDiffWith         = "--diff-with"
+	FlagCount            = "--count"
 )
 
 // entity types.
@@ -44,6 +88,75 @@ var (
 	AuthUser string
 	// AuthHeader is a custom Authorization header.
 	AuthHeader string
+	// HeaderOptions are extra "Key: Value" headers sent to HTTP and SSE servers.
+	HeaderOptions []string
+	// ServerEnvOptions are extra "KEY=VALUE" environment variables for stdio servers.
+	ServerEnvOptions []string
+	// MaxRetries is the number of reconnect attempts after an SSE connection drops.
+	MaxRetries = mcpclient.DefaultMaxRetries
+	// LogFileOption is a file that JSON-RPC traffic with the server is appended to.
+	LogFileOption string
+	// VerboseRPC logs JSON-RPC traffic with the server to stderr.
+	VerboseRPC bool
+	// NoPrettyEmbedded prints JSON held in text content verbatim instead of re-indenting it.
+	NoPrettyEmbedded bool
+	// SaveImagesDir is the directory images that can't be shown inline are saved to.
+	SaveImagesDir string
+	// FirstPageOnly lists only the first page of tools, resources or prompts instead of following
+	// the server's pagination cursors.
+	FirstPageOnly bool
+	// CountOption prints only the number of tools, resources or prompts a listing would show.
+	CountOption bool
+	// RawOutput prints the result object the server sent as compact JSON, without formatting it.
+	RawOutput bool
+	// ColorOption controls when output is colorized, valid values are "auto", "always" and "never".
+	// Default is "auto", which colorizes terminal output unless NO_COLOR is set.
+	ColorOption = "auto"
+	// NoColor disables colorized output, like --color=never.
+	NoColor bool
+	// WidthOption is the width table output is wrapped to. Zero uses $COLUMNS or the width of
+	// the terminal.
+	WidthOption int
+	// ProtocolVersionOption is the MCP protocol version requested from servers. Empty requests
+	// the newest supported version.
+	ProtocolVersionOption string
+	// InitParamsOption is a JSON object of initialize params, such as clientInfo and
+	// capabilities, that is merged over the ones the client sends by default.
+	InitParamsOption string
+	// ClientNameOption is the clientInfo name sent in the initialize request. Empty keeps
+	// "mcptools".
+	ClientNameOption string
+	// ClientVersionOption is the clientInfo version sent in the initialize request. Empty keeps
+	// the default.
+	ClientVersionOption string
+	// CapabilityOptions are the client capabilities declared in the initialize request, such as
+	// "sampling", "roots", "roots.listChanged" or "experimental.<name>".
+	CapabilityOptions []string
+	// RootOptions are directories declared to servers as roots, which declares the roots
+	// capability and answers roots/list requests with them.
+	RootOptions []string
+	// ConnectTimeout is how long the initialize handshake with a server may take, which includes
+	// the time a stdio server takes to start up.
+	ConnectTimeout = defaultConnectTimeout
+	// NoCacheOption makes call list the tools of the server instead of using the schemas cached
+	// under ~/.mcpt/cache.
+	NoCacheOption bool
+	// FilterOption is a glob that tools, resources and prompts are listed only if their name
+	// matches. Empty lists them all.
+	FilterOption string
+	// SearchOption is text that tools, resources and prompts are listed only if their name or
+	// description contains, ignoring case. Empty lists them all.
+	SearchOption string
+	// SortOption is the order tools, resources and prompts are listed in, valid values are
+	// "name" and "none". Default is "none", which keeps the server's order.
+	SortOption = sortNone
+	// ReverseOption lists tools, resources and prompts in reverse order.
+	ReverseOption bool
+	// IndentOption is the number of spaces JSON output is indented with. Zero keeps the default
+	// of the format.
+	IndentOption int
+	// CompactOption prints JSON output on a single line, whatever the format.
+	CompactOption bool
 )
 
 // RootCmd creates the root command.
@@ -53,6 +166,14 @@ func RootCmd() *cobra.Command {
 		Short: "MCP is a command line interface for interacting with MCP servers",
 		Long: `MCP is a command line interface for interacting with Model Context Protocol (MCP) servers.
 It allows you to discover and call tools, list resources, and interact with MCP-compatible services.`,
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			// Commands that parse their own flags set the color mode again as they do
+			if NoColor {
+				ColorOption = string(jsonutils.ColorNever)
+			}
+			setColorOption(ColorOption)
+			jsonutils.SetWidth(WidthOption)
+		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&FormatOption, "format", "f", "table", "Output format (table, json, pretty)")
@@ -61,6 +182,31 @@ It allows you to discover and call tools, list resources, and interact with MCP-
 	cmd.PersistentFlags().StringVar(&TransportOption, "transport", "http", "HTTP transport type (http, sse)")
 	cmd.PersistentFlags().StringVar(&AuthUser, "auth-user", "", "Basic authentication in username:password format")
 	cmd.PersistentFlags().StringVar(&AuthHeader, "auth-header", "", "Custom Authorization header (e.g., 'Bearer token' or 'Basic base64credentials')")
+	cmd.PersistentFlags().StringArrayVar(&HeaderOptions, "header", nil, "Extra HTTP header in \"Key: Value\" format for HTTP/SSE servers (repeatable)")
+	cmd.PersistentFlags().StringArrayVar(&ServerEnvOptions, "env", nil, "Environment variable in KEY=VALUE format for stdio servers (repeatable)")
+	cmd.PersistentFlags().IntVar(&MaxRetries, "max-retries", mcpclient.DefaultMaxRetries, "Reconnect attempts after an SSE connection drops (0 to disable)")
+	cmd.PersistentFlags().StringVar(&LogFileOption, "log-file", "", "Append JSON-RPC traffic with the server to a file")
+	cmd.PersistentFlags().BoolVar(&VerboseRPC, "verbose-rpc", false, "Log JSON-RPC traffic with the server to stderr")
+	cmd.PersistentFlags().BoolVar(&FirstPageOnly, "first-page-only", false, "List only the first page of tools, resources or prompts; its size is set by the server, since MCP list requests take no page size")
+	cmd.PersistentFlags().BoolVar(&CountOption, "count", false, "Print only the number of tools, resources or prompts")
+	cmd.PersistentFlags().StringVar(&FilterOption, "filter", "", "List only tools, resources or prompts whose name matches a glob such as 'read_*'")
+	cmd.PersistentFlags().StringVar(&SearchOption, "search", "", "List only tools, resources or prompts whose name or description contains the text")
+	cmd.PersistentFlags().StringVar(&SortOption, "sort", sortNone, "Order to list tools, resources or prompts in (name, none)")
+	cmd.PersistentFlags().BoolVar(&ReverseOption, "reverse", false, "List tools, resources or prompts in reverse order")
+	cmd.PersistentFlags().StringVar(&ColorOption, "color", "auto", "When to colorize output (auto, always, never)")
+	cmd.PersistentFlags().BoolVar(&NoColor, "no-color", false, "Disable colorized output (NO_COLOR is also honored)")
+	cmd.PersistentFlags().StringVar(&ProtocolVersionOption, "protocol-version", "", "MCP protocol version to request (2025-03-26, 2024-11-05; default newest)")
+	cmd.PersistentFlags().StringVar(&InitParamsOption, "init-params", "", "JSON object merged into the params of the initialize request")
+	cmd.PersistentFlags().StringVar(&ClientNameOption, "client-name", "", "Client name sent in the initialize request (default mcptools)")
+	cmd.PersistentFlags().StringVar(&ClientVersionOption, "client-version", "", "Client version sent in the initialize request")
+	cmd.PersistentFlags().StringArrayVar(&CapabilityOptions, "capability", nil, "Client capability to declare: sampling, roots, roots.listChanged or experimental.<name> (repeatable)")
+	cmd.PersistentFlags().StringArrayVar(&RootOptions, "root", nil, "Directory to expose to servers as a root through roots/list (repeatable)")
+	cmd.PersistentFlags().DurationVar(&ConnectTimeout, "connect-timeout", defaultConnectTimeout, "How long the initialize handshake may take, such as 60s for a slow npx download")
+	cmd.PersistentFlags().IntVar(&WidthOption, "width", 0, "Width to wrap table output to, overriding $COLUMNS and the terminal width")
+	cmd.PersistentFlags().IntVar(&IndentOption, "indent", 0, "Number of spaces to indent JSON output with, for any format")
+	cmd.PersistentFlags().BoolVar(&CompactOption, "compact", false, "Print JSON output on a single line, for any format")
+	cmd.PersistentFlags().BoolVar(&NoPrettyEmbedded, "no-pretty-embedded", false, "Print JSON inside text content verbatim in table output")
+	cmd.PersistentFlags().StringVar(&SaveImagesDir, "save-images", "", "Save images that can't be shown inline to this directory and print their paths")
 
 	return cmd
 }
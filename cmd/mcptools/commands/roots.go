@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// methodListRoots is the method servers call to get the roots declared with --root.
+const methodListRoots = "roots/list"
+
+// resolveRoots turns the directories given with --root into roots with absolute file:// URIs,
+// named after the last element of their path.
+func resolveRoots(paths []string) ([]mcp.Root, error) {
+	roots := make([]mcp.Root, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", path, err)
+		}
+		if _, err := os.Stat(absPath); err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", path, err)
+		}
+
+		// Windows paths such as C:\dir become file:///C:/dir
+		uriPath := filepath.ToSlash(absPath)
+		if !strings.HasPrefix(uriPath, "/") {
+			uriPath = "/" + uriPath
+		}
+		uri := url.URL{Scheme: "file", Path: uriPath}
+		roots = append(roots, mcp.Root{URI: uri.String(), Name: filepath.Base(absPath)})
+	}
+	return roots, nil
+}
+
+// rootsRequestHandler answers the roots/list requests of servers with roots, and any other
+// request with a method not found error.
+func rootsRequestHandler(roots []mcp.Root) mcpclient.RequestHandler {
+	return func(_ context.Context, method string, _ json.RawMessage) (any, error) {
+		if method != methodListRoots {
+			return nil, &mcpclient.RPCError{Code: -32601, Message: "method not found: " + method}
+		}
+		return mcp.ListRootsResult{Roots: roots}, nil
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResolveRoots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := resolveRoots([]string{dir})
+	if err != nil {
+		t.Fatalf("resolveRoots() error = %v", err)
+	}
+	if len(roots) != 1 || roots[0].Name != "project" ||
+		!strings.HasPrefix(roots[0].URI, "file:///") || !strings.HasSuffix(roots[0].URI, "/project") {
+		t.Errorf("resolveRoots() = %+v, want a file URI named project", roots)
+	}
+
+	if _, err := resolveRoots([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("resolveRoots() expected an error for a missing directory")
+	}
+}
+
+func TestRootsRequestHandler(t *testing.T) {
+	roots := []mcp.Root{{URI: "file:///tmp/project", Name: "project"}}
+	handler := rootsRequestHandler(roots)
+
+	result, err := handler(context.Background(), "roots/list", nil)
+	if err != nil {
+		t.Fatalf("roots/list error = %v", err)
+	}
+	if listed := result.(mcp.ListRootsResult).Roots; len(listed) != 1 || listed[0] != roots[0] {
+		t.Errorf("roots/list = %+v, want %+v", listed, roots)
+	}
+
+	_, err = handler(context.Background(), "sampling/createMessage", nil)
+	var rpcErr *mcpclient.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("unknown method error = %v, want method not found", err)
+	}
+}
+
+func TestNewInitializeRequestDeclaresRoots(t *testing.T) {
+	defer func() { RootOptions = nil }()
+
+	RootOptions = []string{"."}
+	request, err := newInitializeRequest()
+	if err != nil {
+		t.Fatalf("newInitializeRequest() error = %v", err)
+	}
+	if request.Params.Capabilities.Roots == nil {
+		t.Error("newInitializeRequest() didn't declare the roots capability for --root")
+	}
+}
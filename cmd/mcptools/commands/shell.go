@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -46,6 +48,48 @@ func ShellCmd() *cobra.Command { //nolint:gocyclo
 				case cmdArgs[i] == FlagAuthHeader && i+1 < len(cmdArgs):
 					AuthHeader = cmdArgs[i+1]
 					i += 2
+				case cmdArgs[i] == FlagHeader && i+1 < len(cmdArgs):
+					HeaderOptions = append(HeaderOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagEnv && i+1 < len(cmdArgs):
+					ServerEnvOptions = append(ServerEnvOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagProtocolVersion && i+1 < len(cmdArgs):
+					ProtocolVersionOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagInitParams && i+1 < len(cmdArgs):
+					InitParamsOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagClientName && i+1 < len(cmdArgs):
+					ClientNameOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagClientVersion && i+1 < len(cmdArgs):
+					ClientVersionOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagCapability && i+1 < len(cmdArgs):
+					CapabilityOptions = append(CapabilityOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagRoot && i+1 < len(cmdArgs):
+					RootOptions = append(RootOptions, cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagConnectTimeout && i+1 < len(cmdArgs):
+					setConnectTimeout(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagMaxRetries && i+1 < len(cmdArgs):
+					setMaxRetries(cmdArgs[i+1])
+					i += 2
+				case cmdArgs[i] == FlagLogFile && i+1 < len(cmdArgs):
+					LogFileOption = cmdArgs[i+1]
+					i += 2
+				case cmdArgs[i] == FlagVerboseRPC:
+					VerboseRPC = true
+					i++
+				case cmdArgs[i] == FlagNoPrettyEmbedded:
+					NoPrettyEmbedded = true
+					i++
+				case cmdArgs[i] == FlagSaveImages && i+1 < len(cmdArgs):
+					SaveImagesDir = cmdArgs[i+1]
+					i += 2
 				default:
 					parsedArgs = append(parsedArgs, cmdArgs[i])
 					i++
@@ -74,7 +118,7 @@ func ShellCmd() *cobra.Command { //nolint:gocyclo
 			defer func() { _ = line.Close() }()
 
 			defer setUpHistory(line)()
-			setUpCompleter(line)
+			setUpCompleter(line, newShellToolNames(mcpClient))
 
 			for {
 				input, err := line.Prompt("mcp > ")
@@ -244,6 +288,9 @@ func callCommand(thisCmd *cobra.Command, mcpClient *client.Client, commandArgs [
 		toolResponse, execErr = mcpClient.CallTool(context.Background(), request)
 		if execErr == nil && toolResponse != nil {
 			resp = ConvertJSONToMap(toolResponse)
+			if toolErr := toolResultError(resp); toolErr != nil {
+				execErr = fmt.Errorf("tool %s failed: %w", entityName, toolErr)
+			}
 		} else {
 			resp = map[string]any{}
 		}
@@ -294,43 +341,127 @@ func parseJSONBestEffort(jsonString string, params *map[string]any) error {
 	return nil
 }
 
+// shellHistoryPath returns the path of the file the shell history is kept in.
+func shellHistoryPath() string {
+	return filepath.Join(getHomeDirectory(), ".config", "mcp", "history")
+}
+
 func setUpHistory(line *liner.State) func() {
-	historyFile := filepath.Join(getHomeDirectory(), ".mcp_history")
-	if f, err := os.Open(filepath.Clean(historyFile)); err == nil {
+	historyFile := shellHistoryPath()
+	f, err := os.Open(filepath.Clean(historyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		// Carry over the history of older versions, which was kept in the home directory
+		f, err = os.Open(filepath.Join(getHomeDirectory(), ".mcp_history"))
+	}
+	if err == nil {
 		_, _ = line.ReadHistory(f)
 		_ = f.Close()
 	}
 
 	return func() {
-		if f, err := os.Create(historyFile); err == nil {
+		if err := os.MkdirAll(filepath.Dir(historyFile), 0o750); err != nil {
+			return
+		}
+		if f, err := os.OpenFile(historyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600); err == nil {
 			_, _ = line.WriteHistory(f)
 			_ = f.Close()
 		}
 	}
 }
 
-func setUpCompleter(line *liner.State) {
-	line.SetCompleter(func(line string) (c []string) {
-		commands := []string{
-			"tools",
-			"resources",
-			"prompts",
-			"call",
-			"format",
-			"help",
-			"exit",
-			"/h",
-			"/q",
-			"/help",
-			"/quit",
+// shellCommands are the built-in commands the shell completes.
+var shellCommands = []string{
+	"tools",
+	"resources",
+	"prompts",
+	"call",
+	"format",
+	"help",
+	"exit",
+	"/h",
+	"/q",
+	"/help",
+	"/quit",
+}
+
+// shellToolNames lists the tools of the connected server for completion. The list is fetched
+// on first use and again after the server reports that its tools changed.
+type shellToolNames struct {
+	client *client.Client
+	mu     sync.Mutex
+	names  []string
+	loaded bool
+}
+
+func newShellToolNames(mcpClient *client.Client) *shellToolNames {
+	toolNames := &shellToolNames{client: mcpClient}
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == "notifications/tools/list_changed" {
+			toolNames.mu.Lock()
+			toolNames.loaded = false
+			toolNames.mu.Unlock()
+		}
+	})
+	return toolNames
+}
+
+// get returns the tool names, fetching them from the server if needed.
+func (s *shellToolNames) get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return s.names
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A server without tools is only asked once
+	s.loaded = true
+	s.names = nil
+	result, err := s.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil || result == nil {
+		return nil
+	}
+	for _, tool := range result.Tools {
+		s.names = append(s.names, tool.Name)
+	}
+	return s.names
+}
+
+func setUpCompleter(line *liner.State, toolNames *shellToolNames) {
+	line.SetCompleter(func(line string) []string {
+		if !strings.HasPrefix(line, "call ") && strings.Contains(line, " ") {
+			return nil
 		}
-		for _, cmd := range commands {
-			if strings.HasPrefix(cmd, line) {
-				c = append(c, cmd)
+		return shellCompletions(line, toolNames.get())
+	})
+}
+
+// shellCompletions returns the completions of line: tool names after "call ", and shell
+// commands or tool names for direct calling at the start of the line.
+func shellCompletions(line string, toolNames []string) (c []string) {
+	if rest, found := strings.CutPrefix(line, "call "); found {
+		if strings.Contains(rest, " ") {
+			return nil
+		}
+		for _, name := range toolNames {
+			if strings.HasPrefix(name, rest) {
+				c = append(c, "call "+name)
 			}
 		}
-		return
-	})
+		return c
+	}
+
+	if strings.Contains(line, " ") {
+		return nil
+	}
+	for _, candidate := range append(append([]string{}, shellCommands...), toolNames...) {
+		if strings.HasPrefix(candidate, line) {
+			c = append(c, candidate)
+		}
+	}
+	return c
 }
 
 func printShellHelp(thisCmd *cobra.Command) {
@@ -347,4 +478,7 @@ func printShellHelp(thisCmd *cobra.Command) {
 	fmt.Fprintln(thisCmd.OutOrStdout(), "Special Commands:")
 	fmt.Fprintln(thisCmd.OutOrStdout(), "  /h, /help                  Show this help")
 	fmt.Fprintln(thisCmd.OutOrStdout(), "  /q, /quit, exit            Exit the shell")
+	fmt.Fprintln(thisCmd.OutOrStdout(), "Keys:")
+	fmt.Fprintln(thisCmd.OutOrStdout(), "  Tab                        Complete commands and tool names")
+	fmt.Fprintln(thisCmd.OutOrStdout(), "  Ctrl-R                     Search the command history")
 }
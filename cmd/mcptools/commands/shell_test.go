@@ -365,3 +365,48 @@ func TestShellExit(t *testing.T) {
 		})
 	}
 }
+
+func TestShellCompletions(t *testing.T) {
+	toolNames := []string{"read_file", "read_multiple_files", "write_file"}
+
+	testCases := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{
+			name:     "tool names after call",
+			line:     "call read",
+			expected: []string{"call read_file", "call read_multiple_files"},
+		},
+		{
+			name:     "all tool names after call",
+			line:     "call ",
+			expected: []string{"call read_file", "call read_multiple_files", "call write_file"},
+		},
+		{
+			name:     "commands and tool names at the start of the line",
+			line:     "w",
+			expected: []string{"write_file"},
+		},
+		{
+			name:     "commands",
+			line:     "t",
+			expected: []string{"tools"},
+		},
+		{
+			name:     "nothing after the tool name",
+			line:     "call read_file {",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := shellCompletions(tc.line, toolNames)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("shellCompletions(%q) = %v, want %v", tc.line, got, tc.expected)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	mcpclient "github.com/f/mcptools/pkg/client"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -63,22 +64,29 @@ func (m *MockTransport) GetSessionId() string {
 func setupMockClient(executeFunc func(method string, _ any) (map[string]any, error)) func() {
 	// Save original function and restore later
 	originalFunc := CreateClientFunc
+	originalTargetFunc := createTargetClientFunc
 
 	mockTransport := &MockTransport{
 		ExecuteFunc: executeFunc,
 	}
 
-	mockClient := client.NewClient(mockTransport)
+	// Clients created by CreateClientFunc record their responses, which tells transport
+	// errors and JSON-RPC errors apart
+	mockClient := client.NewClient(mcpclient.NewRecording(mockTransport))
 	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
 
 	// Override the function that creates clients
 	CreateClientFunc = func(_ []string, _ ...client.ClientOption) (*client.Client, error) {
 		return mockClient, nil
 	}
+	createTargetClientFunc = func(_ serverTarget) (*client.Client, error) {
+		return mockClient, nil
+	}
 
 	// Return a cleanup function
 	return func() {
 		CreateClientFunc = originalFunc
+		createTargetClientFunc = originalTargetFunc
 	}
 }
 
@@ -4,6 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/f/mcptools/pkg/jsonutils"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
@@ -12,8 +17,21 @@ import (
 // ToolsCmd creates the tools command.
 func ToolsCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:                "tools [command args...]",
-		Short:              "List available tools on the MCP server",
+		Use:   "tools [--multi server]... [command args...]",
+		Short: "List available tools on the MCP server",
+		Long: `List the tools of an MCP server. Repeat --multi (or -M) to list the tools of several servers
+at once, each given as an alias, a URL or a quoted command, optionally labelled with name=. The
+servers are connected to concurrently, and their tools are grouped by server with names prefixed
+by the label, such as "fs:read_file". A server that can't be reached is reported without stopping
+the others.
+
+Use --schema to print the input schemas of all tools as one JSON Schema document, with each
+tool's schema under $defs, or --markdown to print a Markdown reference of the tools with a
+table of the parameters of each, for instance to generate documentation.
+
+  mcp tools npx -y @modelcontextprotocol/server-filesystem ~
+  mcp tools --markdown npx -y @modelcontextprotocol/server-filesystem ~ > TOOLS.md
+  mcp tools --multi fs --multi everything="npx -y @modelcontextprotocol/server-everything"`,
 		DisableFlagParsing: true,
 		SilenceUsage:       true,
 		Run: func(thisCmd *cobra.Command, args []string) {
@@ -22,7 +40,46 @@ func ToolsCmd() *cobra.Command {
 				return
 			}
 
-			parsedArgs := ProcessFlags(args)
+			servers := []string{}
+			remainingArgs := []string{}
+			schema := false
+			markdown := false
+			for i := 0; i < len(args); i++ {
+				switch {
+				case (args[i] == "--multi" || args[i] == "-M") && i+1 < len(args):
+					servers = append(servers, args[i+1])
+					i++
+				case args[i] == "--schema":
+					schema = true
+				case args[i] == "--markdown":
+					markdown = true
+				case args[i] == "--" && len(remainingArgs) == 0:
+					// Allow separating the server command with --
+				default:
+					remainingArgs = append(remainingArgs, args[i])
+				}
+			}
+
+			parsedArgs := ProcessFlags(remainingArgs)
+			if schema && markdown {
+				fmt.Fprintf(os.Stderr, "Error: --schema can't be combined with --markdown\n")
+				os.Exit(1)
+			}
+			if len(servers) > 0 {
+				if schema || markdown {
+					fmt.Fprintf(os.Stderr, "Error: --multi can't be combined with --schema or --markdown\n")
+					os.Exit(1)
+				}
+				if len(parsedArgs) > 0 {
+					fmt.Fprintf(os.Stderr, "Error: --multi can't be combined with a server command\n")
+					os.Exit(1)
+				}
+				if failed := printMultiTools(thisCmd, listMultiTools(servers)); failed {
+					os.Exit(1)
+				}
+				return
+			}
+
 			mcpClient, err := CreateClientFunc(parsedArgs)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -31,18 +88,150 @@ func ToolsCmd() *cobra.Command {
 			}
 			defer CloseWithTimeout(mcpClient)
 
-			resp, listErr := mcpClient.ListTools(context.Background(), mcp.ListToolsRequest{})
+			// The client follows the server's pagination cursors to list every tool
+			listTools := mcpClient.ListTools
+			if FirstPageOnly {
+				listTools = mcpClient.ListToolsByPage
+			}
+			resp, listErr := listTools(context.Background(), mcp.ListToolsRequest{})
+			if listErr == nil && resp != nil && FirstPageOnly {
+				warnMorePages("tools", resp.NextCursor)
+			}
 
 			var tools []any
 			if listErr == nil && resp != nil {
-				tools = ConvertJSONToSlice(resp.Tools)
+				tools = sortEntities(filterEntities(ConvertJSONToSlice(resp.Tools)))
+			}
+
+			if schema || markdown {
+				if listErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", listErr)
+					CloseWithTimeout(mcpClient)
+					os.Exit(1)
+				}
+				printToolsReference(thisCmd, serverName(mcpClient), tools, markdown)
+				return
 			}
 
-			toolsMap := map[string]any{"tools": tools}
-			if formatErr := FormatAndPrintResponse(thisCmd, toolsMap, listErr); formatErr != nil {
+			if formatErr := printEntityList(thisCmd, "tools", "tool", tools, listErr); formatErr != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", formatErr)
 				os.Exit(1)
 			}
 		},
 	}
 }
+
+// serverTools are the tools listed from one of several servers.
+type serverTools struct {
+	server string
+	tools  []any
+	err    error
+}
+
+// listMultiTools connects to each server concurrently and lists its tools, with names
+// prefixed by the server. The results are in the order of servers, and a server that fails
+// has its error set without affecting the others.
+func listMultiTools(servers []string) []serverTools {
+	results := make([]serverTools, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			label, command := splitServerLabel(server)
+			results[i] = serverTools{server: label}
+
+			// CreateClientFunc resolves an alias given as a single argument
+			mcpClient, err := CreateClientFunc(ParseCommandString(command))
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			defer CloseWithTimeout(mcpClient)
+
+			tools, err := listEntities(context.Background(), mcpClient, "tools")
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].tools = prefixToolNames(label, sortEntities(filterEntities(tools)))
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// splitServerLabel splits a --multi value such as "fs=npx -y server" into its label and
+// server. Values without a label are their own label.
+func splitServerLabel(value string) (string, string) {
+	label, server, found := strings.Cut(value, "=")
+	if !found || label == "" || strings.IndexFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	}) >= 0 {
+		return value, value
+	}
+	return label, server
+}
+
+// prefixToolNames returns copies of tools with their names prefixed by server.
+func prefixToolNames(server string, tools []any) []any {
+	prefixed := make([]any, 0, len(tools))
+	for _, tool := range tools {
+		toolMap, ok := tool.(map[string]any)
+		if !ok {
+			prefixed = append(prefixed, tool)
+			continue
+		}
+		toolCopy := make(map[string]any, len(toolMap))
+		for k, v := range toolMap {
+			toolCopy[k] = v
+		}
+		if name, ok := toolMap["name"].(string); ok {
+			toolCopy["name"] = server + ":" + name
+		}
+		prefixed = append(prefixed, toolCopy)
+	}
+	return prefixed
+}
+
+// printMultiTools prints the tools of several servers, grouped by server in table format and
+// merged into one list otherwise. Errors are printed to stderr, and it reports whether any
+// server failed.
+func printMultiTools(cmd *cobra.Command, results []serverTools) bool {
+	failed := false
+	merged := []any{}
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", result.server, result.err)
+			failed = true
+			continue
+		}
+		merged = append(merged, result.tools...)
+	}
+
+	if CountOption {
+		fmt.Fprintln(cmd.OutOrStdout(), len(merged))
+		return failed
+	}
+
+	if jsonutils.ParseFormat(FormatOption) != jsonutils.FormatTable {
+		if formatErr := FormatAndPrintResponse(cmd, map[string]any{"tools": merged}, nil); formatErr != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+			return true
+		}
+		return failed
+	}
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s:\n", result.server)
+		if formatErr := FormatAndPrintResponse(cmd, map[string]any{"tools": result.tools}, nil); formatErr != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", formatErr)
+			failed = true
+		}
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), countLabel(len(merged), "tool"))
+	return failed
+}
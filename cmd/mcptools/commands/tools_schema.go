@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/spf13/cobra"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the document printed by tools --schema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// toolsTitle returns the title of the documentation of a server's tools, named after the
+// server when it is known.
+func toolsTitle(server string) string {
+	if server == "" {
+		return "Tools"
+	}
+	return server + " tools"
+}
+
+// toolsSchemaDocument combines the input schemas of tools into one JSON Schema document,
+// with the schema of each tool under $defs by tool name. A tool's description is added to
+// its schema unless the schema has its own.
+func toolsSchemaDocument(server string, tools []any) map[string]any {
+	defs := make(map[string]any, len(tools))
+	for _, tool := range tools {
+		toolMap, ok := tool.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := toolMap["name"].(string)
+		schema := map[string]any{}
+		if inputSchema, ok := toolMap["inputSchema"].(map[string]any); ok {
+			for k, v := range inputSchema {
+				schema[k] = v
+			}
+		}
+		if description, _ := toolMap["description"].(string); description != "" && schema["description"] == nil {
+			schema["description"] = description
+		}
+		defs[name] = schema
+	}
+
+	return map[string]any{
+		"$schema": jsonSchemaDialect,
+		"title":   toolsTitle(server),
+		"$defs":   defs,
+	}
+}
+
+// toolsMarkdown renders a Markdown reference of tools, with a section per tool holding its
+// description and a table of its parameters.
+func toolsMarkdown(server string, tools []any) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", toolsTitle(server))
+
+	for _, tool := range tools {
+		toolMap, ok := tool.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := toolMap["name"].(string)
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		if description, _ := toolMap["description"].(string); description != "" {
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(description))
+		}
+
+		schema, _ := toolMap["inputSchema"].(map[string]any)
+		properties, _ := schema["properties"].(map[string]any)
+		if len(properties) == 0 {
+			b.WriteString("_No parameters._\n")
+			continue
+		}
+
+		required := map[string]bool{}
+		if requiredList, ok := schema["required"].([]any); ok {
+			for _, r := range requiredList {
+				if s, ok := r.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+
+		names := make([]string, 0, len(properties))
+		for paramName := range properties {
+			names = append(names, paramName)
+		}
+		sort.Strings(names)
+
+		b.WriteString("| Parameter | Type | Required | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, paramName := range names {
+			property, _ := properties[paramName].(map[string]any)
+			requiredCell := "no"
+			if required[paramName] {
+				requiredCell = "yes"
+			}
+			description, _ := property["description"].(string)
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				paramName, markdownCell(schemaType(property)), requiredCell, markdownCell(description))
+		}
+	}
+
+	return b.String()
+}
+
+// schemaType describes the type of a property schema, such as "string[]" for an array of
+// strings or "string (one of: a, b)" for an enum.
+func schemaType(property map[string]any) string {
+	var typeName string
+	switch t := property["type"].(type) {
+	case string:
+		typeName = t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		typeName = strings.Join(types, " | ")
+	}
+
+	if typeName == "array" {
+		if items, ok := property["items"].(map[string]any); ok {
+			if itemType := schemaType(items); itemType != "" && !strings.Contains(itemType, " ") {
+				typeName = itemType + "[]"
+			}
+		}
+	}
+	if typeName == "" {
+		typeName = "any"
+	}
+
+	if enum, ok := property["enum"].([]any); ok && len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, value := range enum {
+			if s, ok := value.(string); ok {
+				values[i] = s
+				continue
+			}
+			data, _ := json.Marshal(value)
+			values[i] = string(data)
+		}
+		typeName += " (one of: " + strings.Join(values, ", ") + ")"
+	}
+	return typeName
+}
+
+// markdownCell escapes text for a Markdown table cell, which can't hold pipes or line breaks.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
+
+// serverName returns the name the server gave when the client initialized, or "" when it
+// isn't known.
+func serverName(mcpClient *client.Client) string {
+	info, _ := initializeResult(mcpClient)
+	serverInfo, _ := info["serverInfo"].(map[string]any)
+	name, _ := serverInfo["name"].(string)
+	return name
+}
+
+// printToolsReference prints the tools of a server as a JSON Schema document or, with
+// markdown, as a Markdown reference.
+func printToolsReference(cmd *cobra.Command, server string, tools []any, markdown bool) {
+	if markdown {
+		fmt.Fprint(cmd.OutOrStdout(), toolsMarkdown(server, tools))
+		return
+	}
+
+	data, err := json.MarshalIndent(toolsSchemaDocument(server, tools), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func schemaTestTools() []any {
+	return []any{
+		map[string]any{
+			"name":        "search",
+			"description": "Search | find things",
+			"inputSchema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{"type": "string", "description": "What to\nsearch for"},
+					"limit": map[string]any{"type": "integer"},
+					"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"sort":  map[string]any{"type": "string", "enum": []any{"asc", "desc"}},
+				},
+				"required": []any{"query"},
+			},
+		},
+		map[string]any{
+			"name":        "ping",
+			"inputSchema": map[string]any{"type": "object"},
+		},
+	}
+}
+
+func TestToolsSchemaDocument(t *testing.T) {
+	document := toolsSchemaDocument("files", schemaTestTools())
+
+	if document["$schema"] != jsonSchemaDialect || document["title"] != "files tools" {
+		t.Errorf("unexpected document header: %v", document)
+	}
+	defs, _ := document["$defs"].(map[string]any)
+	search, _ := defs["search"].(map[string]any)
+	if search["description"] != "Search | find things" || search["required"] == nil {
+		t.Errorf("expected the search schema with its description, got %v", search)
+	}
+	if _, ok := defs["ping"]; !ok {
+		t.Errorf("expected a schema for ping, got %v", defs)
+	}
+}
+
+func TestToolsMarkdown(t *testing.T) {
+	expected := "# Tools\n" +
+		"\n## search\n\n" +
+		"Search | find things\n\n" +
+		"| Parameter | Type | Required | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `limit` | integer | no |  |\n" +
+		"| `query` | string | yes | What to search for |\n" +
+		"| `sort` | string (one of: asc, desc) | no |  |\n" +
+		"| `tags` | string[] | no |  |\n" +
+		"\n## ping\n\n" +
+		"_No parameters._\n"
+
+	if got := toolsMarkdown("", schemaTestTools()); got != expected {
+		t.Errorf("toolsMarkdown() =\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestToolsCmdSchema(t *testing.T) {
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return map[string]any{"tools": schemaTestTools()}, nil
+	})
+	defer cleanup()
+
+	cmd := ToolsCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--schema", "--", "server"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cmd.Execute() error = %v", err)
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("expected a JSON document, got %q: %v", buf.String(), err)
+	}
+	defs, _ := document["$defs"].(map[string]any)
+	if len(defs) != 2 {
+		t.Errorf("expected schemas for 2 tools, got %v", defs)
+	}
+}
@@ -2,7 +2,14 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestToolsCmdRun_Help(t *testing.T) {
@@ -60,3 +67,123 @@ func TestToolsCmdRun(t *testing.T) {
 	assertContains(t, output, "test-tool")
 	assertContains(t, output, "A test tool")
 }
+
+func TestToolsCmdRun_Pagination(t *testing.T) {
+	origFirstPageOnly := FirstPageOnly
+	defer func() { FirstPageOnly = origFirstPageOnly }()
+
+	cleanup := setupMockClient(func(_ string, params any) (map[string]any, error) {
+		data, _ := json.Marshal(params)
+		if strings.Contains(string(data), `"cursor":"page-2"`) {
+			return map[string]any{
+				"tools": []any{map[string]any{"name": "second-page-tool"}},
+			}, nil
+		}
+		return map[string]any{
+			"tools":      []any{map[string]any{"name": "first-page-tool"}},
+			"nextCursor": "page-2",
+		}, nil
+	})
+	defer cleanup()
+
+	testCases := []struct {
+		name       string
+		args       []string
+		wantSecond bool
+	}{
+		{name: "all pages", args: []string{"server"}, wantSecond: true},
+		{name: "first page only", args: []string{"--first-page-only", "server"}, wantSecond: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			FirstPageOnly = false
+			cmd := ToolsCmd()
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("cmd.Execute() error = %v", err)
+			}
+
+			output := buf.String()
+			assertContains(t, output, "first-page-tool")
+			if got := strings.Contains(output, "second-page-tool"); got != tc.wantSecond {
+				t.Errorf("second page listed = %v, want %v:\n%s", got, tc.wantSecond, output)
+			}
+		})
+	}
+}
+
+func TestListMultiTools(t *testing.T) {
+	originalFunc := CreateClientFunc
+	defer func() { CreateClientFunc = originalFunc }()
+
+	CreateClientFunc = func(args []string, _ ...client.ClientOption) (*client.Client, error) {
+		if args[0] == "down" {
+			return nil, errors.New("connection refused")
+		}
+		name := strings.Join(args, "-")
+		mockClient := client.NewClient(&MockTransport{
+			ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+				return map[string]any{"tools": []any{map[string]any{"name": "tool-of-" + name}}}, nil
+			},
+		})
+		_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+		return mockClient, nil
+	}
+
+	results := listMultiTools([]string{"fs", "down", "srv=npx server"})
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].err != nil || results[0].tools[0].(map[string]any)["name"] != "fs:tool-of-fs" {
+		t.Errorf("Expected the prefixed tool of fs, got %+v", results[0])
+	}
+	if results[1].err == nil {
+		t.Error("Expected an error for the server that is down")
+	}
+	if results[2].err != nil || results[2].tools[0].(map[string]any)["name"] != "srv:tool-of-npx-server" {
+		t.Errorf("Expected the labelled command to be split into arguments, got %+v", results[2])
+	}
+
+	origFormatOption := FormatOption
+	defer func() { FormatOption = origFormatOption }()
+	FormatOption = "json"
+
+	cmd := ToolsCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	if failed := printMultiTools(cmd, results); !failed {
+		t.Error("Expected printMultiTools to report the failed server")
+	}
+	var output struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(output.Tools) != 2 || output.Tools[0].Name != "fs:tool-of-fs" || output.Tools[1].Name != "srv:tool-of-npx-server" {
+		t.Errorf("Expected the merged tools of both servers, got %+v", output.Tools)
+	}
+}
+
+func TestSplitServerLabel(t *testing.T) {
+	testCases := []struct {
+		value, label, server string
+	}{
+		{"fs", "fs", "fs"},
+		{"fs=npx -y server", "fs", "npx -y server"},
+		{"https://example.com/mcp?a=b", "https://example.com/mcp?a=b", "https://example.com/mcp?a=b"},
+		{"node server.js --opt=1", "node server.js --opt=1", "node server.js --opt=1"},
+	}
+	for _, tc := range testCases {
+		label, server := splitServerLabel(tc.value)
+		if label != tc.label || server != tc.server {
+			t.Errorf("splitServerLabel(%q) = %q, %q, want %q, %q", tc.value, label, server, tc.label, tc.server)
+		}
+	}
+}
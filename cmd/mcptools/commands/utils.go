@@ -5,16 +5,25 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/f/mcptools/pkg/alias"
+	mcpclient "github.com/f/mcptools/pkg/client"
 	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/itchyny/gojq"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,8 +32,14 @@ import (
 )
 
 // CloseWithTimeout attempts to close the MCP client with a timeout.
-// It waits up to 1 second for graceful shutdown, then kills any child processes.
+// It waits up to 2 seconds for graceful shutdown, then kills any child processes.
 func CloseWithTimeout(c *client.Client) {
+	liveClientsMu.Lock()
+	delete(liveClients, c)
+	liveClientsMu.Unlock()
+	serverKeys.Delete(c)
+	initializeResults.Delete(c)
+
 	done := make(chan struct{})
 	go func() {
 		_ = c.Close()
@@ -34,12 +49,63 @@ func CloseWithTimeout(c *client.Client) {
 	select {
 	case <-done:
 		// Closed successfully within timeout
-	case <-time.After(1 * time.Second):
+	case <-time.After(2 * time.Second):
 		// Timeout - kill entire process tree to prevent lingering subprocesses
 		killDescendantProcesses()
 	}
 }
 
+// liveClients are the clients closeOnSignal closes when the process is interrupted or
+// terminated. CloseWithTimeout removes a client once it is closed.
+var (
+	liveClientsMu     sync.Mutex
+	liveClients       = make(map[*client.Client]struct{})
+	signalHandlerOnce sync.Once
+)
+
+// closeOnSignal closes the client and exits when the process is interrupted or terminated,
+// so servers started by the client don't outlive it. A single handler is installed for the
+// process, which closes every client that is still open.
+func closeOnSignal(c *client.Client) {
+	liveClientsMu.Lock()
+	liveClients[c] = struct{}{}
+	liveClientsMu.Unlock()
+
+	signalHandlerOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		go func() {
+			sig := <-signals
+			closeLiveClients()
+			if sig == os.Interrupt {
+				os.Exit(130)
+			}
+			os.Exit(143)
+		}()
+	})
+}
+
+// closeLiveClients closes every client registered with closeOnSignal concurrently.
+func closeLiveClients() {
+	liveClientsMu.Lock()
+	clients := make([]*client.Client, 0, len(liveClients))
+	for c := range liveClients {
+		clients = append(clients, c)
+	}
+	liveClientsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *client.Client) {
+			defer wg.Done()
+			CloseWithTimeout(c)
+		}(c)
+	}
+	wg.Wait()
+}
+
 // killDescendantProcesses finds and kills all descendant processes of the current process.
 // It recursively finds children, grandchildren, etc. and kills them with SIGKILL.
 func killDescendantProcesses() {
@@ -83,6 +149,21 @@ var (
 	ErrCommandRequired = fmt.Errorf("command to execute is required when using stdio transport")
 )
 
+// invalidArgsError is returned by CreateClientFunc for arguments and flags that are found to
+// be invalid before connecting to the server, such as a malformed --header or --env, an
+// unsupported --protocol-version or an alias that can't be resolved.
+type invalidArgsError struct {
+	err error
+}
+
+func (e *invalidArgsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidArgsError) Unwrap() error {
+	return e.err
+}
+
 // IsHTTP returns true if the string is a valid HTTP URL.
 func IsHTTP(str string) bool {
 	return strings.HasPrefix(str, "http://") || strings.HasPrefix(str, "https://") || strings.HasPrefix(str, "localhost:")
@@ -148,68 +229,119 @@ func buildAuthHeader(originalURL string) (string, string, error) {
 // CreateClientFunc is the function used to create MCP clients.
 // This can be replaced in tests to use a mock transport.
 var CreateClientFunc = func(args []string, _ ...client.ClientOption) (*client.Client, error) {
-	if len(args) == 0 {
-		return nil, ErrCommandRequired
+	args, target, err := resolveServerArgs(args)
+	if err != nil {
+		return nil, err
 	}
+	return createClient(args, target)
+}
 
-	// Check if the first argument is an alias
+// resolveServerArgs resolves the server arguments of a command without connecting to the
+// server. A single argument may be an alias, or a server of the project's .mcp.json. Without
+// arguments, the only server of the project's .mcp.json is used. target is set when the
+// server is defined by a config file.
+func resolveServerArgs(args []string) ([]string, *serverTarget, error) {
+	var target *serverTarget
 	if len(args) == 1 {
 		server, found := alias.GetServerCommand(args[0])
-		if found {
+		switch {
+		case found && isConfigRef(server):
+			resolved, refErr := resolveConfigRef(server)
+			if refErr != nil {
+				return nil, nil, &invalidArgsError{err: fmt.Errorf("alias %s: %w", args[0], refErr)}
+			}
+			target = &resolved
+		case found:
 			args = ParseCommandString(server)
+		case !IsHTTP(args[0]):
+			resolved, projectErr := resolveProjectServer(args[0])
+			if projectErr != nil {
+				return nil, nil, &invalidArgsError{err: projectErr}
+			}
+			target = resolved
+		}
+	} else if len(args) == 0 {
+		resolved, projectErr := resolveProjectServer("")
+		if projectErr != nil {
+			return nil, nil, &invalidArgsError{err: projectErr}
 		}
+		if resolved == nil {
+			return nil, nil, &invalidArgsError{err: ErrCommandRequired}
+		}
+		target = resolved
 	}
+	return args, target, nil
+}
 
-	var c *client.Client
-	var err error
-
-	if len(args) == 1 && IsHTTP(args[0]) {
-		// Validate transport option for HTTP URLs
-		if TransportOption != "http" && TransportOption != "sse" {
-			return nil, fmt.Errorf("invalid transport option: %s (supported: http, sse)", TransportOption)
-		}
+// createTargetClientFunc creates a client for the server described by a config entry, without
+// touching the transport, header and env flags. Tests replace it along with CreateClientFunc.
+var createTargetClientFunc = func(target serverTarget) (*client.Client, error) {
+	return createClient(target.args, &target)
+}
 
-		// Build authentication header
-		authHeader, cleanURL, authErr := buildAuthHeader(args[0])
-		if authErr != nil {
-			return nil, fmt.Errorf("failed to parse authentication: %w", authErr)
-		}
+// createClient starts and initializes a client for the server started by args, or for target
+// when the server is defined by a config file.
+func createClient(args []string, target *serverTarget) (*client.Client, error) {
+	if ProtocolVersionOption != "" && !slices.Contains(supportedProtocolVersions, ProtocolVersionOption) {
+		return nil, &invalidArgsError{err: fmt.Errorf("unsupported protocol version: %s (supported: %s)",
+			ProtocolVersionOption, strings.Join(supportedProtocolVersions, ", "))}
+	}
+	initRequest, err := newInitializeRequest()
+	if err != nil {
+		return nil, &invalidArgsError{err: err}
+	}
+	roots, err := resolveRoots(RootOptions)
+	if err != nil {
+		return nil, &invalidArgsError{err: err}
+	}
 
-		// Create headers map if authentication is provided
-		headers := make(map[string]string)
-		if authHeader != "" {
-			headers["Authorization"] = authHeader
-		}
+	conn, err := resolveConnection(args, target)
+	if err != nil {
+		return nil, err
+	}
 
-		if TransportOption == "sse" {
-			// For SSE transport, use transport.ClientOption
-			if len(headers) > 0 {
-				c, err = client.NewSSEMCPClient(cleanURL, transport.WithHeaders(headers))
-			} else {
-				c, err = client.NewSSEMCPClient(cleanURL)
-			}
-		} else {
-			// For StreamableHTTP transport, use transport.StreamableHTTPCOption
-			if len(headers) > 0 {
-				c, err = client.NewStreamableHttpClient(cleanURL, transport.WithHTTPHeaders(headers))
-			} else {
-				c, err = client.NewStreamableHttpClient(cleanURL)
-			}
+	var c *client.Client
+	switch {
+	case conn.url != "" && conn.transport == "sse":
+		// For SSE transport, use the reconnecting transport so dropped streams are restored
+		sseTransport, sseErr := mcpclient.NewSSE(conn.url, mcpclient.SSEOptions{
+			Headers:    conn.headers,
+			MaxRetries: MaxRetries,
+		})
+		if sseErr != nil {
+			return nil, fmt.Errorf("failed to create SSE transport: %w", sseErr)
 		}
-
-		if err != nil {
-			return nil, err
+		c, err = newClient(sseTransport, conn.headers)
+	case conn.url != "":
+		// For StreamableHTTP transport, header values are resolved on every request
+		httpTransport, httpErr := mcpclient.NewStreamableHTTP(conn.url, mcpclient.HTTPOptions{
+			Headers: conn.headers,
+		})
+		if httpErr != nil {
+			return nil, fmt.Errorf("failed to create HTTP transport: %w", httpErr)
 		}
-		err = c.Start(context.Background())
-	} else {
-		c, err = client.NewStdioMCPClient(args[0], nil, args[1:]...)
+		c, err = newClient(httpTransport, conn.headers)
+	default:
+		// The stdio transport runs the server in its own process group so Close can stop it
+		c, err = newClient(mcpclient.NewStdio(conn.args[0], conn.args[1:], mcpclient.StdioOptions{Env: conn.env}), nil)
 	}
-
 	if err != nil {
 		return nil, err
 	}
+	if err := c.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// Interrupting the process would otherwise leave the server running
+	closeOnSignal(c)
 
-	stdErr, ok := client.GetStderr(c)
+	// Servers ask for the roots with roots/list, which the transport has to be able to answer
+	if len(roots) > 0 && !mcpclient.SetRequestHandler(c, rootsRequestHandler(roots)) {
+		CloseWithTimeout(c)
+		return nil, &invalidArgsError{err: fmt.Errorf("%s needs a transport that can answer %s requests", FlagRoot, methodListRoots)}
+	}
+
+	stdErr, ok := mcpclient.GetStderr(c)
 	if ok && ShowServerLogs {
 		go func() {
 			scanner := bufio.NewScanner(stdErr)
@@ -222,29 +354,275 @@ var CreateClientFunc = func(args []string, _ ...client.ClientOption) (*client.Cl
 	done := make(chan error, 1)
 
 	go func() {
-		initRequest := mcp.InitializeRequest{}
-		initRequest.Params.ProtocolVersion = "2024-11-05"
-		initRequest.Params.Capabilities = mcp.ClientCapabilities{}
-		initRequest.Params.ClientInfo = mcp.Implementation{
-			Name:    "mcptools",
-			Version: "1.0.0",
-		}
-		_, err := c.Initialize(context.Background(), initRequest)
+		result, err := c.Initialize(context.Background(), initRequest)
+		if err == nil {
+			err = negotiateProtocolVersion(result.ProtocolVersion)
+		}
+		if err == nil {
+			rememberInitializeResult(c, result)
+		}
 		done <- err
 	}()
 
 	select {
 	case err := <-done:
 		if err != nil {
+			CloseWithTimeout(c)
 			return nil, fmt.Errorf("init error: %w", err)
 		}
-	case <-time.After(10 * time.Second):
-		return nil, fmt.Errorf("initialization timed out")
+	case <-time.After(ConnectTimeout):
+		CloseWithTimeout(c)
+		return nil, fmt.Errorf("initialization timed out after %s (use %s to wait longer)", ConnectTimeout, FlagConnectTimeout)
 	}
 
+	serverKeys.Store(c, conn.key)
 	return c, nil
 }
 
+// connection is what a client connects to once aliases and config files are resolved.
+type connection struct {
+	// url is set for HTTP and SSE servers, and args for stdio servers.
+	url       string
+	args      []string
+	transport string
+	headers   map[string]string
+	env       []string
+	key       []string
+}
+
+// resolveConnection applies the transport, header and env flags to the server started by
+// args, or to target when the server is defined by a config file. It doesn't connect to the
+// server, so it can be used to find the server's key without starting it.
+func resolveConnection(args []string, target *serverTarget) (connection, error) {
+	transportOption := TransportOption
+	headerOptions := HeaderOptions
+	envOptions := ServerEnvOptions
+	if target != nil {
+		// The server is defined by a config file; flags take precedence over its environment
+		// and headers
+		args = target.args
+		envOptions = append(target.env, envOptions...)
+		headerOptions = append(target.headers, headerOptions...)
+		if target.transport != "" {
+			transportOption = target.transport
+		}
+	}
+	if len(args) == 0 {
+		return connection{}, &invalidArgsError{err: ErrCommandRequired}
+	}
+
+	if len(args) == 1 && IsHTTP(args[0]) {
+		// Validate transport option for HTTP URLs
+		if transportOption != "http" && transportOption != "sse" {
+			return connection{}, &invalidArgsError{err: fmt.Errorf("invalid transport option: %s (supported: http, sse)", transportOption)}
+		}
+
+		// Build authentication header
+		authHeader, cleanURL, authErr := buildAuthHeader(args[0])
+		if authErr != nil {
+			return connection{}, &invalidArgsError{err: fmt.Errorf("failed to parse authentication: %w", authErr)}
+		}
+
+		// Create headers map if authentication is provided
+		headers := make(map[string]string)
+		if authHeader != "" {
+			headers["Authorization"] = authHeader
+		}
+
+		// Explicit --header values take precedence over the auth flags
+		extraHeaders, headerErr := parseHeaders(headerOptions)
+		if headerErr != nil {
+			return connection{}, &invalidArgsError{err: headerErr}
+		}
+		for k, v := range extraHeaders {
+			headers[k] = v
+		}
+		return connection{
+			url:       cleanURL,
+			transport: transportOption,
+			headers:   headers,
+			key:       newServerKey(transportOption, []string{cleanURL}, nil, headers),
+		}, nil
+	}
+
+	env, envErr := parseServerEnv(envOptions)
+	if envErr != nil {
+		return connection{}, &invalidArgsError{err: envErr}
+	}
+	return connection{
+		args:      args,
+		transport: "stdio",
+		env:       env,
+		key:       newServerKey("stdio", args, env, nil),
+	}, nil
+}
+
+// serverKeys holds the key of the server each client created by CreateClientFunc is connected
+// to, until CloseWithTimeout closes it.
+var serverKeys sync.Map
+
+// newServerKey identifies a server by what the client connects to once aliases and config
+// files are resolved: the transport, the command and its arguments or the URL, and the
+// environment and headers it is given. Servers with the same key are assumed to have the same
+// tools.
+func newServerKey(transport string, args, env []string, headers map[string]string) []string {
+	key := []string{"transport:" + transport}
+	for _, arg := range args {
+		key = append(key, "arg:"+arg)
+	}
+	env = slices.Clone(env)
+	slices.Sort(env)
+	for _, value := range env {
+		key = append(key, "env:"+value)
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		key = append(key, "header:"+name+": "+headers[name])
+	}
+	return key
+}
+
+// serverKey returns the key of the server c is connected to, or false if c wasn't created by
+// CreateClientFunc.
+func serverKey(c *client.Client) ([]string, bool) {
+	key, ok := serverKeys.Load(c)
+	if !ok {
+		return nil, false
+	}
+	return key.([]string), true
+}
+
+// defaultConnectTimeout is how long the initialize handshake may take unless --connect-timeout
+// is given.
+const defaultConnectTimeout = 10 * time.Second
+
+// setConnectTimeout sets how long the initialize handshake may take, exiting if value isn't a
+// positive duration.
+func setConnectTimeout(value string) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s must be a duration such as 30s or 2m\n", FlagConnectTimeout)
+		os.Exit(1)
+	}
+	ConnectTimeout = timeout
+}
+
+// supportedProtocolVersions are the MCP protocol versions the client can speak, newest first.
+var supportedProtocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// newInitializeRequest creates the initialize request sent to servers. It asks for the
+// version given with --protocol-version, or else the newest supported one. The params given
+// with --init-params are merged over the defaults, then --client-name, --client-version and
+// --capability are applied. The roots capability is declared when --root is given.
+func newInitializeRequest() (mcp.InitializeRequest, error) {
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = supportedProtocolVersions[0]
+	if ProtocolVersionOption != "" {
+		initRequest.Params.ProtocolVersion = ProtocolVersionOption
+	}
+	initRequest.Params.Capabilities = mcp.ClientCapabilities{}
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcptools",
+		Version: "1.0.0",
+	}
+
+	if InitParamsOption != "" {
+		if err := json.Unmarshal([]byte(InitParamsOption), &initRequest.Params); err != nil {
+			return initRequest, fmt.Errorf("invalid %s: %w", FlagInitParams, err)
+		}
+	}
+	if ClientNameOption != "" {
+		initRequest.Params.ClientInfo.Name = ClientNameOption
+	}
+	if ClientVersionOption != "" {
+		initRequest.Params.ClientInfo.Version = ClientVersionOption
+	}
+	if len(RootOptions) > 0 && initRequest.Params.Capabilities.Roots == nil {
+		if err := declareCapability(&initRequest.Params.Capabilities, "roots"); err != nil {
+			return initRequest, err
+		}
+	}
+	for _, capability := range CapabilityOptions {
+		if err := declareCapability(&initRequest.Params.Capabilities, capability); err != nil {
+			return initRequest, err
+		}
+	}
+	return initRequest, nil
+}
+
+// declareCapability adds a client capability given with --capability to capabilities.
+func declareCapability(capabilities *mcp.ClientCapabilities, capability string) error {
+	switch {
+	case capability == "sampling":
+		capabilities.Sampling = &struct{}{}
+	case capability == "roots" || capability == "roots.listChanged":
+		if capabilities.Roots == nil {
+			capabilities.Roots = &struct {
+				ListChanged bool `json:"listChanged,omitempty"`
+			}{}
+		}
+		if capability == "roots.listChanged" {
+			capabilities.Roots.ListChanged = true
+		}
+	case strings.HasPrefix(capability, "experimental.") && capability != "experimental.":
+		if capabilities.Experimental == nil {
+			capabilities.Experimental = make(map[string]any)
+		}
+		capabilities.Experimental[strings.TrimPrefix(capability, "experimental.")] = map[string]any{}
+	default:
+		return fmt.Errorf("unknown capability %q (supported: sampling, roots, roots.listChanged, experimental.<name>)", capability)
+	}
+	return nil
+}
+
+// negotiateProtocolVersion checks the protocol version a server chose in its initialize
+// response. Servers may answer with another version than the one requested; the client adopts
+// it if it is supported, and warns when it differs from an explicit --protocol-version.
+func negotiateProtocolVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if !slices.Contains(supportedProtocolVersions, version) {
+		return fmt.Errorf("server uses protocol version %s, which is not supported (supported: %s)",
+			version, strings.Join(supportedProtocolVersions, ", "))
+	}
+	if ProtocolVersionOption != "" && version != ProtocolVersionOption {
+		fmt.Fprintf(os.Stderr, "Warning: requested protocol version %s, but the server uses %s\n", ProtocolVersionOption, version)
+	}
+	return nil
+}
+
+// initializeResults holds the initialize result of each client created by CreateClientFunc,
+// until CloseWithTimeout closes it.
+var initializeResults sync.Map
+
+// rememberInitializeResult stores the initialize result of c. The response is kept as the
+// server sent it when the client records its responses, so that capabilities mcp-go doesn't
+// model are included.
+func rememberInitializeResult(c *client.Client, result *mcp.InitializeResult) {
+	info := ConvertJSONToMap(result)
+	if recording, ok := mcpclient.GetRecording(c); ok {
+		var raw map[string]any
+		if err := json.Unmarshal(recording.LastResult(), &raw); err == nil {
+			info = raw
+		}
+	}
+	initializeResults.Store(c, info)
+}
+
+// initializeResult returns the initialize result the server sent to c.
+func initializeResult(c *client.Client) (map[string]any, bool) {
+	info, ok := initializeResults.Load(c)
+	if !ok {
+		return nil, false
+	}
+	return info.(map[string]any), true
+}
+
 // ProcessFlags processes command line flags, sets the format option, and returns the remaining
 // arguments. Supported format options: json, pretty, and table.
 // Supported transport options: http and sse.
@@ -273,6 +651,88 @@ func ProcessFlags(args []string) []string {
 		case args[i] == FlagAuthHeader && i+1 < len(args):
 			AuthHeader = args[i+1]
 			i += 2
+		case args[i] == FlagHeader && i+1 < len(args):
+			HeaderOptions = append(HeaderOptions, args[i+1])
+			i += 2
+		case args[i] == FlagEnv && i+1 < len(args):
+			ServerEnvOptions = append(ServerEnvOptions, args[i+1])
+			i += 2
+		case args[i] == FlagMaxRetries && i+1 < len(args):
+			setMaxRetries(args[i+1])
+			i += 2
+		case args[i] == FlagLogFile && i+1 < len(args):
+			LogFileOption = args[i+1]
+			i += 2
+		case args[i] == FlagVerboseRPC:
+			VerboseRPC = true
+			i++
+		case args[i] == FlagNoPrettyEmbedded:
+			NoPrettyEmbedded = true
+			i++
+		case args[i] == FlagSaveImages && i+1 < len(args):
+			SaveImagesDir = args[i+1]
+			i += 2
+		case args[i] == FlagFirstPageOnly:
+			FirstPageOnly = true
+			i++
+		case args[i] == FlagCount:
+			CountOption = true
+			i++
+		case args[i] == FlagFilter && i+1 < len(args):
+			setFilterOption(args[i+1])
+			i += 2
+		case args[i] == FlagSearch && i+1 < len(args):
+			SearchOption = args[i+1]
+			i += 2
+		case args[i] == FlagSort && i+1 < len(args):
+			setSortOption(args[i+1])
+			i += 2
+		case args[i] == FlagReverse:
+			ReverseOption = true
+			i++
+		case args[i] == FlagRaw:
+			// call, read-resource and get-prompt take --raw before calling ProcessFlags
+			fmt.Fprintf(os.Stderr, "Error: %s is only supported by call, read-resource and get-prompt\n", FlagRaw)
+			os.Exit(1)
+		case args[i] == FlagNoColor:
+			setColorOption(string(jsonutils.ColorNever))
+			i++
+		case args[i] == FlagColor && i+1 < len(args):
+			setColorOption(args[i+1])
+			i += 2
+		case strings.HasPrefix(args[i], FlagColor+"="):
+			setColorOption(strings.TrimPrefix(args[i], FlagColor+"="))
+			i++
+		case args[i] == FlagProtocolVersion && i+1 < len(args):
+			ProtocolVersionOption = args[i+1]
+			i += 2
+		case args[i] == FlagInitParams && i+1 < len(args):
+			InitParamsOption = args[i+1]
+			i += 2
+		case args[i] == FlagClientName && i+1 < len(args):
+			ClientNameOption = args[i+1]
+			i += 2
+		case args[i] == FlagClientVersion && i+1 < len(args):
+			ClientVersionOption = args[i+1]
+			i += 2
+		case args[i] == FlagCapability && i+1 < len(args):
+			CapabilityOptions = append(CapabilityOptions, args[i+1])
+			i += 2
+		case args[i] == FlagRoot && i+1 < len(args):
+			RootOptions = append(RootOptions, args[i+1])
+			i += 2
+		case args[i] == FlagConnectTimeout && i+1 < len(args):
+			setConnectTimeout(args[i+1])
+			i += 2
+		case args[i] == FlagWidth && i+1 < len(args):
+			setWidthOption(args[i+1])
+			i += 2
+		case args[i] == FlagIndent && i+1 < len(args):
+			setIndentOption(args[i+1])
+			i += 2
+		case args[i] == FlagCompact:
+			CompactOption = true
+			i++
 		default:
 			parsedArgs = append(parsedArgs, args[i])
 			i++
@@ -282,22 +742,367 @@ func ProcessFlags(args []string) []string {
 	return parsedArgs
 }
 
+// newClient creates a client for t, logging its JSON-RPC traffic when --log-file or
+// --verbose-rpc is set. Header values are redacted from the log, and the log file is closed
+// when the client is. The results the server sends are recorded so that they can be printed
+// verbatim with --raw.
+func newClient(t transport.Interface, headers map[string]string) (*client.Client, error) {
+	t = mcpclient.NewRecording(t)
+
+	var outputs []io.Writer
+	var logFile *os.File
+	if VerboseRPC {
+		outputs = append(outputs, os.Stderr)
+	}
+	if LogFileOption != "" {
+		var err error
+		logFile, err = os.OpenFile(LogFileOption, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // Path is provided by the user
+		if err != nil {
+			return nil, fmt.Errorf("error opening log file: %w", err)
+		}
+		outputs = append(outputs, logFile)
+	}
+
+	if len(outputs) == 0 {
+		return client.NewClient(t), nil
+	}
+
+	secrets := make([]string, 0, len(headers))
+	for _, value := range headers {
+		secrets = append(secrets, value)
+	}
+	options := mcpclient.LoggingOptions{
+		Output:  io.MultiWriter(outputs...),
+		Secrets: secrets,
+	}
+	// The log file is closed with the client's transport
+	if logFile != nil {
+		options.Closer = logFile
+	}
+	return client.NewClient(mcpclient.NewLogging(t, options)), nil
+}
+
+// parseHeaders parses "Key: Value" header flags into a map.
+func parseHeaders(values []string) (map[string]string, error) {
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		key, headerValue, found := strings.Cut(value, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Key: Value\"", value)
+		}
+		headers[key] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// parseServerEnv validates "KEY=VALUE" env flags for a stdio server.
+func parseServerEnv(values []string) ([]string, error) {
+	env := make([]string, 0, len(values))
+	for _, value := range values {
+		key, _, found := strings.Cut(value, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid env %q, expected KEY=VALUE", value)
+		}
+		env = append(env, value)
+	}
+	return env, nil
+}
+
+// setMaxRetries sets MaxRetries from a flag value, exiting if value isn't a non-negative
+// integer.
+func setMaxRetries(value string) {
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s must be a non-negative integer\n", FlagMaxRetries)
+		os.Exit(1)
+	}
+	MaxRetries = retries
+}
+
 // FormatAndPrintResponse formats and prints an MCP response in the format specified by
-// FormatOption.
+// FormatOption, indented as specified by IndentOption and CompactOption.
 func FormatAndPrintResponse(cmd *cobra.Command, resp any, err error) error {
 	if err != nil {
 		return fmt.Errorf("error: %w", err)
 	}
 
-	output, err := jsonutils.Format(resp, FormatOption)
-	if err != nil {
+	jsonutils.PrettyEmbeddedJSON = !NoPrettyEmbedded
+	jsonutils.ImageDir = SaveImagesDir
+	// The output is streamed rather than built up first, so that large listings piped into
+	// commands such as head don't have to be held in memory
+	out := bufio.NewWriter(cmd.OutOrStdout())
+	if err := jsonutils.FormatTo(out, resp, formatOptions()); err != nil {
+		_ = out.Flush()
 		return fmt.Errorf("error formatting output: %w", err)
 	}
+	return out.Flush()
+}
+
+// setColorOption sets when the formatters colorize output, exiting if mode isn't a valid
+// color mode.
+func setColorOption(mode string) {
+	colorMode, err := jsonutils.ParseColorMode(mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", FlagColor, err)
+		os.Exit(1)
+	}
+	ColorOption = string(colorMode)
+	jsonutils.SetColorMode(colorMode)
+}
+
+// setWidthOption sets the width table output is wrapped to, exiting if value isn't a positive
+// integer.
+func setWidthOption(value string) {
+	width, err := strconv.Atoi(value)
+	if err != nil || width < 1 {
+		fmt.Fprintf(os.Stderr, "Error: %s must be a positive integer\n", FlagWidth)
+		os.Exit(1)
+	}
+	WidthOption = width
+	jsonutils.SetWidth(width)
+}
+
+// setIndentOption sets the number of spaces JSON output is indented with, exiting if value
+// isn't a positive integer.
+func setIndentOption(value string) {
+	indent, err := strconv.Atoi(value)
+	if err != nil || indent < 1 {
+		fmt.Fprintf(os.Stderr, "Error: %s must be a positive integer\n", FlagIndent)
+		os.Exit(1)
+	}
+	IndentOption = indent
+}
 
-	fmt.Fprintln(cmd.OutOrStdout(), output)
+// formatOptions returns the options output is formatted with.
+func formatOptions() jsonutils.FormatOptions {
+	return jsonutils.FormatOptions{
+		Format:  FormatOption,
+		Indent:  IndentOption,
+		Compact: CompactOption,
+	}
+}
+
+// addRawFlag registers --raw on cmd, which must handle it itself since it is only supported
+// by the commands that print a single result.
+func addRawFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolVar(&RawOutput, "raw", false, "Print the server's result verbatim as compact JSON, bypassing --format")
+	return cmd
+}
+
+// printRawResult prints the result of the last response mcpClient received exactly as the
+// server sent it. Clients without a recording transport print resp as compact JSON instead.
+func printRawResult(cmd *cobra.Command, mcpClient *client.Client, resp any) error {
+	if recording, ok := mcpclient.GetRecording(mcpClient); ok && recording.LastResult() != nil {
+		fmt.Fprintln(cmd.OutOrStdout(), string(recording.LastResult()))
+		return nil
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("error encoding output: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
 	return nil
 }
 
+// Listing orders.
+const (
+	sortName = "name"
+	sortNone = "none"
+)
+
+// setFilterOption sets the glob listings are filtered with, exiting if it isn't a valid
+// pattern.
+func setFilterOption(pattern string) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: invalid pattern %q\n", FlagFilter, pattern)
+		os.Exit(1)
+	}
+	FilterOption = pattern
+}
+
+// filterEntities returns the tools, resources or prompts that match --filter and --search.
+// The glob is matched against the name, and for resources also the URI, while the search
+// text is looked for in the name and description.
+func filterEntities(items []any) []any {
+	if FilterOption == "" && SearchOption == "" {
+		return items
+	}
+
+	search := strings.ToLower(SearchOption)
+	filtered := []any{}
+	for _, item := range items {
+		entity, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := entity["name"].(string)
+		uri, _ := entity["uri"].(string)
+		description, _ := entity["description"].(string)
+
+		if FilterOption != "" {
+			nameMatched, _ := filepath.Match(FilterOption, name)
+			uriMatched := false
+			if uri != "" {
+				uriMatched, _ = filepath.Match(FilterOption, uri)
+			}
+			if !nameMatched && !uriMatched {
+				continue
+			}
+		}
+		if search != "" && !strings.Contains(strings.ToLower(name), search) &&
+			!strings.Contains(strings.ToLower(description), search) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
+// setSortOption sets the order listings are printed in, exiting if it isn't a valid order.
+func setSortOption(order string) {
+	if order != sortName && order != sortNone {
+		fmt.Fprintf(os.Stderr, "Error: %s must be %s or %s\n", FlagSort, sortName, sortNone)
+		os.Exit(1)
+	}
+	SortOption = order
+}
+
+// sortEntities orders tools, resources or prompts as set by --sort and --reverse. Sorting by
+// name breaks ties by URI, so that resources with the same name keep a stable order.
+func sortEntities(items []any) []any {
+	if SortOption != sortName && !ReverseOption {
+		return items
+	}
+
+	sorted := slices.Clone(items)
+	if SortOption == sortName {
+		key := func(item any) (string, string) {
+			entity, _ := item.(map[string]any)
+			name, _ := entity["name"].(string)
+			uri, _ := entity["uri"].(string)
+			return name, uri
+		}
+		slices.SortStableFunc(sorted, func(a, b any) int {
+			nameA, uriA := key(a)
+			nameB, uriB := key(b)
+			if c := strings.Compare(nameA, nameB); c != 0 {
+				return c
+			}
+			return strings.Compare(uriA, uriB)
+		})
+	}
+	if ReverseOption {
+		slices.Reverse(sorted)
+	}
+	return sorted
+}
+
+// printEntityList prints the tools, resources, prompts or resource templates listed under key.
+// With --count only their number is printed. In table format the number is also written to
+// stderr after the listing, where it doesn't end up in piped output.
+func printEntityList(cmd *cobra.Command, key, noun string, entities []any, listErr error) error {
+	if CountOption && listErr == nil {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), len(entities))
+		return err
+	}
+	if err := FormatAndPrintResponse(cmd, map[string]any{key: entities}, listErr); err != nil {
+		return err
+	}
+	if jsonutils.ParseFormat(FormatOption) == jsonutils.FormatTable {
+		fmt.Fprintln(cmd.ErrOrStderr(), countLabel(len(entities), noun))
+	}
+	return nil
+}
+
+// countLabel returns a count followed by noun, pluralized unless the count is one.
+func countLabel(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// warnMorePages tells the user that a listing was cut short by --first-page-only.
+func warnMorePages(entities string, nextCursor mcp.Cursor) {
+	if nextCursor != "" {
+		fmt.Fprintf(os.Stderr, "More %s are available; run without %s to list them all\n", entities, FlagFirstPageOnly)
+	}
+}
+
+// toolResultError returns an error holding the text content of a tool result that has the
+// MCP isError flag set, and nil for any other result.
+func toolResultError(resp map[string]any) error {
+	if isError, _ := resp["isError"].(bool); !isError {
+		return nil
+	}
+
+	var texts []string
+	content, _ := resp["content"].([]any)
+	for _, item := range content {
+		if contentItem, ok := item.(map[string]any); ok && contentItem["type"] == "text" {
+			if text, _ := contentItem["text"].(string); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return errors.New("the tool reported an error")
+	}
+	return errors.New(strings.Join(texts, "\n"))
+}
+
+// parseQueryFlag compiles the jq expression given with --query, exiting on an invalid one so
+// that it is reported before the server is contacted.
+func parseQueryFlag(expr string) *gojq.Code {
+	parsed, err := gojq.Parse(expr)
+	if err == nil {
+		var code *gojq.Code
+		if code, err = gojq.Compile(parsed); err == nil {
+			return code
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Error: invalid %s expression: %v\n", FlagQuery, err)
+	os.Exit(1)
+	return nil
+}
+
+// applyQuery runs a --query expression on a response. A single output is returned as is and
+// any other number of outputs as an array.
+func applyQuery(query *gojq.Code, resp map[string]any) (any, error) {
+	// gojq only accepts the types encoding/json decodes into, so the response is normalized
+	// by a JSON round trip first.
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	outputs := []any{}
+	iter := query.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := v.(error); isErr {
+			var haltErr *gojq.HaltError
+			if errors.As(err, &haltErr) && haltErr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		outputs = append(outputs, v)
+	}
+	if len(outputs) == 1 {
+		return outputs[0], nil
+	}
+	return outputs, nil
+}
+
 // IsValidFormat returns true if the format is valid.
 func IsValidFormat(format string) bool {
 	return format == "json" || format == "j" ||
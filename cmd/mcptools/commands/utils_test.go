@@ -3,10 +3,16 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/mark3labs/mcp-go/client"
 	"github.com/spf13/cobra"
 )
 
@@ -92,6 +98,299 @@ func TestProcessFlags(t *testing.T) {
 	}
 }
 
+func TestParseHeaders(t *testing.T) {
+	originalHeaders := HeaderOptions
+	defer func() { HeaderOptions = originalHeaders }()
+
+	HeaderOptions = nil
+	gotArgs := ProcessFlags([]string{"--header", "Authorization: Bearer abc", "https://example.com/mcp", "--header", "X-Trace:1"})
+	if !reflect.DeepEqual(gotArgs, []string{"https://example.com/mcp"}) {
+		t.Errorf("ProcessFlags() gotArgs = %v", gotArgs)
+	}
+	if !reflect.DeepEqual(HeaderOptions, []string{"Authorization: Bearer abc", "X-Trace:1"}) {
+		t.Errorf("ProcessFlags() HeaderOptions = %v", HeaderOptions)
+	}
+
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "key and value",
+			values: []string{"Authorization: Bearer abc"},
+			want:   map[string]string{"Authorization": "Bearer abc"},
+		},
+		{
+			name:   "value containing colons",
+			values: []string{"X-Url: http://example.com:8080", "X-Empty:"},
+			want:   map[string]string{"X-Url": "http://example.com:8080", "X-Empty": ""},
+		},
+		{
+			name:    "missing colon",
+			values:  []string{"Authorization"},
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			values:  []string{": value"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHeaders(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFlagsColor(t *testing.T) {
+	originalColor := ColorOption
+	defer func() {
+		ColorOption = originalColor
+		jsonutils.SetColorMode(jsonutils.ColorAuto)
+	}()
+
+	tests := []struct {
+		args      []string
+		wantColor string
+	}{
+		{[]string{"--no-color", "server"}, "never"},
+		{[]string{"--color=always", "server"}, "always"},
+		{[]string{"--color", "never", "server"}, "never"},
+		{[]string{"--color=Auto", "server"}, "auto"},
+	}
+
+	for _, tt := range tests {
+		ColorOption = "auto"
+		gotArgs := ProcessFlags(tt.args)
+		if !reflect.DeepEqual(gotArgs, []string{"server"}) {
+			t.Errorf("ProcessFlags(%v) gotArgs = %v", tt.args, gotArgs)
+		}
+		if ColorOption != tt.wantColor {
+			t.Errorf("ProcessFlags(%v) ColorOption = %q, want %q", tt.args, ColorOption, tt.wantColor)
+		}
+	}
+}
+
+func TestProcessFlagsWidth(t *testing.T) {
+	originalWidth := WidthOption
+	defer func() {
+		WidthOption = originalWidth
+		jsonutils.SetWidth(0)
+	}()
+
+	gotArgs := ProcessFlags([]string{"--width", "160", "server"})
+	if !reflect.DeepEqual(gotArgs, []string{"server"}) {
+		t.Errorf("ProcessFlags() gotArgs = %v", gotArgs)
+	}
+	if WidthOption != 160 {
+		t.Errorf("ProcessFlags() WidthOption = %d, want 160", WidthOption)
+	}
+}
+
+func TestFilterEntities(t *testing.T) {
+	origFilter, origSearch := FilterOption, SearchOption
+	defer func() { FilterOption, SearchOption = origFilter, origSearch }()
+
+	items := []any{
+		map[string]any{"name": "read_file", "description": "Read a file"},
+		map[string]any{"name": "read_dir", "description": "List a directory"},
+		map[string]any{"name": "write_file", "description": "Write a FILE"},
+		map[string]any{"name": "readme", "uri": "docs://readme.md"},
+	}
+
+	testCases := []struct {
+		filter   string
+		search   string
+		expected []string
+	}{
+		{filter: "", search: "", expected: []string{"read_file", "read_dir", "write_file", "readme"}},
+		{filter: "read_*", expected: []string{"read_file", "read_dir"}},
+		{filter: "*_file", expected: []string{"read_file", "write_file"}},
+		{filter: "docs://*.md", expected: []string{"readme"}},
+		{search: "file", expected: []string{"read_file", "write_file"}},
+		{filter: "read_*", search: "directory", expected: []string{"read_dir"}},
+		{filter: "delete_*", expected: []string{}},
+	}
+	for _, tc := range testCases {
+		FilterOption, SearchOption = tc.filter, tc.search
+		names := []string{}
+		for _, item := range filterEntities(items) {
+			names = append(names, item.(map[string]any)["name"].(string))
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("filterEntities() with filter %q and search %q = %v, want %v", tc.filter, tc.search, names, tc.expected)
+		}
+	}
+
+	gotArgs := ProcessFlags([]string{"--filter", "read_*", "--search", "dir", "server"})
+	if !reflect.DeepEqual(gotArgs, []string{"server"}) || FilterOption != "read_*" || SearchOption != "dir" {
+		t.Errorf("ProcessFlags() = %v with filter %q and search %q", gotArgs, FilterOption, SearchOption)
+	}
+}
+
+func TestSortEntities(t *testing.T) {
+	origSort, origReverse := SortOption, ReverseOption
+	defer func() { SortOption, ReverseOption = origSort, origReverse }()
+
+	items := []any{
+		map[string]any{"name": "write", "uri": "b://"},
+		map[string]any{"name": "read", "uri": "z://"},
+		map[string]any{"name": "delete"},
+		map[string]any{"name": "read", "uri": "a://"},
+	}
+
+	testCases := []struct {
+		sort     string
+		reverse  bool
+		expected []string
+	}{
+		{sort: sortNone, expected: []string{"write b://", "read z://", "delete ", "read a://"}},
+		{sort: sortName, expected: []string{"delete ", "read a://", "read z://", "write b://"}},
+		{sort: sortName, reverse: true, expected: []string{"write b://", "read z://", "read a://", "delete "}},
+		{sort: sortNone, reverse: true, expected: []string{"read a://", "delete ", "read z://", "write b://"}},
+	}
+	for _, tc := range testCases {
+		SortOption, ReverseOption = tc.sort, tc.reverse
+		keys := []string{}
+		for _, item := range sortEntities(items) {
+			entity := item.(map[string]any)
+			uri, _ := entity["uri"].(string)
+			keys = append(keys, entity["name"].(string)+" "+uri)
+		}
+		if !reflect.DeepEqual(keys, tc.expected) {
+			t.Errorf("sortEntities() with sort %q and reverse %v = %v, want %v", tc.sort, tc.reverse, keys, tc.expected)
+		}
+	}
+
+	// The listing the server returned is left in its order
+	if first := items[0].(map[string]any)["name"]; first != "write" {
+		t.Errorf("sortEntities() reordered its input, first item is %v", first)
+	}
+}
+
+func TestNewInitializeRequestClientOptions(t *testing.T) {
+	defer func() {
+		InitParamsOption, ClientNameOption, ClientVersionOption, CapabilityOptions = "", "", "", nil
+	}()
+
+	InitParamsOption = `{"clientInfo":{"name":"from-params","version":"9.9"},"capabilities":{"experimental":{"x":{}}}}`
+	ClientNameOption = "tester"
+	CapabilityOptions = []string{"sampling", "roots.listChanged"}
+	request, err := newInitializeRequest()
+	if err != nil {
+		t.Fatalf("newInitializeRequest() error = %v", err)
+	}
+
+	clientInfo := request.Params.ClientInfo
+	if clientInfo.Name != "tester" || clientInfo.Version != "9.9" {
+		t.Errorf("clientInfo = %+v, want tester 9.9", clientInfo)
+	}
+	capabilities := request.Params.Capabilities
+	if capabilities.Sampling == nil || capabilities.Roots == nil || !capabilities.Roots.ListChanged {
+		t.Errorf("capabilities = %+v, want sampling and roots with listChanged", capabilities)
+	}
+	if got := describeClientCapabilities(capabilities); got != "experimental.x, roots (listChanged), sampling" {
+		t.Errorf("describeClientCapabilities() = %q", got)
+	}
+
+	CapabilityOptions = []string{"telepathy"}
+	if _, err := newInitializeRequest(); err == nil {
+		t.Error("newInitializeRequest() expected an error for an unknown capability")
+	}
+	CapabilityOptions = nil
+	InitParamsOption = "not json"
+	if _, err := newInitializeRequest(); err == nil {
+		t.Error("newInitializeRequest() expected an error for invalid --init-params")
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	originalVersion := ProtocolVersionOption
+	defer func() { ProtocolVersionOption = originalVersion }()
+
+	ProtocolVersionOption = ""
+	if request, _ := newInitializeRequest(); request.Params.ProtocolVersion != supportedProtocolVersions[0] {
+		t.Errorf("newInitializeRequest() requested %s, want the newest version %s", request.Params.ProtocolVersion, supportedProtocolVersions[0])
+	}
+	ProtocolVersionOption = "2024-11-05"
+	if request, _ := newInitializeRequest(); request.Params.ProtocolVersion != "2024-11-05" {
+		t.Errorf("newInitializeRequest() requested %s, want 2024-11-05", request.Params.ProtocolVersion)
+	}
+
+	for _, version := range []string{"2024-11-05", "2025-03-26", ""} {
+		if err := negotiateProtocolVersion(version); err != nil {
+			t.Errorf("negotiateProtocolVersion(%q) error = %v", version, err)
+		}
+	}
+	if err := negotiateProtocolVersion("2030-01-01"); err == nil {
+		t.Error("negotiateProtocolVersion() expected an error for an unsupported version")
+	}
+}
+
+func TestParseServerEnv(t *testing.T) {
+	originalEnv := ServerEnvOptions
+	defer func() { ServerEnvOptions = originalEnv }()
+
+	ServerEnvOptions = nil
+	gotArgs := ProcessFlags([]string{"--env", "API_KEY=secret", "npx", "server", "--env", "ROOT_DIR=/tmp"})
+	if !reflect.DeepEqual(gotArgs, []string{"npx", "server"}) {
+		t.Errorf("ProcessFlags() gotArgs = %v", gotArgs)
+	}
+
+	env, err := parseServerEnv(ServerEnvOptions)
+	if err != nil {
+		t.Fatalf("parseServerEnv() error = %v", err)
+	}
+	if !reflect.DeepEqual(env, []string{"API_KEY=secret", "ROOT_DIR=/tmp"}) {
+		t.Errorf("parseServerEnv() = %v", env)
+	}
+
+	for _, invalid := range []string{"API_KEY", "=value"} {
+		if _, err := parseServerEnv([]string{invalid}); err == nil {
+			t.Errorf("parseServerEnv(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestNewServerKey(t *testing.T) {
+	key := newServerKey("stdio", []string{"npx", "server"}, []string{"B=2", "A=1"}, nil)
+
+	testCases := []struct {
+		name     string
+		key      []string
+		expected bool
+	}{
+		{name: "env in another order", key: newServerKey("stdio", []string{"npx", "server"}, []string{"A=1", "B=2"}, nil), expected: true},
+		{name: "other env value", key: newServerKey("stdio", []string{"npx", "server"}, []string{"A=1", "B=3"}, nil), expected: false},
+		{name: "other arguments", key: newServerKey("stdio", []string{"npx", "server", "--debug"}, []string{"A=1", "B=2"}, nil), expected: false},
+		{name: "other transport", key: newServerKey("sse", []string{"npx", "server"}, []string{"A=1", "B=2"}, nil), expected: false},
+		{name: "arguments that look like env", key: newServerKey("stdio", []string{"npx", "server", "env:A=1", "env:B=2"}, nil, nil), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reflect.DeepEqual(key, tc.key); got != tc.expected {
+				t.Errorf("keys equal = %v, want %v: %q and %q", got, tc.expected, key, tc.key)
+			}
+		})
+	}
+
+	urlKey := newServerKey("http", []string{"https://example.com/mcp"}, nil, map[string]string{"X-Tenant": "a"})
+	if reflect.DeepEqual(urlKey, newServerKey("http", []string{"https://example.com/mcp"}, nil, map[string]string{"X-Tenant": "b"})) {
+		t.Error("servers with different headers have the same key")
+	}
+}
+
 func TestFormatAndPrintResponse(t *testing.T) {
 	// Save original value to restore later
 	originalFormat := FormatOption
@@ -190,3 +489,160 @@ nested  {"key":"value"}`[1:] // remove first newline
 		})
 	}
 }
+
+func TestPrintEntityList(t *testing.T) {
+	originalFormat, originalCount := FormatOption, CountOption
+	defer func() { FormatOption, CountOption = originalFormat, originalCount }()
+
+	tools := []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}
+	run := func() (string, string) {
+		t.Helper()
+		cmd := &cobra.Command{}
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		if err := printEntityList(cmd, "tools", "tool", tools, nil); err != nil {
+			t.Fatalf("printEntityList() error = %v", err)
+		}
+		return out.String(), errOut.String()
+	}
+
+	FormatOption, CountOption = "table", false
+	out, errOut := run()
+	assertContains(t, out, "a\n")
+	assertEquals(t, errOut, "2 tools\n")
+
+	// JSON output carries no summary
+	FormatOption = "json"
+	if _, errOut = run(); errOut != "" {
+		t.Errorf("stderr = %q, want nothing in JSON format", errOut)
+	}
+
+	CountOption = true
+	out, errOut = run()
+	assertEquals(t, out, "2\n")
+	assertEquals(t, errOut, "")
+
+	assertEquals(t, countLabel(1, "resource template"), "1 resource template")
+}
+
+func TestToolResultError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resp     map[string]any
+		expected string
+	}{
+		{
+			name:     "success",
+			resp:     map[string]any{"content": []any{map[string]any{"type": "text", "text": "ok"}}},
+			expected: "",
+		},
+		{
+			name: "error text",
+			resp: map[string]any{
+				"isError": true,
+				"content": []any{
+					map[string]any{"type": "text", "text": "disk is full"},
+					map[string]any{"type": "image", "data": "..."},
+					map[string]any{"type": "text", "text": "try again later"},
+				},
+			},
+			expected: "disk is full\ntry again later",
+		},
+		{
+			name:     "error without text",
+			resp:     map[string]any{"isError": true},
+			expected: "the tool reported an error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := toolResultError(tc.resp)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.expected {
+				t.Errorf("toolResultError() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCreateClientConnectTimeout(t *testing.T) {
+	originalTimeout := ConnectTimeout
+	defer func() { ConnectTimeout = originalTimeout }()
+	ConnectTimeout = 100 * time.Millisecond
+
+	// The server doesn't answer the initialize request until the test is over
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := CreateClientFunc([]string{server.URL})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("CreateClientFunc() error = %v, want a timeout after 100ms", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("CreateClientFunc() took %s, want about %s", elapsed, ConnectTimeout)
+	}
+}
+
+// closeCountingTransport counts how often it is closed.
+type closeCountingTransport struct {
+	MockTransport
+	closed atomic.Int32
+}
+
+func (c *closeCountingTransport) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestCloseLiveClients(t *testing.T) {
+	transports := []*closeCountingTransport{{}, {}, {}}
+	clients := make([]*client.Client, len(transports))
+	for i, transport := range transports {
+		clients[i] = client.NewClient(transport)
+		closeOnSignal(clients[i])
+	}
+
+	// A client closed by its command is no longer closed on a signal
+	CloseWithTimeout(clients[0])
+	closeLiveClients()
+
+	for i, transport := range transports {
+		if got := transport.closed.Load(); got != 1 {
+			t.Errorf("client %d closed %d times, want 1", i, got)
+		}
+	}
+
+	liveClientsMu.Lock()
+	defer liveClientsMu.Unlock()
+	if len(liveClients) != 0 {
+		t.Errorf("expected no live clients after closing them, got %d", len(liveClients))
+	}
+}
+
+func TestCloseWithTimeoutForgetsClient(t *testing.T) {
+	c := client.NewClient(&MockTransport{})
+	serverKeys.Store(c, newServerKey("stdio", []string{"server"}, nil, nil))
+	initializeResults.Store(c, map[string]any{"protocolVersion": "2025-03-26"})
+
+	CloseWithTimeout(c)
+
+	if _, ok := serverKey(c); ok {
+		t.Error("Expected the server key of a closed client to be forgotten")
+	}
+	if _, ok := initializeResult(c); ok {
+		t.Error("Expected the initialize result of a closed client to be forgotten")
+	}
+}
@@ -1,8 +1,15 @@
 package commands
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -23,13 +30,154 @@ func getHomeDirectory() string {
 // TemplatesPath information placeholder.
 var TemplatesPath = getHomeDirectory() + "/.mcpt/templates"
 
+// latestReleaseURL is the GitHub API endpoint of the latest release, which version --check
+// compares the version with.
+var latestReleaseURL = "https://api.github.com/repos/f/mcptools/releases/latest"
+
+// versionCheckTimeout is how long version --check waits for GitHub.
+const versionCheckTimeout = 5 * time.Second
+
 // VersionCmd creates the version command.
 func VersionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the version information",
 		Run: func(cmd *cobra.Command, _ []string) {
 			fmt.Fprintf(cmd.OutOrStdout(), "MCP Tools version %s\n", Version)
+
+			check, _ := cmd.Flags().GetBool("check")
+			if !check {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+			defer cancel()
+			latest, err := fetchLatestRelease(ctx, latestReleaseURL)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Could not check for updates: %v\n", err)
+				return
+			}
+			fmt.Fprint(cmd.OutOrStdout(), describeUpdate(Version, latest))
 		},
 	}
+
+	cmd.Flags().Bool("check", false, "Check GitHub for a newer release")
+	return cmd
+}
+
+// fetchLatestRelease returns the tag of the latest release from the GitHub releases API.
+func fetchLatestRelease(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub answered with status %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("invalid release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("the latest release has no tag")
+	}
+	return release.TagName, nil
+}
+
+// describeUpdate reports the latest release and whether it is newer than current.
+func describeUpdate(current, latest string) string {
+	message := fmt.Sprintf("Latest release: %s\n", latest)
+	switch compared, ok := compareVersions(current, latest); {
+	case !ok:
+		return message + fmt.Sprintf("Version %s can't be compared with releases\n", current)
+	case compared < 0:
+		return message + fmt.Sprintf("An update is available: %s -> %s\n", current, latest)
+	default:
+		return message + "You are using the latest version\n"
+	}
+}
+
+// compareVersions compares two semantic versions such as v1.2.3 or 1.2.3-rc.1, returning -1,
+// 0 or 1 as a is older than, the same as or newer than b. It returns false if either isn't a
+// semantic version, such as the "dev" version of builds from source.
+func compareVersions(a, b string) (int, bool) {
+	aCore, aPre, aOK := parseVersion(a)
+	bCore, bPre, bOK := parseVersion(b)
+	if !aOK || !bOK {
+		return 0, false
+	}
+
+	for i := range aCore {
+		if aCore[i] != bCore[i] {
+			return cmp.Compare(aCore[i], bCore[i]), true
+		}
+	}
+
+	// A pre-release is older than the release itself
+	switch {
+	case aPre == bPre:
+		return 0, true
+	case aPre == "":
+		return 1, true
+	case bPre == "":
+		return -1, true
+	}
+	return comparePrerelease(aPre, bPre), true
+}
+
+// parseVersion splits a semantic version into its major, minor and patch numbers and its
+// pre-release, ignoring a leading v and build metadata.
+func parseVersion(version string) ([3]int, string, bool) {
+	var core [3]int
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != len(core) {
+		return core, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return core, "", false
+		}
+		core[i] = n
+	}
+	return core, prerelease, true
+}
+
+// comparePrerelease compares pre-releases identifier by identifier, numerically for numbers
+// and lexically otherwise, as semantic versioning specifies.
+func comparePrerelease(a, b string) int {
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return cmp.Compare(aNum, bNum)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case aIDs[i] != bIDs[i]:
+			return strings.Compare(aIDs[i], bIDs[i])
+		}
+	}
+	return cmp.Compare(len(aIDs), len(bIDs))
 }
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -50,3 +52,77 @@ func TestVersionCmdWorks(t *testing.T) {
 		t.Error("Expected Run function to be defined")
 	}
 }
+
+func TestVersionCheck(t *testing.T) {
+	oldVersion, oldURL := Version, latestReleaseURL
+	defer func() { Version, latestReleaseURL = oldVersion, oldURL }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name":"v1.3.0"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		version  string
+		path     string
+		expected string
+		stderr   string
+	}{
+		{"update available", "v1.2.0", "/latest", "An update is available: v1.2.0 -> v1.3.0", ""},
+		{"up to date", "1.3.0", "/latest", "You are using the latest version", ""},
+		{"development build", "dev", "/latest", "Version dev can't be compared with releases", ""},
+		{"check fails", "v1.2.0", "/missing", "", "Could not check for updates: GitHub answered with status 404"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			Version = tc.version
+			latestReleaseURL = server.URL + tc.path
+
+			cmd := VersionCmd()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs([]string{"--check"})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("cmd.Execute() error = %v", err)
+			}
+
+			if tc.expected != "" {
+				assertContains(t, stdout.String(), "Latest release: v1.3.0")
+				assertContains(t, stdout.String(), tc.expected)
+			}
+			if tc.stderr != "" {
+				assertContains(t, stderr.String(), tc.stderr)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+		ok       bool
+	}{
+		{"v1.2.3", "1.2.3", 0, true},
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"v2.0.0", "v1.99.99", 1, true},
+		{"v1.0.0-rc.1", "v1.0.0", -1, true},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1, true},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1, true},
+		{"v1.0.0+build.5", "v1.0.0", 0, true},
+		{"dev", "v1.0.0", 0, false},
+		{"v1.0", "v1.0.0", 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := compareVersions(tc.a, tc.b)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v, want %d, %v", tc.a, tc.b, got, ok, tc.expected, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGzipHandler(t *testing.T) {
+	handler := gzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"result":"ok"}`)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("PNG"))
+		case "/empty":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+
+	testCases := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantGzip       bool
+		wantBody       string
+	}{
+		{name: "compresses JSON", path: "/json", acceptEncoding: "gzip, deflate", wantGzip: true, wantBody: `{"result":"ok"}`},
+		{name: "without accept-encoding", path: "/json", wantBody: `{"result":"ok"}`},
+		{name: "gzip refused", path: "/json", acceptEncoding: "gzip;q=0", wantBody: `{"result":"ok"}`},
+		{name: "skips images", path: "/image", acceptEncoding: "gzip", wantBody: "PNG"},
+		{name: "skips empty responses", path: "/empty", acceptEncoding: "gzip"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			gzipped := w.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tc.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip: %v", w.Header().Get("Content-Encoding"), tc.wantGzip)
+			}
+
+			var body io.Reader = w.Body
+			if gzipped {
+				reader, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				body = reader
+			}
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("reading body: %v", err)
+			}
+			if string(data) != tc.wantBody {
+				t.Errorf("body = %q, want %q", data, tc.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/client"
+)
+
+// defaultPoolSize is the number of clients the web interface connects to the server with,
+// unless --pool-size is given. A single client keeps the state of a stdio server in one process.
+const defaultPoolSize = 1
+
+// errPoolCannotReconnect is returned for a client of a pool that died when the pool can't
+// connect a new one.
+var errPoolCannotReconnect = errors.New("the connection to the server was lost")
+
+// MCPClientCache is a pool of clients connected to the same server, so that API requests of
+// the web interface don't wait on each other. Each request uses an idle client, waiting for
+// one to be released when all of them are in use. A client whose connection failed is closed
+// when it is released, and replaced by a new one the next time its slot is acquired.
+type MCPClientCache struct {
+	// newClient connects a client to replace one that died, if set
+	newClient func() (*client.Client, error)
+	// idle holds the clients that aren't in use, and nil for the slots of dead clients
+	idle chan *client.Client
+
+	mu      sync.Mutex
+	clients map[*client.Client]bool
+}
+
+// newClientPool creates a pool of clients.
+func newClientPool(clients ...*client.Client) *MCPClientCache {
+	idle := make(chan *client.Client, len(clients))
+	live := make(map[*client.Client]bool, len(clients))
+	for _, c := range clients {
+		idle <- c
+		live[c] = true
+	}
+	return &MCPClientCache{idle: idle, clients: live}
+}
+
+// startClientPool creates size clients with newClient, in parallel, and pools them. Each
+// client runs its own copy of a stdio server. If any client fails to start, the others are
+// closed and the first error is returned. Clients that die are replaced with newClient.
+func startClientPool(size int, newClient func() (*client.Client, error)) (*MCPClientCache, error) {
+	clients := make([]*client.Client, max(size, 1))
+	errs := make([]error, len(clients))
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i], errs[i] = newClient()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			for _, c := range clients {
+				if c != nil {
+					CloseWithTimeout(c)
+				}
+			}
+			return nil, err
+		}
+	}
+	pool := newClientPool(clients...)
+	pool.newClient = newClient
+	return pool, nil
+}
+
+// acquire returns an idle client, waiting for one to be released unless ctx is done first.
+// The slot of a client that died is given a new client. The client must be given back with
+// release.
+func (p *MCPClientCache) acquire(ctx context.Context) (*client.Client, error) {
+	var c *client.Client
+	select {
+	case c = <-p.idle:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c != nil {
+		return c, nil
+	}
+
+	if p.newClient == nil {
+		p.idle <- nil
+		return nil, errPoolCannotReconnect
+	}
+	c, err := p.newClient()
+	if err != nil {
+		p.idle <- nil
+		return nil, fmt.Errorf("%w: %w", errPoolCannotReconnect, err)
+	}
+	p.mu.Lock()
+	p.clients[c] = true
+	p.mu.Unlock()
+	return c, nil
+}
+
+// release gives a client obtained with acquire back to the pool, along with the error of the
+// request it was used for. A client whose request failed in the transport, such as a stdio
+// server that exited, is closed instead and its slot reconnected by a later acquire. A request
+// that was cancelled, because the browser went away or the server is shutting down, leaves
+// its client usable.
+func (p *MCPClientCache) release(c *client.Client, err error) {
+	var transportErr *mcpclient.TransportError
+	if !errors.As(err, &transportErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		p.idle <- c
+		return
+	}
+
+	p.mu.Lock()
+	delete(p.clients, c)
+	p.mu.Unlock()
+	CloseWithTimeout(c)
+	p.idle <- nil
+}
+
+// close closes every client of the pool.
+func (p *MCPClientCache) close() {
+	p.mu.Lock()
+	clients := make([]*client.Client, 0, len(p.clients))
+	for c := range p.clients {
+		clients = append(clients, c)
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			CloseWithTimeout(c)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClientPoolConcurrentRequests(t *testing.T) {
+	// Each call waits until both are in flight, which only happens if the pool doesn't
+	// serialize them
+	var inFlight sync.WaitGroup
+	inFlight.Add(2)
+	newMockClient := func() (*client.Client, error) {
+		mockClient := client.NewClient(&MockTransport{
+			ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+				inFlight.Done()
+				inFlight.Wait()
+				return map[string]any{"tools": []any{}}, nil
+			},
+		})
+		_, err := mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+		return mockClient, err
+	}
+
+	pool, err := startClientPool(2, newMockClient)
+	if err != nil {
+		t.Fatalf("startClientPool() error = %v", err)
+	}
+	handler := handleTools(pool)
+
+	done := make(chan int, 2)
+	for range 2 {
+		go func() {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/api/tools", nil))
+			done <- w.Code
+		}()
+	}
+
+	for range 2 {
+		select {
+		case code := <-done:
+			if code != http.StatusOK {
+				t.Errorf("status = %d, want %d", code, http.StatusOK)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("requests were not served concurrently")
+		}
+	}
+}
+
+func TestClientPoolAcquireWaits(t *testing.T) {
+	mockClient := client.NewClient(&MockTransport{})
+	pool := newClientPool(mockClient)
+
+	c, err := pool.acquire(context.Background())
+	if err != nil || c != mockClient {
+		t.Fatalf("acquire() = %v, %v, want the pooled client", c, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() on an exhausted pool error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	pool.release(c, nil)
+	if c, err = pool.acquire(context.Background()); err != nil || c != mockClient {
+		t.Errorf("acquire() after release = %v, %v, want the released client", c, err)
+	}
+}
+
+func TestStartClientPoolError(t *testing.T) {
+	var mutex sync.Mutex
+	created := 0
+	_, err := startClientPool(3, func() (*client.Client, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		created++
+		if created == 2 {
+			return nil, errors.New("server failed to start")
+		}
+		return client.NewClient(&MockTransport{}), nil
+	})
+
+	if err == nil || err.Error() != "server failed to start" {
+		t.Errorf("startClientPool() error = %v, want the client error", err)
+	}
+	if created != 3 {
+		t.Errorf("created %d clients, want 3", created)
+	}
+}
+
+func TestClientPoolReconnectsDeadClient(t *testing.T) {
+	// The first client's server is gone, so its requests fail in the transport
+	var transports []*closeCountingTransport
+	newMockClient := func() (*client.Client, error) {
+		mockTransport := &closeCountingTransport{}
+		dead := len(transports) == 0
+		mockTransport.ExecuteFunc = func(_ string, _ any) (map[string]any, error) {
+			if dead {
+				return nil, errors.New("broken pipe")
+			}
+			return map[string]any{"tools": []any{}}, nil
+		}
+		transports = append(transports, mockTransport)
+		mockClient := client.NewClient(mcpclient.NewRecording(mockTransport))
+		_, err := mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+		return mockClient, err
+	}
+
+	pool, err := startClientPool(1, newMockClient)
+	if err != nil {
+		t.Fatalf("startClientPool() error = %v", err)
+	}
+	defer pool.close()
+	handler := handleTools(pool)
+
+	for i, want := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/api/tools", nil))
+		if w.Code != want {
+			t.Errorf("request %d status = %d, want %d", i, w.Code, want)
+		}
+	}
+
+	if len(transports) != 2 {
+		t.Fatalf("created %d clients, want 2", len(transports))
+	}
+	if got := transports[0].closed.Load(); got != 1 {
+		t.Errorf("dead client closed %d times, want 1", got)
+	}
+	if got := transports[1].closed.Load(); got != 0 {
+		t.Errorf("new client closed %d times, want 0", got)
+	}
+}
+
+// blockingTransport answers tools/list only once the request's context is done.
+type blockingTransport struct {
+	closeCountingTransport
+}
+
+func (b *blockingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method == "tools/list" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return b.closeCountingTransport.SendRequest(ctx, request)
+}
+
+func TestHandleToolsCancelledRequest(t *testing.T) {
+	mockTransport := &blockingTransport{}
+	mockClient := client.NewClient(mcpclient.NewRecording(mockTransport))
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+	pool := newClientPool(mockClient)
+
+	// The call ends with the request instead of holding the pool's only client
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	handleTools(pool)(w, httptest.NewRequest(http.MethodGet, "/api/tools", nil).WithContext(ctx))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	acquireCtx, acquireCancel := context.WithTimeout(context.Background(), time.Second)
+	defer acquireCancel()
+	c, err := pool.acquire(acquireCtx)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if c != mockClient || mockTransport.closed.Load() != 0 {
+		t.Error("Expected the client of a cancelled request to be kept")
+	}
+}
+
+func TestClientPoolReconnectError(t *testing.T) {
+	pool := newClientPool(client.NewClient(&MockTransport{}))
+	c, err := pool.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.release(c, &mcpclient.TransportError{Err: errors.New("broken pipe")})
+
+	// Without a way to connect a new client, the slot stays empty rather than blocking
+	for range 2 {
+		if _, err := pool.acquire(context.Background()); !errors.Is(err, errPoolCannotReconnect) {
+			t.Errorf("acquire() error = %v, want %v", err, errPoolCannotReconnect)
+		}
+	}
+}
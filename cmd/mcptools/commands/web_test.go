@@ -0,0 +1,311 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mcpclient "github.com/f/mcptools/pkg/client"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWebAuthOptionsWrap(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name           string
+		auth           webAuthOptions
+		setup          func(r *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:           "no authentication",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token accepted",
+			auth:           webAuthOptions{Token: "s3cret"},
+			setup:          func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token missing",
+			auth:           webAuthOptions{Token: "s3cret"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "token wrong",
+			auth:           webAuthOptions{Token: "s3cret"},
+			setup:          func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "basic auth accepted",
+			auth:           webAuthOptions{User: "admin", Password: "pass"},
+			setup:          func(r *http.Request) { r.SetBasicAuth("admin", "pass") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "basic auth wrong password",
+			auth:           webAuthOptions{User: "admin", Password: "pass"},
+			setup:          func(r *http.Request) { r.SetBasicAuth("admin", "nope") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/tools", nil)
+			if tc.setup != nil {
+				tc.setup(request)
+			}
+			recorder := httptest.NewRecorder()
+			tc.auth.wrap(ok).ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, recorder.Code)
+			}
+			if recorder.Code == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate header on 401 responses")
+			}
+		})
+	}
+}
+
+func TestWebAuthOptionsValidate(t *testing.T) {
+	valid := []webAuthOptions{{}, {Token: "t"}, {User: "u", Password: "p"}}
+	for _, auth := range valid {
+		if err := auth.validate(); err != nil {
+			t.Errorf("validate(%+v) error = %v", auth, err)
+		}
+	}
+
+	invalid := []webAuthOptions{{Token: "t", User: "u", Password: "p"}, {User: "u"}, {Password: "p"}}
+	for _, auth := range invalid {
+		if err := auth.validate(); err == nil {
+			t.Errorf("validate(%+v) expected an error", auth)
+		}
+	}
+}
+
+func TestWebAssets(t *testing.T) {
+	assets, err := webAssets("")
+	if err != nil {
+		t.Fatalf("webAssets() error = %v", err)
+	}
+	server := http.FileServer(assets)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("GET %s: expected status 200, got %d", path, recorder.Code)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("custom"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assets, err = webAssets(dir)
+	if err != nil {
+		t.Fatalf("webAssets(%q) error = %v", dir, err)
+	}
+	recorder := httptest.NewRecorder()
+	http.FileServer(assets).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertContains(t, recorder.Body.String(), "custom")
+
+	if _, err := webAssets(filepath.Join(dir, "index.html")); err == nil {
+		t.Error("webAssets() expected an error for a file")
+	}
+}
+
+func TestHandleResource(t *testing.T) {
+	mockClient := client.NewClient(&MockTransport{
+		ExecuteFunc: func(_ string, _ any) (map[string]any, error) {
+			return map[string]any{
+				"contents": []any{
+					map[string]any{"uri": "test://r", "mimeType": "application/json", "text": `{"a":1}`},
+					map[string]any{"uri": "test://r", "mimeType": "text/plain", "text": "plain"},
+					map[string]any{"uri": "test://r", "mimeType": "image/png", "blob": base64.StdEncoding.EncodeToString([]byte("PNG"))},
+				},
+			}, nil
+		},
+	})
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+	handler := handleResource(newClientPool(mockClient))
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/resource?uri=test%3A%2F%2Fr", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		Result struct {
+			Contents []webResourceContent `json:"contents"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	contents := response.Result.Contents
+	if len(contents) != 3 {
+		t.Fatalf("Expected 3 contents, got %d", len(contents))
+	}
+	if contents[0].Kind != "json" || !reflect.DeepEqual(contents[0].Value, map[string]any{"a": float64(1)}) {
+		t.Errorf("Expected parsed JSON content, got %+v", contents[0])
+	}
+	if contents[1].Kind != "text" || contents[1].Text != "plain" {
+		t.Errorf("Expected text content, got %+v", contents[1])
+	}
+	if contents[2].Kind != "image" || contents[2].Size != 3 || contents[2].URL != "/api/resource?index=2&uri=test%3A%2F%2Fr" {
+		t.Errorf("Expected image content, got %+v", contents[2])
+	}
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, contents[2].URL, nil))
+	if recorder.Body.String() != "PNG" || recorder.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected the decoded image, got %q (%s)", recorder.Body.String(), recorder.Header().Get("Content-Type"))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/resource", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a uri, got %d", recorder.Code)
+	}
+}
+
+// rpcErrorTransport answers every request but initialize with a JSON-RPC error.
+type rpcErrorTransport struct {
+	MockTransport
+}
+
+func (r *rpcErrorTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method == "initialize" {
+		return r.MockTransport.SendRequest(ctx, request)
+	}
+	response := &transport.JSONRPCResponse{ID: &request.ID}
+	response.Error = &struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}{Code: mcp.METHOD_NOT_FOUND, Message: "Unknown tool: nope"}
+	return response, nil
+}
+
+func TestHandleCallRPCError(t *testing.T) {
+	mockClient := client.NewClient(mcpclient.NewRecording(&rpcErrorTransport{}))
+	_, _ = mockClient.Initialize(context.Background(), mcp.InitializeRequest{})
+	handler := handleCall(newClientPool(mockClient))
+
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"type":"tool","name":"nope","params":{}}`)
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/api/call", body))
+
+	var response struct {
+		Error string `json:"error"`
+		Code  *int   `json:"code"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	if response.Error != "Unknown tool: nope" || response.Code == nil || *response.Code != mcp.METHOD_NOT_FOUND {
+		t.Errorf("Expected the JSON-RPC error with its code, got %s", recorder.Body.String())
+	}
+}
+
+func TestServeWebShutdown(t *testing.T) {
+	poolTransport, bridgeTransport := &closeCountingTransport{}, &closeCountingTransport{}
+	clientPool := newClientPool(client.NewClient(poolTransport))
+	bridgeClient := client.NewClient(bridgeTransport)
+
+	// The handler holds its request until it is released
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out strings.Builder
+	served := make(chan error, 1)
+	go func() {
+		served <- serveWeb(ctx, &out, listener, handler, clientPool, bridgeClient)
+	}()
+
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+	<-started
+
+	// Shutting down waits for the in-flight request before closing the clients
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("serveWeb() returned %v before the in-flight request finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if poolTransport.closed.Load() != 0 || bridgeTransport.closed.Load() != 0 {
+		t.Error("clients were closed before the in-flight request finished")
+	}
+
+	close(release)
+	if body := <-responses; body != "done" {
+		t.Errorf("in-flight request got %q, want done", body)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serveWeb() error = %v", err)
+	}
+	if poolTransport.closed.Load() != 1 || bridgeTransport.closed.Load() != 1 {
+		t.Errorf("clients closed %d and %d times, want once each", poolTransport.closed.Load(), bridgeTransport.closed.Load())
+	}
+	assertContains(t, out.String(), "Shutting down")
+}
+
+func TestServeWebFailure(t *testing.T) {
+	poolTransport := &closeCountingTransport{}
+	clientPool := newClientPool(client.NewClient(poolTransport))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = listener.Close()
+
+	err = serveWeb(context.Background(), io.Discard, listener, http.NotFoundHandler(), clientPool, nil)
+	if err == nil || !strings.Contains(err.Error(), "web server failed") {
+		t.Errorf("serveWeb() error = %v, want the serve error", err)
+	}
+	if got := poolTransport.closed.Load(); got != 1 {
+		t.Errorf("pool client closed %d times, want 1", got)
+	}
+}
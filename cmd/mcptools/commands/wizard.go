@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/peterh/liner"
+	"golang.org/x/term"
+)
+
+// wizardField is an argument or parameter the interactive wizard asks for.
+type wizardField struct {
+	name        string
+	typeName    string
+	description string
+	required    bool
+}
+
+// askFunc reads one answer from the user after showing a prompt.
+type askFunc func(prompt string) (string, error)
+
+// newTerminalAsk returns an askFunc reading from the terminal, and a function that restores
+// it. liner writes its prompts to stdout, so when stdout is piped, as in get-prompt --render |
+// llm, the prompts go to stderr and the answers are read from stdin line by line instead.
+func newTerminalAsk() (askFunc, func()) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		reader := bufio.NewReader(os.Stdin)
+		return func(prompt string) (string, error) {
+			fmt.Fprint(os.Stderr, prompt)
+			line, err := reader.ReadString('\n')
+			if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+				return "", err
+			}
+			return strings.TrimRight(line, "\r\n"), nil
+		}, func() {}
+	}
+
+	line := liner.NewLiner()
+	line.SetCtrlCAborts(true)
+	return line.Prompt, func() { _ = line.Close() }
+}
+
+// askFields asks for the value of each field in order, describing it on out first. Empty
+// answers skip optional fields and are asked again for required ones.
+func askFields(ask askFunc, out io.Writer, fields []wizardField) (map[string]string, error) {
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		var details []string
+		if field.typeName != "" {
+			details = append(details, field.typeName)
+		}
+		if field.required {
+			details = append(details, "required")
+		} else {
+			details = append(details, "optional, leave empty to skip")
+		}
+		fmt.Fprintf(out, "%s (%s)", field.name, strings.Join(details, ", "))
+		if field.description != "" {
+			fmt.Fprintf(out, ": %s", field.description)
+		}
+		fmt.Fprintln(out)
+
+		for {
+			value, err := ask(field.name + "> ")
+			if errors.Is(err, liner.ErrPromptAborted) {
+				return nil, fmt.Errorf("aborted")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", field.name, err)
+			}
+			if value != "" {
+				values[field.name] = value
+				break
+			}
+			if !field.required {
+				break
+			}
+			fmt.Fprintf(out, "%s is required\n", field.name)
+		}
+	}
+	return values, nil
+}
+
+// promptWizardFields returns the declared arguments of a prompt that aren't in given.
+func promptWizardFields(prompt mcp.Prompt, given map[string]string) []wizardField {
+	var fields []wizardField
+	for _, arg := range prompt.Arguments {
+		if _, ok := given[arg.Name]; ok {
+			continue
+		}
+		fields = append(fields, wizardField{name: arg.Name, description: arg.Description, required: arg.Required})
+	}
+	return fields
+}
+
+// toolWizardFields returns the parameters of a tool's input schema that aren't in given,
+// required ones first and each group sorted by name.
+func toolWizardFields(tool mcp.Tool, given map[string]any) []wizardField {
+	required := make(map[string]bool, len(tool.InputSchema.Required))
+	for _, name := range tool.InputSchema.Required {
+		required[name] = true
+	}
+
+	var fields []wizardField
+	for name, p := range tool.InputSchema.Properties {
+		if _, ok := given[name]; ok {
+			continue
+		}
+		property, _ := p.(map[string]any)
+		description, _ := property["description"].(string)
+		fields = append(fields, wizardField{
+			name:        name,
+			typeName:    schemaPropertyType(property),
+			description: description,
+			required:    required[name],
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].required != fields[j].required {
+			return fields[i].required
+		}
+		return fields[i].name < fields[j].name
+	})
+	return fields
+}
+
+// askToolParams connects to the server to read the schema of a tool, then asks for the
+// parameters that aren't in params. The answers are parsed like --params key=value values.
+func askToolParams(serverArgs []string, toolName string, params map[string]any, ask askFunc, out io.Writer) (map[string]any, error) {
+	mcpClient, err := CreateClientFunc(serverArgs)
+	if err != nil {
+		return nil, err
+	}
+	tool, err := findTool(mcpClient, toolName)
+	CloseWithTimeout(mcpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := askFields(ask, out, toolWizardFields(tool, params))
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(answers))
+	for name, value := range answers {
+		values = append(values, name+"="+value)
+	}
+	parsed, err := parseCallParams(values)
+	if err != nil {
+		return nil, err
+	}
+	return mergeParams(params, parsed), nil
+}
+
+// findTool returns the tool with the given name from the server's tools list.
+func findTool(mcpClient *client.Client, toolName string) (mcp.Tool, error) {
+	resp, err := mcpClient.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		return mcp.Tool{}, err
+	}
+	for _, tool := range resp.Tools {
+		if tool.Name == toolName {
+			return tool, nil
+		}
+	}
+	return mcp.Tool{}, fmt.Errorf("tool %s not found", toolName)
+}
+
+// findPrompt returns the prompt with the given name from the server's prompts list.
+func findPrompt(mcpClient *client.Client, promptName string) (mcp.Prompt, error) {
+	resp, err := mcpClient.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+	if err != nil {
+		return mcp.Prompt{}, err
+	}
+	for _, prompt := range resp.Prompts {
+		if prompt.Name == promptName {
+			return prompt, nil
+		}
+	}
+	return mcp.Prompt{}, fmt.Errorf("prompt %s not found", promptName)
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/peterh/liner"
+)
+
+// scriptedAsk returns an askFunc answering with answers in order, and records the prompts.
+func scriptedAsk(answers ...string) (askFunc, *[]string) {
+	var prompts []string
+	return func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(answers) == 0 {
+			return "", liner.ErrPromptAborted
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}, &prompts
+}
+
+func TestAskFields(t *testing.T) {
+	fields := []wizardField{
+		{name: "topic", description: "What to summarize", required: true},
+		{name: "style"},
+		{name: "words", typeName: "integer"},
+	}
+	ask, prompts := scriptedAsk("", "go", "", "50")
+	out := new(bytes.Buffer)
+
+	values, err := askFields(ask, out, fields)
+	if err != nil {
+		t.Fatalf("askFields() error = %v", err)
+	}
+	if want := map[string]string{"topic": "go", "words": "50"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if want := []string{"topic> ", "topic> ", "style> ", "words> "}; !reflect.DeepEqual(*prompts, want) {
+		t.Errorf("prompts = %q, want %q", *prompts, want)
+	}
+	assertContains(t, out.String(), "topic (required): What to summarize")
+	assertContains(t, out.String(), "topic is required")
+	assertContains(t, out.String(), "words (integer, optional, leave empty to skip)")
+
+	ask, _ = scriptedAsk()
+	if _, err := askFields(ask, out, fields); err == nil || err.Error() != "aborted" {
+		t.Errorf("askFields() error = %v, want aborted", err)
+	}
+}
+
+func TestToolWizardFields(t *testing.T) {
+	tool := mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required(), mcp.Description("Search terms")),
+		mcp.WithNumber("limit"),
+		mcp.WithBoolean("exact"),
+		mcp.WithString("lang", mcp.Required()),
+	)
+
+	var names []string
+	for _, field := range toolWizardFields(tool, map[string]any{"lang": "go"}) {
+		names = append(names, field.name+":"+field.typeName)
+	}
+	if want := []string{"query:string", "exact:boolean", "limit:number"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fields = %v, want %v", names, want)
+	}
+}
+
+func TestAskToolParams(t *testing.T) {
+	cleanup := setupMockClient(func(_ string, _ any) (map[string]any, error) {
+		return map[string]any{"tools": []any{map[string]any{
+			"name": "search",
+			"inputSchema": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"query": map[string]any{"type": "string"}, "tags": map[string]any{"type": "array"}},
+				"required":   []any{"query"},
+			},
+		}}}, nil
+	})
+	defer cleanup()
+
+	ask, _ := scriptedAsk(`["a","b"]`)
+	params, err := askToolParams([]string{"server"}, "search", map[string]any{"query": "mcp"}, ask, new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("askToolParams() error = %v", err)
+	}
+	if want := map[string]any{"query": "mcp", "tags": []any{"a", "b"}}; !reflect.DeepEqual(params, want) {
+		t.Errorf("params = %v, want %v", params, want)
+	}
+
+	if _, err := askToolParams([]string{"server"}, "missing", nil, ask, new(bytes.Buffer)); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("askToolParams() error = %v, want the tool not found", err)
+	}
+}
@@ -29,15 +29,24 @@ func main() {
 		commands.VersionCmd(),
 		commands.ToolsCmd(),
 		commands.ResourcesCmd(),
+		commands.ResourceTemplatesCmd(),
 		commands.PromptsCmd(),
+		commands.AllCmd(),
+		commands.OpenAPICmd(),
 		commands.CallCmd(),
+		commands.BatchCmd(),
+		commands.PingCmd(),
+		commands.InfoCmd(),
+		commands.LogsCmd(),
 		commands.GetPromptCmd(),
 		commands.ReadResourceCmd(),
+		commands.CompleteCmd(),
 		commands.ShellCmd(),
 		commands.WebCmd(),
 		commands.MockCmd(),
 		commands.ProxyCmd(),
 		commands.AliasCmd(),
+		commands.CacheCmd(),
 		commands.ConfigsCmd(),
 		commands.NewCmd(),
 		commands.GuardCmd(),
@@ -0,0 +1,145 @@
+/*
+Package cache implements the on-disk cache of the tool schemas of MCP servers.
+*/
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultTTL is how long the tools of a server are used from the cache before they are listed
+// again.
+const DefaultTTL = 10 * time.Minute
+
+// entry is the cached tool listing of a server.
+type entry struct {
+	// Key is the hash of the key of the server. The key itself isn't stored, since it may
+	// include secrets from the environment or headers of the server.
+	Key      string     `json:"key"`
+	CachedAt time.Time  `json:"cachedAt"`
+	Tools    []mcp.Tool `json:"tools"`
+}
+
+// hashKey returns the hex-encoded hash of the key of a server.
+func hashKey(key []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(key, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetDir returns the cache directory, ~/.mcpt/cache.
+func GetDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".mcpt", "cache"), nil
+}
+
+// getPath returns the path of the file caching the tools of a server. Servers are told apart
+// by a hash of their key, which keeps commands with paths or URLs out of file names.
+func getPath(key []string) (string, error) {
+	dir, err := GetDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tools-"+hashKey(key)[:16]+".json"), nil
+}
+
+// LoadTools returns the cached tools of the server identified by key, or false if they aren't
+// cached, are older than ttl or can't be read.
+func LoadTools(key []string, ttl time.Duration) ([]mcp.Tool, bool) {
+	path, err := getPath(key)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path is generated internally by getPath
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	// A hash collision would otherwise hand out the tools of another server
+	if e.Key != hashKey(key) || time.Since(e.CachedAt) > ttl {
+		return nil, false
+	}
+	return e.Tools, true
+}
+
+// SaveTools caches the tools of the server identified by key, replacing what was cached for it.
+func SaveTools(key []string, tools []mcp.Tool) error {
+	path, err := getPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry{Key: hashKey(key), CachedAt: time.Now(), Tools: tools})
+	if err != nil {
+		return fmt.Errorf("failed to marshal tools: %w", err)
+	}
+
+	// Write to a temporary file first, so that concurrent calls never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tools-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmp.Name(), path)
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", writeErr)
+	}
+	return nil
+}
+
+// Clear removes everything cached and returns the number of servers whose tools were cached.
+func Clear() (int, error) {
+	dir, err := GetDir()
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove cache file: %w", err)
+		}
+		if strings.HasSuffix(file.Name(), ".json") {
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSaveAndLoadTools(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := []string{"npx", "-y", "server"}
+	tools := []mcp.Tool{mcp.NewTool("search", mcp.WithNumber("count"))}
+	if err := SaveTools(server, tools); err != nil {
+		t.Fatalf("SaveTools() error = %v", err)
+	}
+
+	loaded, ok := LoadTools(server, time.Minute)
+	if !ok || len(loaded) != 1 || loaded[0].Name != "search" {
+		t.Fatalf("LoadTools() = %v, %v, want the saved tool", loaded, ok)
+	}
+	if _, ok := loaded[0].InputSchema.Properties["count"]; !ok {
+		t.Errorf("LoadTools() lost the input schema: %+v", loaded[0].InputSchema)
+	}
+
+	if _, ok := LoadTools([]string{"npx", "-y", "other"}, time.Minute); ok {
+		t.Error("LoadTools() returned tools for a server that wasn't cached")
+	}
+	if _, ok := LoadTools(server, 0); ok {
+		t.Error("LoadTools() returned expired tools")
+	}
+}
+
+func TestClear(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if removed, err := Clear(); err != nil || removed != 0 {
+		t.Errorf("Clear() without a cache = %d, %v, want 0, nil", removed, err)
+	}
+
+	for _, server := range [][]string{{"a"}, {"b"}} {
+		if err := SaveTools(server, nil); err != nil {
+			t.Fatalf("SaveTools() error = %v", err)
+		}
+	}
+	if removed, err := Clear(); err != nil || removed != 2 {
+		t.Errorf("Clear() = %d, %v, want 2, nil", removed, err)
+	}
+	if _, ok := LoadTools([]string{"a"}, time.Minute); ok {
+		t.Error("LoadTools() returned tools after Clear()")
+	}
+}
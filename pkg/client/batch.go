@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// batchRequestIDBase is the first id given to batched requests. The client numbers its own
+// requests from 1, so batched requests can share a transport with it without colliding.
+const batchRequestIDBase = 1 << 41
+
+// ErrBatchUnsupported is returned when a transport, or the server behind it, can't handle
+// JSON-RPC batch requests.
+var ErrBatchUnsupported = errors.New("batch requests are not supported")
+
+// batchRequestID numbers batched requests.
+var batchRequestID atomic.Int64
+
+// Batcher is implemented by transports that can send several JSON-RPC requests as a single
+// batch. SendBatch returns the responses in the order of the requests.
+type Batcher interface {
+	SendBatch(ctx context.Context, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error)
+}
+
+// BatchRequest is a request to send as part of a batch.
+type BatchRequest struct {
+	Method string
+	Params any
+}
+
+// Batch sends requests to the server behind c in one JSON-RPC batch and returns their
+// responses in the same order. It returns ErrBatchUnsupported when the transport of c can't
+// send batches, in which case the requests should be sent one by one.
+func Batch(ctx context.Context, c *mcpclient.Client, requests ...BatchRequest) ([]*transport.JSONRPCResponse, error) {
+	jsonrpcRequests := make([]transport.JSONRPCRequest, len(requests))
+	for i, request := range requests {
+		jsonrpcRequests[i] = transport.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      batchRequestIDBase + batchRequestID.Add(1),
+			Method:  request.Method,
+			Params:  request.Params,
+		}
+	}
+	return sendBatch(ctx, c.GetTransport(), jsonrpcRequests)
+}
+
+// sendBatch sends requests with the first transport in the chain of t that can send batches.
+func sendBatch(ctx context.Context, t transport.Interface, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error) {
+	for {
+		if batcher, ok := t.(Batcher); ok {
+			return batcher.SendBatch(ctx, requests)
+		}
+		wrapper, ok := t.(interface{ Unwrap() transport.Interface })
+		if !ok {
+			return nil, ErrBatchUnsupported
+		}
+		t = wrapper.Unwrap()
+	}
+}
+
+// orderResponses matches responses to requests by id, failing if any request is left
+// without a response.
+func orderResponses(requests []transport.JSONRPCRequest, responses map[int64]*transport.JSONRPCResponse) ([]*transport.JSONRPCResponse, error) {
+	ordered := make([]*transport.JSONRPCResponse, len(requests))
+	for i, request := range requests {
+		response, ok := responses[request.ID]
+		if !ok {
+			return nil, fmt.Errorf("batch response is missing request %d", request.ID)
+		}
+		ordered[i] = response
+	}
+	return ordered, nil
+}
+
+// batchError converts an error response the server sent for a whole batch, such as the
+// parse error of a server that only accepts single messages, into ErrBatchUnsupported.
+func batchError(response *transport.JSONRPCResponse) error {
+	if response.Error == nil {
+		return fmt.Errorf("unexpected response to a batch request")
+	}
+	return fmt.Errorf("%w: %s", ErrBatchUnsupported, response.Error.Message)
+}
@@ -0,0 +1,128 @@
+//go:build !windows
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+func TestStdioSendBatch(t *testing.T) {
+	// cat echoes the batch back, which is enough to route each request as a response by id
+	stdio := NewStdio("cat", nil, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requests := []transport.JSONRPCRequest{
+		{JSONRPC: "2.0", ID: 3, Method: "tools/list"},
+		{JSONRPC: "2.0", ID: 1, Method: "resources/list"},
+		{JSONRPC: "2.0", ID: 2, Method: "prompts/list"},
+	}
+	responses, err := stdio.SendBatch(ctx, requests)
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	for i, response := range responses {
+		if response.ID == nil || *response.ID != requests[i].ID {
+			t.Errorf("response %d id = %v, want %d", i, response.ID, requests[i].ID)
+		}
+	}
+}
+
+func TestStdioSendBatchRejected(t *testing.T) {
+	// The server only accepts single messages and answers a batch with a parse error
+	script := `read line; echo '{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}'; cat`
+	stdio := NewStdio("sh", []string{"-c", script}, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := stdio.SendBatch(ctx, []transport.JSONRPCRequest{{JSONRPC: "2.0", ID: 1, Method: "tools/list"}})
+	if !errors.Is(err, ErrBatchUnsupported) {
+		t.Fatalf("SendBatch() error = %v, want %v", err, ErrBatchUnsupported)
+	}
+
+	// The transport can still be used for single requests
+	if _, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "ping"}); err != nil {
+		t.Errorf("SendRequest() error = %v", err)
+	}
+}
+
+func TestStreamableHTTPSendBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&requests)
+
+		if r.URL.Query().Get("stream") != "" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := len(requests) - 1; i >= 0; i-- {
+				fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":{\"method\":%q}}\n\n", requests[i].ID, requests[i].Method)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "[")
+		for i := len(requests) - 1; i >= 0; i-- {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"method":%q}}`, requests[i].ID, requests[i].Method)
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+		}
+		fmt.Fprint(w, "]")
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requests := []transport.JSONRPCRequest{
+		{JSONRPC: "2.0", ID: 1, Method: "tools/list"},
+		{JSONRPC: "2.0", ID: 2, Method: "prompts/list"},
+	}
+	for _, url := range []string{server.URL, server.URL + "?stream=1"} {
+		httpTransport, err := NewStreamableHTTP(url, HTTPOptions{})
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP() error = %v", err)
+		}
+
+		responses, err := httpTransport.SendBatch(ctx, requests)
+		if err != nil {
+			t.Fatalf("SendBatch(%s) error = %v", url, err)
+		}
+		for i, response := range responses {
+			expected := fmt.Sprintf(`{"method":%q}`, requests[i].Method)
+			if string(response.Result) != expected {
+				t.Errorf("SendBatch(%s) response %d = %s, want %s", url, i, response.Result, expected)
+			}
+		}
+		_ = httpTransport.Close()
+	}
+}
+
+func TestSendBatchUnsupported(t *testing.T) {
+	_, err := sendBatch(context.Background(), NewRecording(&SSE{}), nil)
+	if !errors.Is(err, ErrBatchUnsupported) {
+		t.Errorf("sendBatch() error = %v, want %v", err, ErrBatchUnsupported)
+	}
+}
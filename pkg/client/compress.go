@@ -0,0 +1,68 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// newHTTPClient creates the HTTP client of the HTTP and SSE transports, which asks servers for
+// gzip-compressed responses.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: &gzipTransport{base: http.DefaultTransport}}
+}
+
+// gzipTransport asks for gzip-compressed responses and decompresses them. Go's transport only
+// does so itself when a request doesn't set Accept-Encoding, which a --header flag may do.
+type gzipTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends req, accepting gzip unless it sets another encoding, and decompresses the
+// body of a gzip response.
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp, nil
+	}
+
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody decompresses a response body. The gzip header is read on the first Read rather
+// than up front, so that waiting for an event stream to start doesn't block RoundTrip.
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+	err    error
+}
+
+// Read reads decompressed data.
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = gzip.NewReader(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+// Close closes the underlying body.
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
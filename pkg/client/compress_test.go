@@ -0,0 +1,43 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+func TestStreamableHTTPGzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		fmt.Fprint(gw, `{"jsonrpc":"2.0","id":1,"result":{"compressed":true}}`)
+		_ = gw.Close()
+	}))
+	t.Cleanup(server.Close)
+
+	// Go's transport leaves the body compressed when the request sets Accept-Encoding itself
+	for _, headers := range []map[string]string{nil, {"Accept-Encoding": "gzip"}} {
+		httpTransport, err := NewStreamableHTTP(server.URL, HTTPOptions{Headers: headers})
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP() error = %v", err)
+		}
+
+		resp, err := httpTransport.SendRequest(context.Background(), transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "ping"})
+		if err != nil {
+			t.Fatalf("SendRequest() with headers %v error = %v", headers, err)
+		}
+		if string(resp.Result) != `{"compressed":true}` {
+			t.Errorf("result with headers %v = %s", headers, resp.Result)
+		}
+		_ = httpTransport.Close()
+	}
+}
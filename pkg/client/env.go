@@ -0,0 +1,67 @@
+package client
+
+import (
+	"os"
+	"strings"
+)
+
+// ExpandEnv replaces the $NAME, ${NAME} and ${env:NAME} references in value with the variables
+// of the current environment. NAME must be a whole identifier of letters, digits and
+// underscores that doesn't start with a digit. Everything else, including references to unset
+// variables and placeholders such as ${workspaceFolder:app}, is copied as written.
+func ExpandEnv(value string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		if value[i] == '$' {
+			if name, n := envReference(value[i+1:]); name != "" {
+				if v, ok := os.LookupEnv(name); ok {
+					b.WriteString(v)
+					i += 1 + n
+					continue
+				}
+			}
+		}
+		b.WriteByte(value[i])
+		i++
+	}
+	return b.String()
+}
+
+// envReference returns the variable name referenced at the start of s, which follows a $, and
+// the number of bytes of s the reference spans. The name is empty if s doesn't start with a
+// valid reference.
+func envReference(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", 0
+		}
+		name := strings.TrimPrefix(s[1:end], "env:")
+		if identifierLength(name) != len(name) {
+			return "", 0
+		}
+		return name, end + 1
+	}
+
+	n := identifierLength(s)
+	return s[:n], n
+}
+
+// identifierLength returns the length of the identifier at the start of s, or 0 if s doesn't
+// start with one.
+func identifierLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return i
+		}
+	}
+	return len(s)
+}
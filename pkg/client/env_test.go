@@ -0,0 +1,38 @@
+package client
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("MCPT_TOKEN", "secret")
+	t.Setenv("MCPT_EMPTY", "")
+
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "Bearer $MCPT_TOKEN", expected: "Bearer secret"},
+		{value: "Bearer ${MCPT_TOKEN}", expected: "Bearer secret"},
+		{value: "Bearer ${env:MCPT_TOKEN}", expected: "Bearer secret"},
+		{value: "${MCPT_TOKEN}-suffix", expected: "secret-suffix"},
+		{value: "[$MCPT_EMPTY]", expected: "[]"},
+		// Only whole identifiers are references
+		{value: "$MCPT_TOKENS", expected: "$MCPT_TOKENS"},
+		{value: "$1abc", expected: "$1abc"},
+		{value: "${MCPT-TOKEN}", expected: "${MCPT-TOKEN}"},
+		// Unset variables, placeholders and stray $ are kept as written
+		{value: "$MCPT_UNSET", expected: "$MCPT_UNSET"},
+		{value: "${workspaceFolder}/app", expected: "${workspaceFolder}/app"},
+		{value: "${workspaceFolder:app}", expected: "${workspaceFolder:app}"},
+		{value: "${input:token}", expected: "${input:token}"},
+		{value: "pa$$word", expected: "pa$$word"},
+		{value: "cost: 5$", expected: "cost: 5$"},
+		{value: "${MCPT_TOKEN", expected: "${MCPT_TOKEN"},
+		{value: "$$MCPT_TOKEN", expected: "$secret"},
+	}
+
+	for _, tc := range testCases {
+		if got := ExpandEnv(tc.value); got != tc.expected {
+			t.Errorf("ExpandEnv(%q) = %q, want %q", tc.value, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// RPCError is a JSON-RPC error object that the server returned instead of a result, such as
+// -32601 for an unknown method or -32602 for invalid params.
+type RPCError struct {
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Code    int             `json:"code"`
+}
+
+// Error returns the message of the error, as the server sent it.
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// TransportError is returned for a request that failed before the server answered it, such
+// as when the connection failed or dropped. The mcp-go client wraps it, so use errors.As to
+// tell it apart from a JSON-RPC error response.
+type TransportError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// newRPCError returns the error object of a JSON-RPC response, or nil if it has none.
+func newRPCError(response *transport.JSONRPCResponse) *RPCError {
+	if response == nil || response.Error == nil {
+		return nil
+	}
+	return &RPCError{
+		Code:    response.Error.Code,
+		Message: response.Error.Message,
+		Data:    append(json.RawMessage(nil), response.Error.Data...),
+	}
+}
+
+// rpcErrorKey is the context key of the slot the JSON-RPC error object of a response is kept in.
+type rpcErrorKey struct{}
+
+// rpcErrorSlot keeps the JSON-RPC error object of the response to a request.
+type rpcErrorSlot struct {
+	mu  sync.Mutex
+	err *RPCError
+}
+
+// WithRPCError returns a context for a single request, such as a CallTool, that keeps the
+// JSON-RPC error object of its response, so that WrapRPCError can return it. Each request
+// gets its own context, so concurrent requests of a client don't see each other's errors.
+func WithRPCError(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rpcErrorKey{}, &rpcErrorSlot{})
+}
+
+// recordRPCError keeps the error object of a response in the slot of ctx, if it has one.
+func recordRPCError(ctx context.Context, response *transport.JSONRPCResponse) {
+	slot, ok := ctx.Value(rpcErrorKey{}).(*rpcErrorSlot)
+	if !ok {
+		return
+	}
+	slot.mu.Lock()
+	slot.err = newRPCError(response)
+	slot.mu.Unlock()
+}
+
+// WrapRPCError returns an *RPCError for an error returned by a request made with a context
+// from WithRPCError, when it was caused by a JSON-RPC error response. The mcp-go client only
+// keeps the message of such errors, so the error object is taken from the context, where the
+// recording transport of the client put it. Other errors, such as transport failures, are
+// returned unchanged.
+func WrapRPCError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+	slot, ok := ctx.Value(rpcErrorKey{}).(*rpcErrorSlot)
+	if !ok {
+		return err
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.err != nil {
+		return slot.err
+	}
+	return err
+}
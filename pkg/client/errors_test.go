@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// failingTransport answers initialize and fails every other request, with a JSON-RPC error
+// for tools/call and resources/read and a transport error otherwise. Both JSON-RPC errors have
+// the same message, but different codes.
+type failingTransport struct {
+	echoTransport
+}
+
+func (f *failingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	switch request.Method {
+	case "initialize":
+		return f.echoTransport.SendRequest(ctx, request)
+	case "tools/call", "resources/read":
+		code := mcp.INVALID_PARAMS
+		if request.Method == "resources/read" {
+			code = mcp.METHOD_NOT_FOUND
+		}
+		response := &transport.JSONRPCResponse{JSONRPC: "2.0", ID: &request.ID}
+		response.Error = &struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}{Code: code, Message: "missing argument: path", Data: json.RawMessage(`{"argument":"path"}`)}
+		return response, nil
+	default:
+		return nil, errors.New("connection reset")
+	}
+}
+
+func TestWrapRPCError(t *testing.T) {
+	c := mcpclient.NewClient(NewRecording(&failingTransport{}))
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	ctx := WithRPCError(context.Background())
+	_, err := c.CallTool(ctx, mcp.CallToolRequest{})
+	err = WrapRPCError(ctx, err)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("WrapRPCError() = %#v, want an *RPCError", err)
+	}
+	if rpcErr.Code != mcp.INVALID_PARAMS || rpcErr.Message != "missing argument: path" || string(rpcErr.Data) != `{"argument":"path"}` {
+		t.Errorf("unexpected RPCError: %+v", rpcErr)
+	}
+
+	// Transport failures are not JSON-RPC errors
+	ctx = WithRPCError(context.Background())
+	_, err = c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err = WrapRPCError(ctx, err); errors.As(err, &rpcErr) {
+		t.Errorf("WrapRPCError() of a transport error = %#v", err)
+	}
+
+	// Without a context from WithRPCError, the error is returned unchanged
+	_, err = c.CallTool(context.Background(), mcp.CallToolRequest{})
+	if err = WrapRPCError(context.Background(), err); errors.As(err, &rpcErr) {
+		t.Errorf("WrapRPCError() without a recorded error = %#v", err)
+	}
+
+	if err := WrapRPCError(ctx, nil); err != nil {
+		t.Errorf("WrapRPCError(nil) = %v", err)
+	}
+}
+
+func TestWrapRPCErrorConcurrent(t *testing.T) {
+	c := mcpclient.NewClient(NewRecording(&failingTransport{}))
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	// Concurrent requests fail with the same message, and each must get its own error code
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx := WithRPCError(context.Background())
+			var err error
+			expected := mcp.INVALID_PARAMS
+			if i%2 == 0 {
+				_, err = c.CallTool(ctx, mcp.CallToolRequest{})
+			} else {
+				_, err = c.ReadResource(ctx, mcp.ReadResourceRequest{})
+				expected = mcp.METHOD_NOT_FOUND
+			}
+
+			var rpcErr *RPCError
+			if !errors.As(WrapRPCError(ctx, err), &rpcErr) || rpcErr.Code != expected {
+				t.Errorf("request %d: WrapRPCError() = %v, want code %d", i, rpcErr, expected)
+			}
+		}()
+	}
+	wg.Wait()
+}
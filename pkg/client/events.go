@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// eventReader parses server-sent events from a stream.
+type eventReader struct {
+	reader *bufio.Reader
+}
+
+func newEventReader(r io.Reader) eventReader {
+	return eventReader{reader: bufio.NewReader(r)}
+}
+
+// next reads the next complete event from the stream.
+// Multi-line data fields are joined with newlines.
+func (er eventReader) next() (string, string, error) {
+	var event string
+	var data []string
+
+	for {
+		line, err := er.reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(data) > 0 {
+				return eventName(event), strings.Join(data, "\n"), nil
+			}
+			return "", "", err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				return eventName(event), strings.Join(data, "\n"), nil
+			}
+			event = ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+}
+
+// eventName returns the event type, which defaults to "message" when the server omits it.
+func eventName(event string) string {
+	if event == "" {
+		return "message"
+	}
+	return event
+}
+
+// setHeaders adds headers to an outgoing request. $VAR and ${VAR} references in the values
+// are resolved from the environment with ExpandEnv on every request, so secrets never have to
+// be passed on the command line and a rotated token is picked up by long-running sessions.
+func setHeaders(req *http.Request, headers map[string]string) {
+	for k, v := range headers {
+		req.Header.Set(k, ExpandEnv(v))
+	}
+}
@@ -0,0 +1,387 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// headerSessionID carries the session assigned by the server during initialize.
+const headerSessionID = "Mcp-Session-Id"
+
+// HTTPOptions configures a streamable HTTP transport.
+type HTTPOptions struct {
+	// Headers are sent with every request.
+	Headers map[string]string
+}
+
+// StreamableHTTP implements the MCP streamable HTTP transport. Each JSON-RPC message is
+// sent as its own POST request, and the server answers either with a single JSON response
+// or with an event stream that ends with the response. Requests the server sends on such a
+// stream, such as roots/list, are answered with the request handler in a POST of their own.
+type StreamableHTTP struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	headers    map[string]string
+
+	sessionID atomic.Value // string
+
+	notifyMu       sync.RWMutex
+	onNotification func(mcp.JSONRPCNotification)
+	onRequest      RequestHandler
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStreamableHTTP creates a streamable HTTP transport for the given server URL.
+func NewStreamableHTTP(baseURL string, options HTTPOptions) (*StreamableHTTP, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	headers := make(map[string]string, len(options.Headers))
+	for k, v := range options.Headers {
+		headers[k] = v
+	}
+
+	t := &StreamableHTTP{
+		baseURL:    parsedURL,
+		httpClient: newHTTPClient(),
+		headers:    headers,
+		closed:     make(chan struct{}),
+	}
+	t.sessionID.Store("")
+
+	return t, nil
+}
+
+// Start is a no-op, since streamable HTTP doesn't keep a persistent connection.
+func (t *StreamableHTTP) Start(_ context.Context) error {
+	return nil
+}
+
+// SendRequest posts a JSON-RPC request and waits for its response.
+func (t *StreamableHTTP) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ctx, cancel := t.withClose(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := t.post(ctx, body, "application/json, text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		if resp.StatusCode == http.StatusNotFound {
+			t.sessionID.Store("")
+			return nil, fmt.Errorf("session terminated (404). need to re-initialize")
+		}
+
+		respBody, _ := io.ReadAll(resp.Body)
+		var errResponse transport.JSONRPCResponse
+		if json.Unmarshal(respBody, &errResponse) == nil && errResponse.Error != nil {
+			return &errResponse, nil
+		}
+		return nil, &statusError{code: resp.StatusCode, body: string(respBody)}
+	}
+
+	if request.Method == "initialize" {
+		if sessionID := resp.Header.Get(headerSessionID); sessionID != "" {
+			t.sessionID.Store(sessionID)
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var response transport.JSONRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if response.ID == nil {
+			return nil, fmt.Errorf("response should contain RPC id: %v", response)
+		}
+		if *response.ID != request.ID {
+			return nil, fmt.Errorf("response id %d doesn't match request id %d", *response.ID, request.ID)
+		}
+		return &response, nil
+	case "text/event-stream":
+		return t.readResponseStream(ctx, newEventReader(resp.Body), map[int64]bool{request.ID: true})
+	default:
+		return nil, fmt.Errorf("unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+}
+
+// readResponseStream dispatches notifications and requests of the server from a response
+// event stream until the response to one of the requests with the given ids arrives.
+// Responses to other requests are dropped.
+func (t *StreamableHTTP) readResponseStream(ctx context.Context, events eventReader, ids map[int64]bool) (*transport.JSONRPCResponse, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		event, data, err := events.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("event stream ended without a response")
+			}
+			return nil, fmt.Errorf("error reading event stream: %w", err)
+		}
+		if event != "message" {
+			continue
+		}
+
+		if request, ok := parseServerRequest([]byte(data)); ok {
+			// The server may wait for the answer before it sends the response
+			go t.handleRequest(request)
+			continue
+		}
+
+		var message transport.JSONRPCResponse
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			fmt.Fprintf(os.Stderr, "Error unmarshaling message: %v\n", err)
+			continue
+		}
+
+		if message.ID == nil {
+			var notification mcp.JSONRPCNotification
+			if err := json.Unmarshal([]byte(data), &notification); err != nil {
+				continue
+			}
+			t.notifyMu.RLock()
+			if t.onNotification != nil {
+				t.onNotification(notification)
+			}
+			t.notifyMu.RUnlock()
+			continue
+		}
+
+		if ids[*message.ID] {
+			return &message, nil
+		}
+	}
+}
+
+// handleRequest answers a request of the server with the request handler, posting the
+// response to the server. The context of the handler is canceled when the transport is
+// closed.
+func (t *StreamableHTTP) handleRequest(request serverRequest) {
+	ctx, cancel := t.withClose(context.Background())
+	defer cancel()
+
+	t.notifyMu.RLock()
+	handler := t.onRequest
+	t.notifyMu.RUnlock()
+
+	body, err := request.answer(ctx, handler)
+	if err != nil {
+		return
+	}
+	resp, err := t.post(ctx, body, "")
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error answering %s request: %v\n", request.Method, err)
+		}
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// SendBatch posts requests as one JSON-RPC batch and returns their responses in the order of
+// the requests. The server answers either with a JSON array of responses or with an event
+// stream that carries them.
+func (t *StreamableHTTP) SendBatch(ctx context.Context, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error) {
+	ctx, cancel := t.withClose(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	resp, err := t.post(ctx, body, "application/json, text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		var errResponse transport.JSONRPCResponse
+		if json.Unmarshal(respBody, &errResponse) == nil && errResponse.Error != nil {
+			return nil, batchError(&errResponse)
+		}
+		return nil, fmt.Errorf("%w: request failed with status %d: %s", ErrBatchUnsupported, resp.StatusCode, respBody)
+	}
+
+	responses := make(map[int64]*transport.JSONRPCResponse, len(requests))
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		var messages []transport.JSONRPCResponse
+		if err := json.Unmarshal(respBody, &messages); err != nil {
+			var errResponse transport.JSONRPCResponse
+			if json.Unmarshal(respBody, &errResponse) == nil {
+				return nil, batchError(&errResponse)
+			}
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		for i := range messages {
+			if messages[i].ID != nil {
+				responses[*messages[i].ID] = &messages[i]
+			}
+		}
+	case "text/event-stream":
+		ids := make(map[int64]bool, len(requests))
+		for _, request := range requests {
+			ids[request.ID] = true
+		}
+		events := newEventReader(resp.Body)
+		for len(ids) > 0 {
+			response, err := t.readResponseStream(ctx, events, ids)
+			if err != nil {
+				return nil, err
+			}
+			responses[*response.ID] = response
+			delete(ids, *response.ID)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+	return orderResponses(requests, responses)
+}
+
+// SendNotification posts a JSON-RPC notification to the server.
+func (t *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	ctx, cancel := t.withClose(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	resp, err := t.post(ctx, body, "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("notification failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// SetNotificationHandler sets the handler for server notifications.
+func (t *StreamableHTTP) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	t.notifyMu.Lock()
+	defer t.notifyMu.Unlock()
+	t.onNotification = handler
+}
+
+// SetRequestHandler sets the handler for requests the server sends to the client, such as
+// roots/list.
+func (t *StreamableHTTP) SetRequestHandler(handler RequestHandler) {
+	t.notifyMu.Lock()
+	defer t.notifyMu.Unlock()
+	t.onRequest = handler
+}
+
+// Close cancels in-flight requests and tells the server to end the session.
+func (t *StreamableHTTP) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+
+		sessionID, _ := t.sessionID.Load().(string)
+		if sessionID == "" {
+			return
+		}
+		t.sessionID.Store("")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.baseURL.String(), nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set(headerSessionID, sessionID)
+		setHeaders(req, t.headers)
+
+		if resp, err := t.httpClient.Do(req); err == nil {
+			_ = resp.Body.Close()
+		}
+	})
+
+	return nil
+}
+
+// GetSessionID returns the session assigned by the server, if any.
+func (t *StreamableHTTP) GetSessionID() string {
+	sessionID, _ := t.sessionID.Load().(string)
+	return sessionID
+}
+
+// post sends a JSON-RPC message to the server.
+func (t *StreamableHTTP) post(ctx context.Context, body []byte, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if sessionID := t.GetSessionID(); sessionID != "" {
+		req.Header.Set(headerSessionID, sessionID)
+	}
+	setHeaders(req, t.headers)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// withClose returns a context that is also cancelled when the transport is closed.
+func (t *StreamableHTTP) withClose(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-t.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
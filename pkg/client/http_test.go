@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStreamableHTTP(t *testing.T) {
+	var mu sync.Mutex
+	var authHeaders []string
+	var sessionHeaders []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		sessionHeaders = append(sessionHeaders, r.Header.Get(headerSessionID))
+		mu.Unlock()
+
+		switch request.Method {
+		case "initialize":
+			w.Header().Set(headerSessionID, "session-1")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"ok":true}}`, request.ID)
+		case "tools/call":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%d,\n", request.ID)
+			fmt.Fprint(w, "data: \"result\":{\"streamed\":true}}\n\n")
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("MCP_TEST_TOKEN", "first")
+	httpTransport, err := NewStreamableHTTP(server.URL, HTTPOptions{
+		Headers: map[string]string{"Authorization": "Bearer ${MCP_TEST_TOKEN}"},
+	})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = httpTransport.Close() })
+
+	var notifications []string
+	httpTransport.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		notifications = append(notifications, notification.Method)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := httpTransport.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	if err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	if string(resp.Result) != `{"ok":true}` {
+		t.Errorf("initialize result = %s", resp.Result)
+	}
+
+	// The token is read again for every request
+	t.Setenv("MCP_TEST_TOKEN", "second")
+
+	resp, err = httpTransport.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call"})
+	if err != nil {
+		t.Fatalf("tools/call error = %v", err)
+	}
+	if string(resp.Result) != `{"streamed":true}` {
+		t.Errorf("tools/call result = %s", resp.Result)
+	}
+
+	if len(notifications) != 1 || notifications[0] != "notifications/progress" {
+		t.Errorf("notifications = %v", notifications)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectedAuth := []string{"Bearer first", "Bearer second"}
+	expectedSession := []string{"", "session-1"}
+	for i := range expectedAuth {
+		if authHeaders[i] != expectedAuth[i] {
+			t.Errorf("request %d Authorization = %q, want %q", i+1, authHeaders[i], expectedAuth[i])
+		}
+		if sessionHeaders[i] != expectedSession[i] {
+			t.Errorf("request %d session = %q, want %q", i+1, sessionHeaders[i], expectedSession[i])
+		}
+	}
+}
+
+func TestStreamableHTTPServerRequest(t *testing.T) {
+	answers := make(chan json.RawMessage, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+
+		if message.Method == "" {
+			// The client's answer to the roots/list request
+			answers <- message.Result
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Ask for the roots and a response to another request first, then answer once the
+		// roots arrived
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"r1\",\"method\":\"roots/list\"}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":99,\"result\":{\"other\":true}}\n\n")
+		w.(http.Flusher).Flush()
+
+		select {
+		case roots := <-answers:
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":%s}\n\n", message.ID, roots)
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	httpTransport, err := NewStreamableHTTP(server.URL, HTTPOptions{})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = httpTransport.Close() })
+
+	httpTransport.SetRequestHandler(func(_ context.Context, method string, _ json.RawMessage) (any, error) {
+		return map[string]any{"method": method}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := httpTransport.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call"})
+	if err != nil {
+		t.Fatalf("tools/call error = %v", err)
+	}
+	if *resp.ID != 1 || string(resp.Result) != `{"method":"roots/list"}` {
+		t.Errorf("tools/call response = %d %s", *resp.ID, resp.Result)
+	}
+}
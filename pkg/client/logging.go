@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// redacted replaces secret values in log output.
+const redacted = "[REDACTED]"
+
+// LoggingOptions configures a logging transport.
+type LoggingOptions struct {
+	// Output receives the log lines.
+	Output io.Writer
+	// Secrets are values, such as header values, that are replaced in the log output. They
+	// may reference environment variables, which are resolved when a line is written.
+	Secrets []string
+	// Closer, such as the file Output writes to, is closed after the wrapped transport.
+	Closer io.Closer
+}
+
+// Logging wraps a transport and logs every JSON-RPC message it sends and receives.
+type Logging struct {
+	transport.Interface
+
+	output  io.Writer
+	secrets []string
+	closer  io.Closer
+	mu      sync.Mutex
+}
+
+// NewLogging wraps inner so that its requests, responses and notifications are logged.
+func NewLogging(inner transport.Interface, options LoggingOptions) *Logging {
+	return &Logging{
+		Interface: inner,
+		output:    options.Output,
+		secrets:   options.Secrets,
+		closer:    options.Closer,
+	}
+}
+
+// Unwrap returns the wrapped transport.
+func (l *Logging) Unwrap() transport.Interface {
+	return l.Interface
+}
+
+// Close closes the wrapped transport and then the closer of the options, if any.
+func (l *Logging) Close() error {
+	err := l.Interface.Close()
+	if l.closer != nil {
+		if closeErr := l.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SendRequest logs the request and its response or error.
+func (l *Logging) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	l.logJSON("Sending request", request)
+
+	start := time.Now()
+	resp, err := l.Interface.SendRequest(ctx, request)
+	if err != nil {
+		l.log(fmt.Sprintf("Request %d failed after %v: %v", request.ID, time.Since(start), err))
+		return nil, err
+	}
+
+	l.logJSON(fmt.Sprintf("Received response after %v", time.Since(start)), resp)
+	return resp, nil
+}
+
+// SendBatch logs the batch and its responses or error.
+func (l *Logging) SendBatch(ctx context.Context, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error) {
+	l.logJSON("Sending batch", requests)
+
+	start := time.Now()
+	responses, err := sendBatch(ctx, l.Interface, requests)
+	if err != nil {
+		l.log(fmt.Sprintf("Batch failed after %v: %v", time.Since(start), err))
+		return nil, err
+	}
+
+	l.logJSON(fmt.Sprintf("Received batch response after %v", time.Since(start)), responses)
+	return responses, nil
+}
+
+// SendNotification logs the notification before sending it.
+func (l *Logging) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	l.logJSON("Sending notification", notification)
+	return l.Interface.SendNotification(ctx, notification)
+}
+
+// SetNotificationHandler logs notifications from the server before handling them.
+func (l *Logging) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	l.Interface.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		l.logJSON("Received notification", notification)
+		if handler != nil {
+			handler(notification)
+		}
+	})
+}
+
+// log writes a message to the log output with a timestamp.
+func (l *Logging) log(message string) {
+	timestamp := time.Now().Format(time.RFC3339)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.output, "[%s] %s\n", timestamp, l.redact(message))
+}
+
+// logJSON writes a JSON-formatted message to the log output with a timestamp.
+func (l *Logging) logJSON(label string, v any) {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		l.log(fmt.Sprintf("Error marshaling %s: %v", label, err))
+		return
+	}
+	l.log(fmt.Sprintf("%s: %s", label, string(jsonBytes)))
+}
+
+// redact replaces the secrets in message.
+func (l *Logging) redact(message string) string {
+	for _, secret := range l.secrets {
+		value := ExpandEnv(secret)
+		if value == "" {
+			continue
+		}
+		message = strings.ReplaceAll(message, value, redacted)
+
+		// Also hide the credentials of values like "Bearer <token>" when they appear alone
+		if _, credentials, found := strings.Cut(value, " "); found && credentials != "" {
+			message = strings.ReplaceAll(message, credentials, redacted)
+		}
+	}
+	return message
+}
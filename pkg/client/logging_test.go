@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// echoTransport answers every request with its params as the result.
+type echoTransport struct {
+	onNotification func(mcp.JSONRPCNotification)
+}
+
+func (e *echoTransport) Start(_ context.Context) error { return nil }
+
+func (e *echoTransport) SendRequest(_ context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	result, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &transport.JSONRPCResponse{JSONRPC: "2.0", ID: &request.ID, Result: result}, nil
+}
+
+func (e *echoTransport) SendNotification(_ context.Context, _ mcp.JSONRPCNotification) error {
+	return nil
+}
+
+func (e *echoTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	e.onNotification = handler
+}
+
+func (e *echoTransport) Close() error { return nil }
+
+func TestLogging(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "s3cret-token")
+
+	inner := &echoTransport{}
+	var output bytes.Buffer
+	logging := NewLogging(inner, LoggingOptions{
+		Output:  &output,
+		Secrets: []string{"Bearer ${MCP_TEST_TOKEN}"},
+	})
+
+	var handled []string
+	logging.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		handled = append(handled, notification.Method)
+	})
+
+	_, err := logging.SendRequest(context.Background(), transport.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"token": "s3cret-token"},
+	})
+	if err != nil {
+		t.Fatalf("SendRequest() error = %v", err)
+	}
+
+	notification := mcp.JSONRPCNotification{JSONRPC: "2.0"}
+	notification.Method = "notifications/progress"
+	inner.onNotification(notification)
+
+	if len(handled) != 1 || handled[0] != "notifications/progress" {
+		t.Errorf("handled notifications = %v", handled)
+	}
+
+	log := output.String()
+	for _, expected := range []string{"Sending request", "tools/call", "Received response", "Received notification", redacted} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("log does not contain %q:\n%s", expected, log)
+		}
+	}
+	if strings.Contains(log, "s3cret-token") {
+		t.Errorf("log contains the secret:\n%s", log)
+	}
+}
+
+func TestRecording(t *testing.T) {
+	recording := NewRecording(&echoTransport{})
+	if result := recording.LastResult(); result != nil {
+		t.Errorf("LastResult() before any request = %s, want nil", result)
+	}
+
+	_, err := recording.SendRequest(context.Background(), transport.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "echo", "extra": []int{1, 2}},
+	})
+	if err != nil {
+		t.Fatalf("SendRequest() error = %v", err)
+	}
+
+	if result := string(recording.LastResult()); result != `{"extra":[1,2],"name":"echo"}` {
+		t.Errorf("LastResult() = %s", result)
+	}
+
+	logged := NewLogging(recording, LoggingOptions{Output: &bytes.Buffer{}})
+	if found, ok := GetRecording(mcpclient.NewClient(logged)); !ok || found != recording {
+		t.Errorf("GetRecording() = %v, %v; want the wrapped recording", found, ok)
+	}
+	if _, ok := GetRecording(mcpclient.NewClient(&echoTransport{})); ok {
+		t.Error("GetRecording() found a recording on a client without one")
+	}
+}
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLoggingClosesCloser(t *testing.T) {
+	closer := &closeRecorder{}
+	logging := NewLogging(&echoTransport{}, LoggingOptions{Output: &bytes.Buffer{}, Closer: closer})
+
+	if err := mcpclient.NewClient(logging).Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !closer.closed {
+		t.Error("Expected closing the client to close the log output")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package client
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows.
+func setProcessGroup(_ *exec.Cmd) {}
+
+// signalProcessGroup kills the command's process. Windows has no SIGTERM, so the process is
+// killed whether or not force is set.
+func signalProcessGroup(cmd *exec.Cmd, _ bool) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// Recording wraps a transport and keeps the result of the last response it received exactly
+// as the server sent it. The JSON-RPC error object of a response is kept in the context of its
+// request when it comes from WithRPCError.
+type Recording struct {
+	transport.Interface
+
+	mu   sync.Mutex
+	last json.RawMessage
+}
+
+// NewRecording wraps inner so that the raw result of its responses can be retrieved.
+func NewRecording(inner transport.Interface) *Recording {
+	return &Recording{Interface: inner}
+}
+
+// Unwrap returns the wrapped transport.
+func (r *Recording) Unwrap() transport.Interface {
+	return r.Interface
+}
+
+// SendRequest sends the request and records the result of its response. Errors of the
+// wrapped transport are returned as a *TransportError.
+func (r *Recording) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	resp, err := r.Interface.SendRequest(ctx, request)
+	if err != nil {
+		return nil, &TransportError{Err: err}
+	}
+
+	recordRPCError(ctx, resp)
+	r.mu.Lock()
+	r.last = append(json.RawMessage(nil), resp.Result...)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// LastResult returns the result of the last response, or nil if none was received.
+func (r *Recording) LastResult() json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// GetRecording returns the recording transport of a client, if it has one.
+func GetRecording(c *mcpclient.Client) (*Recording, bool) {
+	t := c.GetTransport()
+	for {
+		if recording, ok := t.(*Recording); ok {
+			return recording, true
+		}
+		wrapper, ok := t.(interface{ Unwrap() transport.Interface })
+		if !ok {
+			return nil, false
+		}
+		t = wrapper.Unwrap()
+	}
+}
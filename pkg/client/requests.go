@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MethodCreateMessage is the method of the requests servers with the sampling capability send
+// to have the client run an LLM.
+const MethodCreateMessage = "sampling/createMessage"
+
+// Error codes of the JSON-RPC error responses sent to requests of the server.
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// RequestHandler answers a request the server sent to the client. The result it returns is
+// sent back as the result of the response, and an error as a JSON-RPC error response, with
+// the code of an *RPCError or else an internal error.
+type RequestHandler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// SamplingHandler fulfills a sampling/createMessage request of the server, such as by running
+// the prompt through an LLM, and returns the completion.
+type SamplingHandler func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+// serverRequest is a request the server sent to the client.
+type serverRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// parseServerRequest returns the request in a message, or false if the message is a response
+// or a notification.
+func parseServerRequest(data []byte) (serverRequest, bool) {
+	var request serverRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return request, false
+	}
+	if request.Method == "" || len(request.ID) == 0 || string(request.ID) == "null" {
+		return request, false
+	}
+	return request, true
+}
+
+// answer runs handler for the request and returns the response to send. Without a handler,
+// the request is answered with a method not found error, so that the server doesn't wait for
+// a response that never comes.
+func (r serverRequest) answer(ctx context.Context, handler RequestHandler) ([]byte, error) {
+	response := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": r.ID}
+
+	var result any
+	err := error(&RPCError{Code: codeMethodNotFound, Message: "method not found: " + r.Method})
+	if handler != nil {
+		result, err = handler(ctx, r.Method, r.Params)
+	}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: codeInternalError, Message: err.Error()}
+		}
+		response["error"] = rpcErr
+	} else {
+		response["result"] = result
+	}
+	return json.Marshal(response)
+}
+
+// SetRequestHandler sets the handler for requests the server of c sends to the client. It
+// returns false if the transport of c can't receive requests.
+func SetRequestHandler(c *mcpclient.Client, handler RequestHandler) bool {
+	t := c.GetTransport()
+	for {
+		if receiver, ok := t.(interface{ SetRequestHandler(RequestHandler) }); ok {
+			receiver.SetRequestHandler(handler)
+			return true
+		}
+		wrapper, ok := t.(interface{ Unwrap() transport.Interface })
+		if !ok {
+			return false
+		}
+		t = wrapper.Unwrap()
+	}
+}
+
+// SetSamplingHandler answers the sampling/createMessage requests of the server of c with
+// handler, and any other request with a method not found error. It returns false if the
+// transport of c can't receive requests. Declare the sampling capability in the initialize
+// request for servers to send them.
+func SetSamplingHandler(c *mcpclient.Client, handler SamplingHandler) bool {
+	return SetRequestHandler(c, samplingRequestHandler(handler))
+}
+
+// samplingRequestHandler adapts a sampling handler to the requests of the server.
+func samplingRequestHandler(handler SamplingHandler) RequestHandler {
+	return func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		if method != MethodCreateMessage {
+			return nil, &RPCError{Code: codeMethodNotFound, Message: "method not found: " + method}
+		}
+
+		request := mcp.CreateMessageRequest{}
+		request.Method = method
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &request.Params); err != nil {
+				return nil, &RPCError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+			}
+		}
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,697 @@
+/*
+Package client provides MCP client transports used by the CLI commands.
+*/
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMaxRetries is the default number of reconnect attempts after an SSE stream drops.
+const DefaultMaxRetries = 5
+
+const (
+	// endpointTimeout is how long to wait for the server to announce its message endpoint.
+	endpointTimeout = 30 * time.Second
+	// restoreTimeout is how long to wait for each replayed request while restoring a session.
+	restoreTimeout = 30 * time.Second
+)
+
+// errSessionLost is returned to callers when the stream dropped and could not be restored.
+var errSessionLost = errors.New("SSE connection lost")
+
+// errConnectionDropped fails the requests that were waiting for a response when the stream
+// dropped. Whether they are sent again is up to the caller, since the server may already have
+// acted on them.
+var errConnectionDropped = errors.New("SSE connection dropped before the response arrived")
+
+// SSEOptions configures an SSE transport.
+type SSEOptions struct {
+	// Headers are sent with the event stream request and with every message.
+	Headers map[string]string
+	// MaxRetries limits how many times the transport reconnects after the stream drops.
+	// Zero disables reconnection.
+	MaxRetries int
+}
+
+// SSE implements the MCP SSE transport and transparently reconnects when the event stream
+// drops. After reconnecting it replays the initialize handshake and any active resource
+// subscriptions. Requests that were still waiting for a response fail with a transport
+// error, while later requests wait for the session to be restored.
+type SSE struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	headers    map[string]string
+	maxRetries int
+
+	// Backoff between reconnect attempts, doubled after each failure.
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed atomic.Bool
+
+	mu            sync.Mutex
+	endpoint      *url.URL
+	ready         chan struct{} // closed while the session is usable
+	generation    int           // incremented on every reconnect
+	attempts      int           // reconnect attempts since the session was last usable
+	restoreErr    error         // why restoring the current stream failed, if it did
+	failed        chan struct{} // closed once reconnecting has given up
+	err           error
+	pending       map[int64]*pendingRequest
+	initRequest   *transport.JSONRPCRequest
+	subscriptions map[string]transport.JSONRPCRequest
+
+	notifyMu       sync.RWMutex
+	onNotification func(mcp.JSONRPCNotification)
+	onRequest      RequestHandler
+}
+
+// pendingRequest is a request waiting for its response on the event stream.
+type pendingRequest struct {
+	response chan *transport.JSONRPCResponse
+	// err is set before response is closed without a response.
+	err error
+}
+
+// sseStream is a single connection to the server's event stream.
+type sseStream struct {
+	eventReader
+	body   io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// NewSSE creates a reconnecting SSE transport for the given server URL.
+func NewSSE(baseURL string, options SSEOptions) (*SSE, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	headers := make(map[string]string, len(options.Headers))
+	for k, v := range options.Headers {
+		headers[k] = v
+	}
+
+	return &SSE{
+		baseURL:        parsedURL,
+		httpClient:     newHTTPClient(),
+		headers:        headers,
+		maxRetries:     options.MaxRetries,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     30 * time.Second,
+		ready:          make(chan struct{}),
+		failed:         make(chan struct{}),
+		pending:        make(map[int64]*pendingRequest),
+		subscriptions:  make(map[string]transport.JSONRPCRequest),
+	}, nil
+}
+
+// Start opens the event stream and waits for the server to announce its message endpoint.
+func (s *SSE) Start(ctx context.Context) error {
+	if s.ctx != nil {
+		return fmt.Errorf("has already started")
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	stream, err := s.connect()
+	if err != nil {
+		s.cancel()
+		return err
+	}
+
+	close(s.ready)
+	go s.run(stream)
+	return nil
+}
+
+// connect opens a new event stream and reads it until the endpoint event arrives.
+func (s *SSE) connect() (*sseStream, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL.String(), nil)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Connection", "keep-alive")
+	setHeaders(req, s.headers)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to SSE stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	stream := &sseStream{eventReader: newEventReader(resp.Body), body: resp.Body, cancel: cancel}
+
+	// Cancelling the request unblocks the read below if the endpoint never arrives
+	timer := time.AfterFunc(endpointTimeout, cancel)
+	defer timer.Stop()
+
+	for {
+		event, data, err := stream.next()
+		if err != nil {
+			stream.close()
+			if ctx.Err() != nil && s.ctx.Err() == nil {
+				return nil, fmt.Errorf("timeout waiting for endpoint")
+			}
+			return nil, fmt.Errorf("error waiting for endpoint: %w", err)
+		}
+		if event != "endpoint" {
+			s.handleEvent(event, data)
+			continue
+		}
+
+		endpoint, err := s.parseEndpoint(data)
+		if err != nil {
+			stream.close()
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.endpoint = endpoint
+		s.mu.Unlock()
+		return stream, nil
+	}
+}
+
+// run reads the event stream and reconnects whenever it drops.
+func (s *SSE) run(stream *sseStream) {
+	for {
+		err := s.readStream(stream)
+		stream.close()
+		if s.closed.Load() {
+			return
+		}
+
+		s.mu.Lock()
+		if s.restoreErr != nil {
+			err = s.restoreErr
+			s.restoreErr = nil
+		}
+		s.mu.Unlock()
+
+		stream, err = s.reconnect(err)
+		if err != nil {
+			s.fail(err)
+			return
+		}
+
+		// Responses to the replayed requests arrive on the stream, so restore concurrently
+		s.mu.Lock()
+		generation := s.generation
+		s.mu.Unlock()
+		go func(stream *sseStream) {
+			if err := s.restore(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error restoring SSE session: %v\n", err)
+				// Closing the stream reconnects again, counting against the same retries
+				s.mu.Lock()
+				if s.generation == generation {
+					s.restoreErr = err
+				}
+				s.mu.Unlock()
+				stream.close()
+				return
+			}
+			s.mu.Lock()
+			if s.generation == generation {
+				s.attempts = 0
+				close(s.ready)
+			}
+			s.mu.Unlock()
+		}(stream)
+	}
+}
+
+// readStream dispatches events until the stream ends or fails.
+func (s *SSE) readStream(stream *sseStream) error {
+	for {
+		event, data, err := stream.next()
+		if err != nil {
+			return err
+		}
+		s.handleEvent(event, data)
+	}
+}
+
+// reconnect opens a new event stream, backing off exponentially between attempts. Attempts
+// whose session could not be restored count against the retries, so the budget is only reset
+// once a restored session is usable again.
+func (s *SSE) reconnect(cause error) (*sseStream, error) {
+	s.mu.Lock()
+	select {
+	case <-s.ready:
+		s.ready = make(chan struct{})
+	default:
+	}
+	s.endpoint = nil
+	s.generation++
+	s.failPending(fmt.Errorf("%w: %v", errConnectionDropped, cause))
+	attempts := s.attempts
+	s.mu.Unlock()
+
+	backoff := s.initialBackoff
+	for i := 0; i < attempts; i++ {
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+	for attempt := attempts + 1; attempt <= s.maxRetries; attempt++ {
+		s.mu.Lock()
+		s.attempts = attempt
+		s.mu.Unlock()
+
+		fmt.Fprintf(os.Stderr, "SSE connection lost (%v), reconnecting (attempt %d/%d)...\n",
+			cause, attempt, s.maxRetries)
+
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		}
+
+		stream, err := s.connect()
+		if err == nil {
+			fmt.Fprintln(os.Stderr, "SSE connection restored")
+			return stream, nil
+		}
+
+		cause = err
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+
+	return nil, fmt.Errorf("%w after %d reconnect attempts: %v", errSessionLost, s.maxRetries, cause)
+}
+
+// restore replays the initialize handshake and active subscriptions on a new session.
+func (s *SSE) restore() error {
+	s.mu.Lock()
+	initRequest := s.initRequest
+	subscriptions := make([]transport.JSONRPCRequest, 0, len(s.subscriptions))
+	for _, request := range s.subscriptions {
+		subscriptions = append(subscriptions, request)
+	}
+	s.mu.Unlock()
+
+	if initRequest != nil {
+		if err := s.replay(*initRequest); err != nil {
+			return fmt.Errorf("error re-initializing: %w", err)
+		}
+
+		notification := mcp.JSONRPCNotification{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{
+				Method: "notifications/initialized",
+			},
+		}
+		body, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+		if err := s.post(s.ctx, body); err != nil {
+			return fmt.Errorf("error re-initializing: %w", err)
+		}
+	}
+
+	for _, request := range subscriptions {
+		if err := s.replay(request); err != nil {
+			fmt.Fprintf(os.Stderr, "Error re-subscribing: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// replay sends a previously completed request again and waits for its response.
+func (s *SSE) replay(request transport.JSONRPCRequest) error {
+	ctx, cancel := context.WithTimeout(s.ctx, restoreTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	pending, err := s.register(request.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unregister(request.ID, pending)
+
+	if err := s.post(ctx, body); err != nil {
+		return err
+	}
+
+	select {
+	case resp, ok := <-pending.response:
+		if !ok {
+			return pending.err
+		}
+		if resp.Error != nil {
+			return errors.New(resp.Error.Message)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendRequest sends a JSON-RPC request and waits for its response on the event stream.
+func (s *SSE) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if s.ctx == nil {
+		return nil, fmt.Errorf("transport not started yet")
+	}
+	if s.closed.Load() {
+		return nil, fmt.Errorf("transport has been closed")
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	s.track(request)
+
+	if err := s.waitReady(ctx); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.register(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unregister(request.ID, pending)
+
+	if err := s.post(ctx, body); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-pending.response:
+		if !ok {
+			return nil, pending.err
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendNotification sends a JSON-RPC notification to the server.
+func (s *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	if s.ctx == nil {
+		return fmt.Errorf("transport not started yet")
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	if err := s.waitReady(ctx); err != nil {
+		return err
+	}
+	return s.post(ctx, body)
+}
+
+// SetNotificationHandler sets the handler for server notifications.
+func (s *SSE) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	s.onNotification = handler
+}
+
+// SetRequestHandler sets the handler for requests the server sends to the client, such as
+// sampling/createMessage.
+func (s *SSE) SetRequestHandler(handler RequestHandler) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	s.onRequest = handler
+}
+
+// Close stops the event stream and fails any requests still waiting for a response.
+func (s *SSE) Close() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Lock()
+	s.failPending(fmt.Errorf("transport has been closed"))
+	s.mu.Unlock()
+
+	return nil
+}
+
+// track remembers the requests that have to be replayed after a reconnect.
+func (s *SSE) track(request transport.JSONRPCRequest) {
+	switch request.Method {
+	case "initialize":
+		s.mu.Lock()
+		s.initRequest = &request
+		s.mu.Unlock()
+	case "resources/subscribe", "resources/unsubscribe":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		data, err := json.Marshal(request.Params)
+		if err != nil || json.Unmarshal(data, &params) != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if request.Method == "resources/subscribe" {
+			s.subscriptions[params.URI] = request
+		} else {
+			delete(s.subscriptions, params.URI)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// register adds a pending request, whose response is delivered on its channel. It fails when
+// a request with the same id is already pending.
+func (s *SSE) register(id int64) (*pendingRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[id]; ok {
+		return nil, fmt.Errorf("%w: %d", errDuplicateID, id)
+	}
+	request := &pendingRequest{response: make(chan *transport.JSONRPCResponse, 1)}
+	s.pending[id] = request
+	return request, nil
+}
+
+// unregister removes a pending request, unless its id was already answered and reused.
+func (s *SSE) unregister(id int64, request *pendingRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending[id] == request {
+		delete(s.pending, id)
+	}
+}
+
+// failPending fails every pending request with err. The caller must hold s.mu.
+func (s *SSE) failPending(err error) {
+	for id, request := range s.pending {
+		request.err = err
+		close(request.response)
+		delete(s.pending, id)
+	}
+}
+
+// waitReady blocks until the session is usable, the transport gives up or ctx is done.
+func (s *SSE) waitReady(ctx context.Context) error {
+	s.mu.Lock()
+	ready := s.ready
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-s.failed:
+		return s.sessionErr()
+	case <-s.ctx.Done():
+		return fmt.Errorf("transport has been closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fail records that reconnecting gave up and fails every pending request.
+func (s *SSE) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+	close(s.failed)
+	s.failPending(err)
+}
+
+// sessionErr returns the error to report for requests that will never get a response.
+func (s *SSE) sessionErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return fmt.Errorf("transport has been closed")
+}
+
+// statusError is returned when the server rejects a message with an HTTP error status.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.code, e.body)
+}
+
+// StatusCode returns the HTTP status with which the server rejected a request, if err was
+// caused by one.
+func StatusCode(err error) (int, bool) {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+	return statusErr.code, true
+}
+
+// post sends a JSON-RPC message to the current message endpoint.
+func (s *SSE) post(ctx context.Context, body []byte) error {
+	s.mu.Lock()
+	endpoint := s.endpoint
+	s.mu.Unlock()
+	if endpoint == nil {
+		return fmt.Errorf("endpoint not received")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setHeaders(req, s.headers)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return &statusError{code: resp.StatusCode, body: string(respBody)}
+	}
+
+	return nil
+}
+
+// parseEndpoint resolves the endpoint announced by the server against the base URL.
+func (s *SSE) parseEndpoint(data string) (*url.URL, error) {
+	endpoint, err := s.baseURL.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing endpoint URL: %w", err)
+	}
+	if endpoint.Host != s.baseURL.Host {
+		return nil, fmt.Errorf("endpoint origin does not match connection origin")
+	}
+	return endpoint, nil
+}
+
+// handleEvent routes a message event to the pending request, the request handler or the
+// notification handler.
+func (s *SSE) handleEvent(event, data string) {
+	if event != "message" {
+		return
+	}
+
+	if request, ok := parseServerRequest([]byte(data)); ok {
+		// Answering can take a while, such as for sampling, and must not hold up the stream
+		go s.handleRequest(request)
+		return
+	}
+
+	var message transport.JSONRPCResponse
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		fmt.Fprintf(os.Stderr, "Error unmarshaling message: %v\n", err)
+		return
+	}
+
+	if message.ID == nil {
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal([]byte(data), &notification); err != nil {
+			return
+		}
+		s.notifyMu.RLock()
+		if s.onNotification != nil {
+			s.onNotification(notification)
+		}
+		s.notifyMu.RUnlock()
+		return
+	}
+
+	s.mu.Lock()
+	request, ok := s.pending[*message.ID]
+	if ok {
+		// Remove it right away so a duplicate response is dropped
+		delete(s.pending, *message.ID)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		request.response <- &message
+	}
+}
+
+// handleRequest answers a request of the server with the request handler, posting the
+// response to the message endpoint.
+func (s *SSE) handleRequest(request serverRequest) {
+	s.notifyMu.RLock()
+	handler := s.onRequest
+	s.notifyMu.RUnlock()
+
+	body, err := request.answer(s.ctx, handler)
+	if err != nil {
+		return
+	}
+	if err := s.post(s.ctx, body); err != nil && s.ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "Error answering %s request: %v\n", request.Method, err)
+	}
+}
+
+// close stops the stream request and releases its connection.
+func (st *sseStream) close() {
+	st.cancel()
+	_ = st.body.Close()
+}
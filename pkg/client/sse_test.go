@@ -0,0 +1,320 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// testSSEServer is a minimal MCP SSE server whose sessions can be dropped on demand.
+type testSSEServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int
+	sessions map[int]chan string
+	methods  map[string]int
+	// dropOn drops every session instead of answering requests with this method, once.
+	dropOn string
+	// rejectInitialize answers initialize requests with a JSON-RPC error.
+	rejectInitialize bool
+}
+
+func newTestSSEServer(t *testing.T) *testSSEServer {
+	t.Helper()
+
+	s := &testSSEServer{
+		sessions: make(map[int]chan string),
+		methods:  make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", s.handleStream)
+	mux.HandleFunc("/message", s.handleMessage)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *testSSEServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	events := make(chan string, 16)
+	s.sessions[id] = events
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprintf(w, "event: endpoint\ndata: /message?session=%d\n\n", id)
+	flusher.Flush()
+
+	for {
+		select {
+		case data, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *testSSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     *int64 `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var id int
+	_, _ = fmt.Sscanf(r.URL.Query().Get("session"), "%d", &id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, ok := s.sessions[id]
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	s.methods[request.Method]++
+	w.WriteHeader(http.StatusAccepted)
+
+	if request.Method == s.dropOn {
+		s.dropOn = ""
+		s.dropLocked()
+		return
+	}
+	if request.ID != nil && request.Method == "initialize" && s.rejectInitialize {
+		events <- fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32600,"message":"initialize rejected"}}`, *request.ID)
+		return
+	}
+	if request.ID != nil {
+		events <- fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"session":%d}}`, *request.ID, id)
+	}
+}
+
+// drop closes every open event stream, as a server restart would.
+func (s *testSSEServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropLocked()
+}
+
+func (s *testSSEServer) dropLocked() {
+	for id, events := range s.sessions {
+		close(events)
+		delete(s.sessions, id)
+	}
+}
+
+func (s *testSSEServer) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.methods[method]
+}
+
+func startTestSSE(t *testing.T, url string, maxRetries int) *SSE {
+	t.Helper()
+
+	sse, err := NewSSE(url, SSEOptions{MaxRetries: maxRetries})
+	if err != nil {
+		t.Fatalf("NewSSE() error = %v", err)
+	}
+	sse.initialBackoff = 10 * time.Millisecond
+
+	if err := sse.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sse.Close() })
+
+	return sse
+}
+
+// waitDropped waits until the transport noticed the stream dropped and started reconnecting,
+// so that the next request waits for the restored session.
+func waitDropped(t *testing.T, sse *SSE, generation int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sse.mu.Lock()
+		dropped := sse.generation >= generation
+		sse.mu.Unlock()
+		if dropped {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the stream to drop")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func sendTestRequest(sse *SSE, id int64, method string, params any) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := sse.SendRequest(ctx, transport.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Session int `json:"session"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return 0, err
+	}
+	return result.Session, nil
+}
+
+func TestSSEReconnect(t *testing.T) {
+	server := newTestSSEServer(t)
+	sse := startTestSSE(t, server.URL+"/sse", 3)
+
+	if _, err := sendTestRequest(sse, 1, "initialize", nil); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	if _, err := sendTestRequest(sse, 2, "resources/subscribe", map[string]any{"uri": "file:///a"}); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+
+	server.drop()
+	waitDropped(t, sse, 1)
+
+	session, err := sendTestRequest(sse, 3, "tools/list", nil)
+	if err != nil {
+		t.Fatalf("tools/list after drop error = %v", err)
+	}
+	if session != 2 {
+		t.Errorf("expected request to be served by the new session, got session %d", session)
+	}
+
+	testCases := []struct {
+		method   string
+		expected int
+	}{
+		{method: "initialize", expected: 2},
+		{method: "notifications/initialized", expected: 1},
+		{method: "resources/subscribe", expected: 2},
+		{method: "tools/list", expected: 1},
+	}
+	for _, tc := range testCases {
+		if got := server.count(tc.method); got != tc.expected {
+			t.Errorf("%s sent %d times, want %d", tc.method, got, tc.expected)
+		}
+	}
+}
+
+func TestSSEFailsPendingRequest(t *testing.T) {
+	server := newTestSSEServer(t)
+	sse := startTestSSE(t, server.URL+"/sse", 3)
+
+	if _, err := sendTestRequest(sse, 1, "initialize", nil); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+
+	// The server drops the stream instead of answering the call
+	server.mu.Lock()
+	server.dropOn = "tools/call"
+	server.mu.Unlock()
+
+	_, err := sendTestRequest(sse, 2, "tools/call", nil)
+	if !errors.Is(err, errConnectionDropped) {
+		t.Fatalf("expected the pending request to fail with %v, got %v", errConnectionDropped, err)
+	}
+
+	// The call isn't resent, but later requests are served by the restored session
+	session, err := sendTestRequest(sse, 3, "tools/list", nil)
+	if err != nil {
+		t.Fatalf("tools/list after drop error = %v", err)
+	}
+	if session != 2 {
+		t.Errorf("expected request to be served by the new session, got session %d", session)
+	}
+	if got := server.count("tools/call"); got != 1 {
+		t.Errorf("tools/call sent %d times, want 1", got)
+	}
+}
+
+func TestSSEGivesUpAfterMaxRetries(t *testing.T) {
+	server := newTestSSEServer(t)
+	sse := startTestSSE(t, server.URL+"/sse", 2)
+
+	server.drop()
+	server.Close()
+
+	select {
+	case <-sse.failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnecting to give up")
+	}
+
+	_, err := sendTestRequest(sse, 1, "tools/list", nil)
+	if err == nil {
+		t.Fatal("expected an error once reconnecting gave up")
+	}
+	if !strings.Contains(err.Error(), "after 2 reconnect attempts") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSSEGivesUpWhenRestoreFails(t *testing.T) {
+	server := newTestSSEServer(t)
+	sse := startTestSSE(t, server.URL+"/sse", 2)
+
+	if _, err := sendTestRequest(sse, 1, "initialize", nil); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+
+	// The restarted server accepts the stream but rejects the replayed initialize
+	server.mu.Lock()
+	server.rejectInitialize = true
+	server.mu.Unlock()
+	server.drop()
+
+	select {
+	case <-sse.failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnecting to give up")
+	}
+
+	_, err := sendTestRequest(sse, 2, "tools/list", nil)
+	if err == nil {
+		t.Fatal("expected an error once reconnecting gave up")
+	}
+	if !strings.Contains(err.Error(), "after 2 reconnect attempts") || !strings.Contains(err.Error(), "initialize rejected") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := server.count("initialize"); got != 3 {
+		t.Errorf("initialize sent %d times, want 3", got)
+	}
+}
@@ -0,0 +1,463 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// exitGracePeriod is how long a server gets to exit after its stdin is closed.
+	exitGracePeriod = 500 * time.Millisecond
+	// terminateGracePeriod is how long a server gets to exit after being asked to terminate.
+	terminateGracePeriod = 500 * time.Millisecond
+)
+
+// errServerExited is returned for requests that were pending when the server process exited.
+var errServerExited = errors.New("server process exited")
+
+// errDuplicateID is returned for requests sent with the id of a request still waiting for its
+// response, which would make the responses impossible to tell apart.
+var errDuplicateID = errors.New("a request with this id is already pending")
+
+// StdioOptions configures a stdio transport.
+type StdioOptions struct {
+	// Env is appended to the current environment of the server process.
+	Env []string
+}
+
+// Stdio implements the MCP stdio transport for a server subprocess. The subprocess runs in
+// its own process group, so that closing the transport also stops any processes it spawned,
+// such as the node process behind an npx command.
+type Stdio struct {
+	command string
+	args    []string
+	env     []string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr io.ReadCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[int64]chan *transport.JSONRPCResponse
+	done    chan struct{}
+
+	// batchMu allows one batch at a time, so that an error the server sends for a whole
+	// batch can be delivered to it on batch
+	batchMu sync.Mutex
+	batch   chan *transport.JSONRPCResponse
+
+	closeOnce sync.Once
+
+	notifyMu       sync.RWMutex
+	onNotification func(mcp.JSONRPCNotification)
+	onRequest      RequestHandler
+}
+
+// NewStdio creates a stdio transport that runs command with args when started.
+func NewStdio(command string, args []string, options StdioOptions) *Stdio {
+	return &Stdio{
+		command: command,
+		args:    args,
+		env:     options.Env,
+		pending: make(map[int64]chan *transport.JSONRPCResponse),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start launches the server process and starts reading its output.
+func (s *Stdio) Start(_ context.Context) error {
+	if s.cmd != nil {
+		return fmt.Errorf("has already started")
+	}
+
+	// #nosec G204 - the command is provided by the user on the command line or in an alias
+	cmd := exec.Command(s.command, s.args...)
+	cmd.Env = append(os.Environ(), s.env...)
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.stdout = bufio.NewReader(stdout)
+	s.stderr = stderr
+
+	go s.readMessages()
+	return nil
+}
+
+// readMessages routes each line the server writes to stdout to the pending request or the
+// notification handler. When the server exits, pending requests fail instead of hanging.
+func (s *Stdio) readMessages() {
+	defer s.failPending()
+
+	for {
+		line, err := s.stdout.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		data := bytes.TrimSpace([]byte(line))
+		if len(data) == 0 || data[0] != '[' {
+			s.handleMessage(data)
+			continue
+		}
+
+		// A batch response holds the responses to each request of a batch
+		var messages []json.RawMessage
+		if err := json.Unmarshal(data, &messages); err != nil {
+			continue
+		}
+		for _, message := range messages {
+			s.handleMessage(message)
+		}
+	}
+}
+
+// handleMessage routes a single message to the pending request, the request handler or the
+// notification handler.
+func (s *Stdio) handleMessage(data []byte) {
+	if request, ok := parseServerRequest(data); ok {
+		// Answering can take a while, such as for sampling, and must not hold up responses
+		go s.handleRequest(request)
+		return
+	}
+
+	var message transport.JSONRPCResponse
+	if err := json.Unmarshal(data, &message); err != nil {
+		return
+	}
+
+	if message.ID == nil {
+		if message.Error != nil {
+			// An error without an id is the server rejecting a batch it couldn't parse
+			s.mu.Lock()
+			if s.batch != nil {
+				select {
+				case s.batch <- &message:
+				default:
+				}
+			}
+			s.mu.Unlock()
+			return
+		}
+
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(data, &notification); err != nil {
+			return
+		}
+		s.notifyMu.RLock()
+		if s.onNotification != nil {
+			s.onNotification(notification)
+		}
+		s.notifyMu.RUnlock()
+		return
+	}
+
+	s.mu.Lock()
+	response, ok := s.pending[*message.ID]
+	delete(s.pending, *message.ID)
+	s.mu.Unlock()
+
+	if ok {
+		response <- &message
+	}
+}
+
+// handleRequest answers a request of the server with the request handler. The context of the
+// handler is canceled when the transport is closed.
+func (s *Stdio) handleRequest(request serverRequest) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.notifyMu.RLock()
+	handler := s.onRequest
+	s.notifyMu.RUnlock()
+
+	body, err := request.answer(ctx, handler)
+	if err != nil {
+		return
+	}
+	_ = s.write(body)
+}
+
+// failPending fails every request still waiting for a response.
+func (s *Stdio) failPending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The requests of a batch share a channel, which must only be closed once
+	closed := make(map[chan *transport.JSONRPCResponse]bool)
+	for id, response := range s.pending {
+		if !closed[response] {
+			close(response)
+			closed[response] = true
+		}
+		delete(s.pending, id)
+	}
+}
+
+// register adds requests that wait for their responses on response. Responses are routed by
+// id, so requests may be sent concurrently and answered in any order, but an id can only be
+// pending once: none of the ids are added if one already is.
+func (s *Stdio) register(ids []int64, response chan *transport.JSONRPCResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := s.pending[id]; ok || seen[id] {
+			return fmt.Errorf("%w: %d", errDuplicateID, id)
+		}
+		seen[id] = true
+	}
+	for _, id := range ids {
+		s.pending[id] = response
+	}
+	return nil
+}
+
+// unregister removes requests that are still waiting on response. Ids that were already
+// answered, and since reused by another request, are left alone.
+func (s *Stdio) unregister(ids []int64, response chan *transport.JSONRPCResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if s.pending[id] == response {
+			delete(s.pending, id)
+		}
+	}
+}
+
+// SendRequest writes a JSON-RPC request to the server and waits for its response.
+func (s *Stdio) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if s.stdin == nil {
+		return nil, fmt.Errorf("stdio client not started")
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	response := make(chan *transport.JSONRPCResponse, 1)
+	ids := []int64{request.ID}
+	if err := s.register(ids, response); err != nil {
+		return nil, err
+	}
+	defer s.unregister(ids, response)
+
+	if err := s.write(body); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	select {
+	case resp, ok := <-response:
+		if !ok {
+			return nil, errServerExited
+		}
+		return resp, nil
+	case <-s.done:
+		return nil, fmt.Errorf("transport has been closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendBatch writes requests to the server as one JSON-RPC batch and waits for all of their
+// responses, which it returns in the order of the requests.
+func (s *Stdio) SendBatch(ctx context.Context, requests []transport.JSONRPCRequest) ([]*transport.JSONRPCResponse, error) {
+	if s.stdin == nil {
+		return nil, fmt.Errorf("stdio client not started")
+	}
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	s.batchMu.Lock()
+	defer s.batchMu.Unlock()
+
+	// One more slot than there are requests leaves room for an error about the whole batch
+	batch := make(chan *transport.JSONRPCResponse, len(requests)+1)
+	ids := make([]int64, len(requests))
+	for i, request := range requests {
+		ids[i] = request.ID
+	}
+	if err := s.register(ids, batch); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.batch = batch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.batch = nil
+		s.mu.Unlock()
+		s.unregister(ids, batch)
+	}()
+
+	if err := s.write(body); err != nil {
+		return nil, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	responses := make(map[int64]*transport.JSONRPCResponse, len(requests))
+	for len(responses) < len(requests) {
+		select {
+		case resp, ok := <-batch:
+			if !ok {
+				return nil, errServerExited
+			}
+			if resp.ID == nil {
+				return nil, batchError(resp)
+			}
+			responses[*resp.ID] = resp
+		case <-s.done:
+			return nil, fmt.Errorf("transport has been closed")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return orderResponses(requests, responses)
+}
+
+// SendNotification writes a JSON-RPC notification to the server.
+func (s *Stdio) SendNotification(_ context.Context, notification mcp.JSONRPCNotification) error {
+	if s.stdin == nil {
+		return fmt.Errorf("stdio client not started")
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	if err := s.write(body); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
+
+// write sends a single newline-delimited message to the server.
+func (s *Stdio) write(body []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := s.stdin.Write(append(body, '\n'))
+	return err
+}
+
+// SetNotificationHandler sets the handler for server notifications.
+func (s *Stdio) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	s.onNotification = handler
+}
+
+// SetRequestHandler sets the handler for requests the server sends to the client, such as
+// sampling/createMessage.
+func (s *Stdio) SetRequestHandler(handler RequestHandler) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	s.onRequest = handler
+}
+
+// Close stops the server. It closes stdin so the server can exit on its own, then asks the
+// process group to terminate and finally kills it, waiting a short grace period between each
+// step. Processes left behind in the group by a server that did exit are terminated as well.
+func (s *Stdio) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.cmd == nil {
+			return
+		}
+
+		_ = s.stdin.Close()
+
+		exited := make(chan struct{})
+		go func() {
+			_ = s.cmd.Wait()
+			close(exited)
+		}()
+
+		select {
+		case <-exited:
+			_ = signalProcessGroup(s.cmd, false)
+			return
+		case <-time.After(exitGracePeriod):
+		}
+
+		_ = signalProcessGroup(s.cmd, false)
+		select {
+		case <-exited:
+			return
+		case <-time.After(terminateGracePeriod):
+		}
+
+		_ = signalProcessGroup(s.cmd, true)
+		<-exited
+	})
+
+	return nil
+}
+
+// Stderr returns the stderr output of the server process.
+func (s *Stdio) Stderr() io.Reader {
+	return s.stderr
+}
+
+// GetStderr returns the stderr output of the server behind c, if it uses a stdio transport.
+func GetStderr(c *mcpclient.Client) (io.Reader, bool) {
+	t := c.GetTransport()
+	for {
+		wrapper, ok := t.(interface{ Unwrap() transport.Interface })
+		if !ok {
+			break
+		}
+		t = wrapper.Unwrap()
+	}
+
+	stdio, ok := t.(*Stdio)
+	if !ok || stdio.stderr == nil {
+		return nil, false
+	}
+	return stdio.Stderr(), true
+}
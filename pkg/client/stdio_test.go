@@ -0,0 +1,259 @@
+//go:build !windows
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStdioSendRequest(t *testing.T) {
+	// cat echoes each request back, which is enough to route it as a response by id
+	stdio := NewStdio("cat", nil, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 7, Method: "ping"})
+	if err != nil {
+		t.Fatalf("SendRequest() error = %v", err)
+	}
+	if resp.ID == nil || *resp.ID != 7 {
+		t.Errorf("response id = %v, want 7", resp.ID)
+	}
+}
+
+func TestStdioOutOfOrderResponses(t *testing.T) {
+	// The server only answers once it has read both requests, and answers the second one first
+	stdio := NewStdio("sh", []string{"-c", `read first; read second; printf '%s\n%s\n' "$second" "$first"; cat`}, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	for _, id := range []int64{1, 2} {
+		go func() {
+			resp, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: "ping"})
+			if err == nil && (resp.ID == nil || *resp.ID != id) {
+				err = fmt.Errorf("request %d got the response with id %v", id, resp.ID)
+			}
+			errs <- err
+		}()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("SendRequest() error = %v", err)
+		}
+	}
+}
+
+func TestStdioDuplicateID(t *testing.T) {
+	// The server never answers, so the first request stays pending
+	stdio := NewStdio("sh", []string{"-c", "cat > /dev/null"}, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pending := make(chan error, 1)
+	go func() {
+		_, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "ping"})
+		pending <- err
+	}()
+	waitPending(t, stdio, 1)
+
+	_, err := stdio.SendRequest(context.Background(), transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "ping"})
+	if !errors.Is(err, errDuplicateID) {
+		t.Errorf("SendRequest() with a pending id error = %v, want %v", err, errDuplicateID)
+	}
+	_, err = stdio.SendBatch(context.Background(), []transport.JSONRPCRequest{
+		{JSONRPC: "2.0", ID: 2, Method: "ping"},
+		{JSONRPC: "2.0", ID: 2, Method: "ping"},
+	})
+	if !errors.Is(err, errDuplicateID) {
+		t.Errorf("SendBatch() with a repeated id error = %v, want %v", err, errDuplicateID)
+	}
+
+	// The rejected requests must not have dropped the one that is still pending
+	waitPending(t, stdio, 1)
+	cancel()
+	if err := <-pending; !errors.Is(err, context.Canceled) {
+		t.Errorf("pending SendRequest() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// waitPending waits until a request with the id is pending on the transport.
+func waitPending(t *testing.T, stdio *Stdio, id int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		stdio.mu.Lock()
+		_, ok := stdio.pending[id]
+		stdio.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("request %d never became pending", id)
+}
+
+func TestStdioSamplingHandler(t *testing.T) {
+	// The server asks for a completion and for an unknown method, and saves both answers
+	replies := filepath.Join(t.TempDir(), "replies")
+	script := `printf '%s\n' '{"jsonrpc":"2.0","id":"s1","method":"sampling/createMessage","params":{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}],"maxTokens":10}}'
+read first
+printf '%s\n' '{"jsonrpc":"2.0","id":2,"method":"roots/list"}'
+read second
+printf '%s\n%s\n' "$first" "$second" > "$REPLIES"
+cat`
+	stdio := NewStdio("sh", []string{"-c", script}, StdioOptions{Env: []string{"REPLIES=" + replies}})
+	c := mcpclient.NewClient(stdio)
+	ok := SetSamplingHandler(c, func(_ context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		prompt := request.Params.Messages[0].Content.(map[string]any)["text"]
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(fmt.Sprintf("you said %v", prompt))},
+			Model:           "test-model",
+		}, nil
+	})
+	if !ok {
+		t.Fatal("SetSamplingHandler() = false, want true for a stdio transport")
+	}
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	var data []byte
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && !strings.Contains(string(data), "roots/list") {
+		data, _ = os.ReadFile(replies)
+		time.Sleep(10 * time.Millisecond)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("server got %q, want two responses", data)
+	}
+	for _, want := range []string{`"id":"s1"`, `"model":"test-model"`, `"text":"you said hi"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("sampling response %s doesn't contain %s", lines[0], want)
+		}
+	}
+	for _, want := range []string{`"id":2`, `"code":-32601`, "method not found: roots/list"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("response to an unknown method %s doesn't contain %s", lines[1], want)
+		}
+	}
+}
+
+func TestStdioServerExit(t *testing.T) {
+	stdio := NewStdio("sh", []string{"-c", "read line; exit 0"}, StdioOptions{})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "ping"})
+	if !errors.Is(err, errServerExited) {
+		t.Errorf("SendRequest() error = %v, want %v", err, errServerExited)
+	}
+}
+
+func TestStdioCloseStopsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+
+	// The server ignores EOF and SIGTERM and leaves a child process behind
+	script := `trap "" TERM; sleep 1000 & echo $! > "$PID_FILE"; while true; do sleep 1; done`
+	stdio := NewStdio("sh", []string{"-c", script}, StdioOptions{Env: []string{"PID_FILE=" + pidFile}})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var childPid int
+	deadline := time.Now().Add(5 * time.Second)
+	for childPid == 0 && time.Now().Before(deadline) {
+		data, _ := os.ReadFile(pidFile)
+		childPid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		time.Sleep(10 * time.Millisecond)
+	}
+	if childPid == 0 {
+		t.Fatal("server did not start its child process")
+	}
+
+	start := time.Now()
+	if err := stdio.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close() took %v", elapsed)
+	}
+
+	// The child is reaped by init once killed, so poll until it disappears
+	deadline = time.Now().Add(5 * time.Second)
+	for syscall.Kill(childPid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d is still running after Close()", childPid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStdioSameCommandTwice(t *testing.T) {
+	// Transports for the same command run separate processes that can be used concurrently
+	// and closed independently
+	first := NewStdio("cat", nil, StdioOptions{})
+	second := NewStdio("cat", nil, StdioOptions{})
+	for _, stdio := range []*Stdio{first, second} {
+		if err := stdio.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	}
+	t.Cleanup(func() { _ = second.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	for i, stdio := range []*Stdio{first, second} {
+		go func() {
+			_, err := stdio.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: int64(i + 1), Method: "ping"})
+			errs <- err
+		}()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("SendRequest() error = %v", err)
+		}
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := second.SendRequest(ctx, transport.JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "ping"}); err != nil {
+		t.Errorf("SendRequest() after closing the other transport error = %v", err)
+	}
+}
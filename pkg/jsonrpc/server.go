@@ -0,0 +1,295 @@
+// Package jsonrpc provides the stdio JSON-RPC server shared by the proxy, mock and guard
+// servers: logging, reading requests, dispatching them to handlers and writing responses.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/f/mcptools/pkg/logfile"
+)
+
+// Error codes of JSON-RPC error responses.
+const (
+	CodeParseError     = -32700
+	CodeMethodNotFound = -32601
+	CodeServerError    = -32000
+)
+
+// ErrMethodNotFound is returned by handlers, and used for methods without one, to answer with
+// a method not found error.
+var ErrMethodNotFound = errors.New("method not found")
+
+// StopError is returned by handlers after which the server can't go on, such as when the
+// process it forwards requests to exited. The request is answered with Err, then Serve
+// returns it.
+type StopError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *StopError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *StopError) Unwrap() error { return e.Err }
+
+// Request is a JSON-RPC request, or a notification when it has no ID.
+type Request struct {
+	Method  string          `json:"method"`
+	Params  map[string]any  `json:"params,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// IsNotification reports whether the request is a notification, which gets no response.
+func (r Request) IsNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
+// Response is a complete JSON-RPC response. A handler that returns one has it sent as is
+// instead of as the result of a response.
+type Response map[string]any
+
+// Handler answers a request with its result, or with an error that is sent as an error
+// response. Results of notifications are discarded.
+type Handler func(request Request) (any, error)
+
+// Server reads JSON-RPC requests and batches of requests from In and writes the responses
+// to Out, logging both to its log file.
+type Server struct {
+	// Handlers answer requests by method.
+	Handlers map[string]Handler
+	// Fallback answers the methods without a handler. Without one they get a method not found
+	// error, or are ignored for notifications.
+	Fallback Handler
+	In       io.Reader
+	Out      io.Writer
+	// Quiet limits stderr to errors and the line saying the server started.
+	Quiet bool
+	// Debug also writes a line to stderr for every request and response.
+	Debug bool
+
+	logFile *logfile.File
+	writeMu sync.Mutex
+	stopErr error
+}
+
+// NewServer creates a server reading stdin and writing stdout, and logging to
+// ~/.mcpt/logs/<name>.log.
+func NewServer(name string, logOptions logfile.Options) (*Server, error) {
+	logFile, err := logfile.Open(name, logOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		Handlers: make(map[string]Handler),
+		In:       os.Stdin,
+		Out:      os.Stdout,
+		logFile:  logFile,
+	}, nil
+}
+
+// LogPath returns the path of the log file.
+func (s *Server) LogPath() string {
+	return s.logFile.Name()
+}
+
+// Log writes a message to the log file with a timestamp.
+func (s *Server) Log(message string) {
+	timestamp := time.Now().Format(time.RFC3339)
+	fmt.Fprintf(s.logFile, "[%s] %s\n", timestamp, message)
+}
+
+// LogJSON writes a JSON-formatted message to the log file with a timestamp.
+func (s *Server) LogJSON(label string, v any) {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		s.Log(fmt.Sprintf("Error marshaling %s: %v", label, err))
+		return
+	}
+	s.Log(fmt.Sprintf("%s: %s", label, string(jsonBytes)))
+}
+
+// Infof writes a message to stderr unless the server is quiet.
+func (s *Server) Infof(format string, args ...any) {
+	if !s.Quiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// Debugf writes a message about a single request to stderr when debugging.
+func (s *Server) Debugf(format string, args ...any) {
+	if s.Debug {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// Errorf writes an error to stderr and to the log file.
+func (s *Server) Errorf(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	s.Log(message)
+	fmt.Fprintln(os.Stderr, message)
+}
+
+// Close closes the log file.
+func (s *Server) Close() error {
+	return s.logFile.Close()
+}
+
+// Serve answers requests until In is closed, then closes the log file. description names the
+// server in the line saying that it started, such as "Mock server".
+func (s *Server) Serve(description string) error {
+	decoder := json.NewDecoder(s.In)
+
+	s.Log(description + " started, waiting for requests...")
+	fmt.Fprintf(os.Stderr, "%s started, waiting for requests...\n", description)
+
+	// Check error from Close() when deferring
+	defer func() {
+		if err := s.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", err)
+		}
+	}()
+
+	for {
+		s.Debugf("Waiting for request...\n")
+		var message json.RawMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				s.Log("Client disconnected (EOF)")
+				return nil
+			}
+			s.Errorf("Error decoding request: %v", err)
+			return fmt.Errorf("error decoding request: %w", err)
+		}
+
+		// A batch holds several requests, which are answered together in one array
+		if trimmed := bytes.TrimSpace(message); len(trimmed) > 0 && trimmed[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(trimmed, &batch); err != nil {
+				return fmt.Errorf("error decoding batch: %w", err)
+			}
+			s.Debugf("Received batch of %d requests\n", len(batch))
+
+			responses := []Response{}
+			for _, item := range batch {
+				if response := s.Handle(item); response != nil {
+					responses = append(responses, response)
+				}
+			}
+			if len(responses) > 0 {
+				s.Write(responses)
+			}
+			if s.stopErr != nil {
+				return s.stopErr
+			}
+			continue
+		}
+
+		if response := s.Handle(message); response != nil {
+			s.Write(response)
+		}
+		if s.stopErr != nil {
+			return s.stopErr
+		}
+	}
+}
+
+// Handle dispatches a single JSON-RPC message and returns the response to send, or nil for
+// notifications.
+func (s *Server) Handle(message json.RawMessage) Response {
+	var request Request
+	if err := json.Unmarshal(message, &request); err != nil {
+		s.Errorf("Error decoding request: %v", err)
+		return s.errorResponse(nil, CodeParseError, err)
+	}
+
+	// Log the incoming request
+	s.LogJSON("Received request", request)
+
+	handler, ok := s.Handlers[request.Method]
+	if !ok {
+		handler = s.Fallback
+	}
+
+	if request.IsNotification() {
+		s.Debugf("Received notification: %s\n", request.Method)
+		if handler != nil {
+			if _, err := handler(request); err != nil {
+				s.Errorf("Error handling notification: %v", err)
+				var stopErr *StopError
+				if errors.As(err, &stopErr) {
+					s.stopErr = stopErr.Err
+				}
+			}
+		}
+		return nil
+	}
+
+	s.Debugf("Received request: %s (ID: %s)\n", request.Method, request.ID)
+
+	var result any
+	err := ErrMethodNotFound
+	if handler != nil {
+		result, err = handler(request)
+	}
+	if err != nil {
+		s.Errorf("Error handling request: %v", err)
+		code := CodeServerError
+		if errors.Is(err, ErrMethodNotFound) {
+			code = CodeMethodNotFound
+		}
+		var stopErr *StopError
+		if errors.As(err, &stopErr) {
+			s.stopErr = stopErr.Err
+		}
+		return s.errorResponse(request.ID, code, err)
+	}
+
+	response, ok := result.(Response)
+	if !ok {
+		response = Response{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  result,
+		}
+	}
+
+	// Log the outgoing response
+	s.Debugf("Sending response\n")
+	s.LogJSON("Sending response", response)
+	return response
+}
+
+// errorResponse builds a JSON-RPC error response to the request with the given ID.
+func (s *Server) errorResponse(id json.RawMessage, code int, err error) Response {
+	response := Response{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]any{
+			"code":    code,
+			"message": err.Error(),
+		},
+	}
+
+	// Log the outgoing error response
+	s.LogJSON("Sending error response", response)
+	return response
+}
+
+// Write writes a message, such as a response or an array of responses to a batch, to Out.
+func (s *Server) Write(v any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := json.NewEncoder(s.Out).Encode(v); err != nil {
+		s.Errorf("Error encoding response: %v", err)
+	}
+}
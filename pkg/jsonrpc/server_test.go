@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/f/mcptools/pkg/logfile"
+)
+
+// serve runs a server with the given handlers on the input and returns the decoded messages
+// it wrote.
+func serve(t *testing.T, configure func(*Server), input string) ([]any, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	s, err := NewServer("test", logfile.Options{})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	var out bytes.Buffer
+	s.In = strings.NewReader(input)
+	s.Out = &out
+	configure(s)
+
+	serveErr := s.Serve("Test server")
+
+	var messages []any
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var message any
+		if err := decoder.Decode(&message); err != nil {
+			t.Fatalf("invalid output %q: %v", out.String(), err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, serveErr
+}
+
+func TestServeDispatchesRequests(t *testing.T) {
+	var notified bool
+	messages, err := serve(t, func(s *Server) {
+		s.Handlers["echo"] = func(r Request) (any, error) { return r.Params, nil }
+		s.Handlers["fail"] = func(Request) (any, error) { return nil, errors.New("boom") }
+		s.Handlers["notifications/initialized"] = func(Request) (any, error) {
+			notified = true
+			return nil, nil
+		}
+	}, `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"a":1}}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+{"jsonrpc":"2.0","id":"two","method":"fail"}
+{"jsonrpc":"2.0","id":3,"method":"missing"}
+{"jsonrpc":"2.0","method":"notifications/unknown"}
+`)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if !notified {
+		t.Error("notification handler wasn't called")
+	}
+
+	want := []any{
+		map[string]any{"jsonrpc": "2.0", "id": float64(1), "result": map[string]any{"a": float64(1)}},
+		map[string]any{"jsonrpc": "2.0", "id": "two", "error": map[string]any{"code": float64(CodeServerError), "message": "boom"}},
+		map[string]any{"jsonrpc": "2.0", "id": float64(3), "error": map[string]any{"code": float64(CodeMethodNotFound), "message": "method not found"}},
+	}
+	assertMessages(t, messages, want)
+}
+
+func TestServeBatchAndFallback(t *testing.T) {
+	messages, err := serve(t, func(s *Server) {
+		s.Fallback = func(r Request) (any, error) {
+			return Response{"jsonrpc": "2.0", "id": r.ID, "result": r.Method}, nil
+		}
+	}, `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"b"},{"jsonrpc":"2.0","id":2,"method":"c"}]`)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	want := []any{[]any{
+		map[string]any{"jsonrpc": "2.0", "id": float64(1), "result": "a"},
+		map[string]any{"jsonrpc": "2.0", "id": float64(2), "result": "c"},
+	}}
+	assertMessages(t, messages, want)
+}
+
+func TestServeStopsOnStopError(t *testing.T) {
+	messages, err := serve(t, func(s *Server) {
+		s.Handlers["exit"] = func(Request) (any, error) {
+			return nil, &StopError{Err: errors.New("child exited")}
+		}
+	}, `{"jsonrpc":"2.0","id":1,"method":"exit"}
+{"jsonrpc":"2.0","id":2,"method":"exit"}
+`)
+	if err == nil || err.Error() != "child exited" {
+		t.Errorf("Serve() error = %v, want child exited", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("got %d responses, want only the one to the first request", len(messages))
+	}
+}
+
+func assertMessages(t *testing.T, got, want []any) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("messages = %s\nwant %s", gotJSON, wantJSON)
+	}
+}
@@ -0,0 +1,136 @@
+package jsonutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Kinds of differences reported by Diff.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// Difference is a value that differs between two JSON documents. Path is dot-separated like
+// the paths of SelectFields, with "." for the documents themselves.
+type Difference struct {
+	Path string
+	Kind string
+	Old  any
+	New  any
+}
+
+// Diff deep-compares two decoded JSON documents and returns the paths where they differ,
+// in order. Objects are compared key by key and arrays index by index; any other values,
+// including values of different types, are reported as changed when they aren't equal.
+func Diff(oldData, newData any) []Difference {
+	var diffs []Difference
+	diffValues("", oldData, newData, &diffs)
+	return diffs
+}
+
+// diffValues appends the differences between two values at path to diffs.
+func diffValues(path string, oldValue, newValue any, diffs *[]Difference) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]any:
+		if newTyped, ok := newValue.(map[string]any); ok {
+			keys := make([]string, 0, len(oldTyped)+len(newTyped))
+			for key := range oldTyped {
+				keys = append(keys, key)
+			}
+			for key := range newTyped {
+				if _, ok := oldTyped[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				oldChild, inOld := oldTyped[key]
+				newChild, inNew := newTyped[key]
+				switch {
+				case !inNew:
+					*diffs = append(*diffs, Difference{Path: joinPath(path, key), Kind: DiffRemoved, Old: oldChild})
+				case !inOld:
+					*diffs = append(*diffs, Difference{Path: joinPath(path, key), Kind: DiffAdded, New: newChild})
+				default:
+					diffValues(joinPath(path, key), oldChild, newChild, diffs)
+				}
+			}
+			return
+		}
+	case []any:
+		if newTyped, ok := newValue.([]any); ok {
+			for i := 0; i < len(oldTyped) || i < len(newTyped); i++ {
+				childPath := joinPath(path, strconv.Itoa(i))
+				switch {
+				case i >= len(newTyped):
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: DiffRemoved, Old: oldTyped[i]})
+				case i >= len(oldTyped):
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: DiffAdded, New: newTyped[i]})
+				default:
+					diffValues(childPath, oldTyped[i], newTyped[i], diffs)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		if path == "" {
+			path = "."
+		}
+		*diffs = append(*diffs, Difference{Path: path, Kind: DiffChanged, Old: oldValue, New: newValue})
+	}
+}
+
+// joinPath appends a key or index to a dot-separated path.
+func joinPath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
+}
+
+// FormatDiff writes differences one per line: "+ path: value" for added values,
+// "- path: value" for removed ones and "~ path: old -> new" for changed ones, colored
+// green, red and yellow when colors are enabled.
+func FormatDiff(w io.Writer, diffs []Difference) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "No differences")
+		return err
+	}
+
+	buf := &errWriter{w: w}
+	useColors := colorsEnabled()
+	for _, d := range diffs {
+		var line, color string
+		switch d.Kind {
+		case DiffAdded:
+			line, color = fmt.Sprintf("+ %s: %s", d.Path, diffValueText(d.New)), ColorGreen
+		case DiffRemoved:
+			line, color = fmt.Sprintf("- %s: %s", d.Path, diffValueText(d.Old)), ColorRed
+		default:
+			line, color = fmt.Sprintf("~ %s: %s -> %s", d.Path, diffValueText(d.Old), diffValueText(d.New)), ColorYellow
+		}
+		if useColors {
+			line = color + line + ColorReset
+		}
+		fmt.Fprintln(buf, line)
+	}
+	return buf.err
+}
+
+// diffValueText renders a value of a difference as compact JSON.
+func diffValueText(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,62 @@
+package jsonutils
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldData := map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": "3 files"}},
+		"isError": false,
+		"meta":    map[string]any{"took": 1.5, "host": "a"},
+		"tags":    []any{"x", "y"},
+	}
+	newData := map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": "4 files"}},
+		"isError": false,
+		"meta":    map[string]any{"took": "slow", "region": "eu"},
+		"tags":    []any{"x"},
+	}
+
+	want := []Difference{
+		{Path: "content.0.text", Kind: DiffChanged, Old: "3 files", New: "4 files"},
+		{Path: "meta.host", Kind: DiffRemoved, Old: "a"},
+		{Path: "meta.region", Kind: DiffAdded, New: "eu"},
+		{Path: "meta.took", Kind: DiffChanged, Old: 1.5, New: "slow"},
+		{Path: "tags.1", Kind: DiffRemoved, Old: "y"},
+	}
+	if diffs := Diff(oldData, newData); !reflect.DeepEqual(diffs, want) {
+		t.Errorf("Diff() = %+v, want %+v", diffs, want)
+	}
+
+	if diffs := Diff(oldData, oldData); len(diffs) != 0 {
+		t.Errorf("Diff() of equal documents = %+v", diffs)
+	}
+	if diffs := Diff("a", []any{"a"}); !reflect.DeepEqual(diffs, []Difference{{Path: ".", Kind: DiffChanged, Old: "a", New: []any{"a"}}}) {
+		t.Errorf("Diff() of documents of different types = %+v", diffs)
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	diffs := []Difference{
+		{Path: "a", Kind: DiffAdded, New: map[string]any{"b": 1.0}},
+		{Path: "c.0", Kind: DiffRemoved, Old: "x"},
+		{Path: "d", Kind: DiffChanged, Old: 1.0, New: true},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatDiff(&buf, diffs); err != nil {
+		t.Fatalf("FormatDiff() error = %v", err)
+	}
+	want := "+ a: {\"b\":1}\n- c.0: \"x\"\n~ d: 1 -> true\n"
+	if buf.String() != want {
+		t.Errorf("FormatDiff() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := FormatDiff(&buf, nil); err != nil || buf.String() != "No differences\n" {
+		t.Errorf("FormatDiff(nil) = %q, %v", buf.String(), err)
+	}
+}
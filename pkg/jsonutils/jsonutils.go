@@ -5,11 +5,15 @@ package jsonutils
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"os"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -37,11 +41,73 @@ const (
 	shortTypeArray  = "arr"
 )
 
-// isTerminal determines if stdout is a terminal (for colorized output).
+// PrettyEmbeddedJSON controls whether text content holding a JSON object or array is
+// re-indented in table output.
+var PrettyEmbeddedJSON = true
+
+// ImageDir is the directory that image content is saved to when the terminal can't show it
+// inline. When it is empty, such images are shown as a placeholder with their MIME type.
+var ImageDir string
+
+// ColorMode controls when formatted output is colorized.
+type ColorMode string
+
+// Color modes.
+const (
+	// ColorAuto colorizes output written to a terminal unless the NO_COLOR environment
+	// variable is set.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways colorizes output even when it is piped.
+	ColorAlways ColorMode = "always"
+	// ColorNever never colorizes output.
+	ColorNever ColorMode = "never"
+)
+
+// colorMode is the color mode used by the formatters.
+var colorMode = ColorAuto
+
+// ParseColorMode converts a string to a ColorMode.
+func ParseColorMode(mode string) (ColorMode, error) {
+	switch ColorMode(strings.ToLower(mode)) {
+	case ColorAuto:
+		return ColorAuto, nil
+	case ColorAlways:
+		return ColorAlways, nil
+	case ColorNever:
+		return ColorNever, nil
+	default:
+		return "", fmt.Errorf("invalid color mode %q (valid values are auto, always and never)", mode)
+	}
+}
+
+// SetColorMode sets when the formatters colorize their output.
+func SetColorMode(mode ColorMode) {
+	colorMode = mode
+}
+
+// UseColors reports whether output written to f should be colorized under the current
+// color mode.
+func UseColors(f *os.File) bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(f.Fd()))
+	}
+}
+
+// isTerminal determines if stdout is a terminal.
 func isTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// colorsEnabled determines if output written to stdout should be colorized.
+func colorsEnabled() bool {
+	return UseColors(os.Stdout)
+}
+
 // OutputFormat represents the available output format options.
 type OutputFormat string
 
@@ -66,93 +132,154 @@ func ParseFormat(format string) OutputFormat {
 	}
 }
 
-// Format formats the given data according to the specified output format.
-func Format(data any, format string) (string, error) {
-	outputFormat := ParseFormat(format)
+// DefaultIndent is the number of spaces the pretty format indents JSON with.
+const DefaultIndent = 2
+
+// FormatOptions controls how Format renders data.
+type FormatOptions struct {
+	// Format is the output format, as accepted by ParseFormat.
+	Format string
+	// Indent is the number of spaces JSON output is indented with. Zero keeps the default of
+	// the format: json is compact, while pretty and the JSON that table falls back to for data
+	// it has no view for are indented by DefaultIndent spaces.
+	Indent int
+	// Compact prints JSON output on a single line, even for the pretty format or with Indent.
+	Compact bool
+}
 
-	switch outputFormat {
-	case FormatJSON:
-		return formatJSON(data, false)
-	case FormatPretty:
-		return formatJSON(data, true)
-	case FormatTable:
-		return formatTable(data)
-	default:
-		return formatTable(data)
+// Format formats the given data according to the specified output format. It returns the
+// output FormatTo writes, without the final newline.
+func Format(data any, options FormatOptions) (string, error) {
+	var buf strings.Builder
+	if err := FormatTo(&buf, data, options); err != nil {
+		return "", err
 	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
 
-// formatJSON converts data to JSON with optional pretty printing.
-func formatJSON(data any, pretty bool) (string, error) {
-	var output []byte
-	var err error
-
-	if pretty {
-		output, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		output, err = json.Marshal(data)
+// FormatTo writes data to w in the specified output format, followed by a newline. Table rows
+// are written as they are formatted rather than collected first, so that large outputs can be
+// streamed.
+func FormatTo(w io.Writer, data any, options FormatOptions) error {
+	switch ParseFormat(options.Format) {
+	case FormatJSON, FormatPretty:
+		return formatJSON(w, data, jsonIndent(options))
+	default:
+		return formatTable(w, data, jsonIndent(options))
 	}
+}
 
-	if err != nil {
-		return "", fmt.Errorf("error formatting JSON: %w", err)
+// jsonIndent returns the indentation of JSON output, or "" for compact output.
+func jsonIndent(options FormatOptions) string {
+	switch {
+	case options.Compact:
+		return ""
+	case options.Indent > 0:
+		return strings.Repeat(" ", options.Indent)
+	case ParseFormat(options.Format) == FormatJSON:
+		return ""
+	default:
+		return strings.Repeat(" ", DefaultIndent)
 	}
+}
 
-	return string(output), nil
+// formatJSON writes data to w as JSON followed by a newline, indented by indent unless it is
+// empty.
+func formatJSON(w io.Writer, data any, indent string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("error formatting JSON: %w", err)
+	}
+	return nil
 }
 
-// formatTable formats the data as a tabular view based on its structure.
-// It tries to detect common MCP response structures and format them appropriately.
-func formatTable(data any) (string, error) {
+// formatTable writes the data to w as a tabular view based on its structure, followed by a
+// newline. It tries to detect common MCP response structures and format them appropriately,
+// and falls back to JSON indented by indent for other data.
+func formatTable(w io.Writer, data any, indent string) error {
 	val := reflect.ValueOf(data)
 
 	if !val.IsValid() {
-		return "No data available", nil
+		_, err := fmt.Fprintln(w, "No data available")
+		return err
 	}
 
 	if val.Kind() != reflect.Map {
-		return formatJSON(data, true)
+		return formatJSON(w, data, indent)
 	}
 
 	mapVal, ok := val.Interface().(map[string]any)
 	if !ok {
-		return formatJSON(data, true)
+		return formatJSON(w, data, indent)
 	}
 
+	if err := formatTableView(w, mapVal); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// errWriter remembers the first error of the writes to w and skips the writes after it, so
+// that a view made of many writes can check for errors once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+// Write writes p to w unless an earlier write failed.
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	var n int
+	n, e.err = e.w.Write(p)
+	return n, e.err
+}
+
+// formatTableView writes the view of an MCP response that matches its structure.
+func formatTableView(w io.Writer, mapVal map[string]any) error {
 	if tools, ok1 := mapVal["tools"]; ok1 {
-		return formatToolsList(tools)
+		return formatToolsList(w, tools)
 	}
 
 	if resources, ok2 := mapVal["resources"]; ok2 {
-		return formatResourcesList(resources)
+		return formatResourcesList(w, resources)
+	}
+
+	if templates, ok5 := mapVal["resourceTemplates"]; ok5 {
+		return formatResourceTemplatesList(w, templates)
 	}
 
 	if prompts, ok3 := mapVal["prompts"]; ok3 {
-		return formatPromptsList(prompts)
+		return formatPromptsList(w, prompts)
 	}
 
 	if content, ok4 := mapVal["content"]; ok4 {
-		return formatContent(content)
+		return formatContent(w, content)
 	}
 
-	return formatGenericMap(mapVal)
+	return formatGenericMap(w, mapVal)
 }
 
-// formatToolsList formats a list of tools as a man-like page.
-func formatToolsList(tools any) (string, error) {
+// formatToolsList writes a list of tools as a man-like page.
+func formatToolsList(w io.Writer, tools any) error {
 	toolsSlice, ok := tools.([]any)
 	if !ok {
-		return "", fmt.Errorf("tools is not a slice")
+		return fmt.Errorf("tools is not a slice")
 	}
 
 	if len(toolsSlice) == 0 {
-		return "No tools available", nil
+		_, err := io.WriteString(w, "No tools available")
+		return err
 	}
 
-	var buf bytes.Buffer
+	buf := &errWriter{w: w}
 	termWidth := getTermWidth()
 	descIndent := "     " // 5 spaces for description indentation
 	descWidth := termWidth - len(descIndent)
-	useColors := isTerminal()
+	useColors := colorsEnabled()
 
 	for i, t := range toolsSlice {
 		tool, ok1 := t.(map[string]any)
@@ -193,27 +320,27 @@ func formatToolsList(tools any) (string, error) {
 		}
 
 		// Write the name with parameters
-		fmt.Fprintln(&buf, displayName)
+		fmt.Fprintln(buf, displayName)
 
 		// Write the indented description
 		if desc != "" {
 			lines := wrapText(desc, descWidth)
 			for _, line := range lines {
 				if useColors {
-					fmt.Fprintf(&buf, "%s%s%s%s\n", descIndent, ColorGray, line, ColorReset)
+					fmt.Fprintf(buf, "%s%s%s%s\n", descIndent, ColorGray, line, ColorReset)
 				} else {
-					fmt.Fprintf(&buf, "%s%s\n", descIndent, line)
+					fmt.Fprintf(buf, "%s%s\n", descIndent, line)
 				}
 			}
 		}
 
 		// Add blank line between tools, but not after the last one
 		if i < len(toolsSlice)-1 {
-			fmt.Fprintln(&buf)
+			fmt.Fprintln(buf)
 		}
 	}
 
-	return buf.String(), nil
+	return buf.err
 }
 
 // formatToolNameWithParams formats a tool name with parameters, adding colors if enabled.
@@ -563,8 +690,25 @@ func formatLegacyParameters(params any) string {
 	}
 }
 
-// getTermWidth returns the terminal width or a default value if detection fails.
+// widthOverride replaces the detected terminal width when it is positive.
+var widthOverride int
+
+// SetWidth sets the width that table output is wrapped to, replacing the width of the
+// terminal. A width of zero restores detection.
+func SetWidth(width int) {
+	widthOverride = width
+}
+
+// getTermWidth returns the width set with SetWidth, the COLUMNS environment variable, or the
+// terminal width, falling back to a default value if none of them is available.
 func getTermWidth() int {
+	if widthOverride > 0 {
+		return widthOverride
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
 	width, _, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil || width <= 0 {
 		return 80 // Default width if terminal width cannot be determined
@@ -607,20 +751,20 @@ func wrapText(text string, width int) []string {
 	return lines
 }
 
-// formatResourcesList formats a list of resources as a table.
-func formatResourcesList(resources any) (string, error) {
+// formatResourcesList writes a list of resources as a table.
+func formatResourcesList(out io.Writer, resources any) error {
 	resourcesSlice, ok := resources.([]any)
 	if !ok {
-		return "", fmt.Errorf("resources is not a slice")
+		return fmt.Errorf("resources is not a slice")
 	}
 
 	if len(resourcesSlice) == 0 {
-		return "No resources available", nil
+		_, err := io.WriteString(out, "No resources available")
+		return err
 	}
 
-	var buf bytes.Buffer
-	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
-	useColors := isTerminal()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	useColors := colorsEnabled()
 
 	// NOTE: Ensure that the column headers are the same length,
 	//       including the color escape sequences!
@@ -666,26 +810,85 @@ func formatResourcesList(resources any) (string, error) {
 		}
 	}
 
-	_ = w.Flush()
-	return buf.String(), nil
+	return w.Flush()
+}
+
+// formatResourceTemplatesList writes a list of resource templates as a table.
+func formatResourceTemplatesList(out io.Writer, templates any) error {
+	templatesSlice, ok := templates.([]any)
+	if !ok {
+		return fmt.Errorf("resource templates is not a slice")
+	}
+
+	if len(templatesSlice) == 0 {
+		_, err := io.WriteString(out, "No resource templates available")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	useColors := colorsEnabled()
+
+	if useColors {
+		fmt.Fprintf(w, "%sNAME%s\t%sMIMETYPE%s\t%sURI TEMPLATE%s\t%sDESCRIPTION%s\n",
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset)
+		fmt.Fprintf(w, "%s----%s\t%s--------%s\t%s------------%s\t%s-----------%s\n",
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset,
+			ColorCyan, ColorReset)
+	} else {
+		fmt.Fprintln(w, "NAME\tMIMETYPE\tURI TEMPLATE\tDESCRIPTION")
+		fmt.Fprintln(w, "----\t--------\t------------\t-----------")
+	}
+
+	for _, t := range templatesSlice {
+		template, ok1 := t.(map[string]any)
+		if !ok1 {
+			continue
+		}
+
+		name, _ := template["name"].(string)
+		mimeType, _ := template["mimeType"].(string)
+		uriTemplate, _ := template["uriTemplate"].(string)
+		desc, _ := template["description"].(string)
+		if len(desc) > 50 {
+			desc = desc[:47] + "..."
+		}
+
+		if useColors {
+			fmt.Fprintf(w, "%s%s%s\t%s%s%s\t%s%s%s\t%s\n",
+				ColorGreen, name, ColorReset,
+				ColorGreen, mimeType, ColorReset,
+				ColorYellow, uriTemplate, ColorReset,
+				desc)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, mimeType, uriTemplate, desc)
+		}
+	}
+
+	return w.Flush()
 }
 
-// formatPromptsList formats a list of prompts as a table.
-func formatPromptsList(prompts any) (string, error) {
+// formatPromptsList writes a list of prompts as a man-like page.
+func formatPromptsList(w io.Writer, prompts any) error {
 	promptsSlice, ok := prompts.([]any)
 	if !ok {
-		return "", fmt.Errorf("prompts is not a slice")
+		return fmt.Errorf("prompts is not a slice")
 	}
 
 	if len(promptsSlice) == 0 {
-		return "No prompts available", nil
+		_, err := io.WriteString(w, "No prompts available")
+		return err
 	}
 
-	var buf bytes.Buffer
+	buf := &errWriter{w: w}
 	termWidth := getTermWidth()
 	descIndent := "     " // 5 spaces for description indentation
 	descWidth := termWidth - len(descIndent)
-	useColors := isTerminal()
+	useColors := colorsEnabled()
 
 	for i, p := range promptsSlice {
 		prompt, ok1 := p.(map[string]any)
@@ -698,9 +901,9 @@ func formatPromptsList(prompts any) (string, error) {
 
 		// Write the prompt name
 		if useColors {
-			fmt.Fprintf(&buf, "%s%s%s\n", ColorBold+ColorCyan, name, ColorReset)
+			fmt.Fprintf(buf, "%s%s%s\n", ColorBold+ColorCyan, name, ColorReset)
 		} else {
-			fmt.Fprintln(&buf, name)
+			fmt.Fprintln(buf, name)
 		}
 
 		// Write the indented description
@@ -708,30 +911,72 @@ func formatPromptsList(prompts any) (string, error) {
 			lines := wrapText(desc, descWidth)
 			for _, line := range lines {
 				if useColors {
-					fmt.Fprintf(&buf, "%s%s%s%s\n", descIndent, ColorGray, line, ColorReset)
+					fmt.Fprintf(buf, "%s%s%s%s\n", descIndent, ColorGray, line, ColorReset)
 				} else {
-					fmt.Fprintf(&buf, "%s%s\n", descIndent, line)
+					fmt.Fprintf(buf, "%s%s\n", descIndent, line)
 				}
 			}
 		}
 
+		// Write the declared arguments, one per line
+		for _, arg := range promptArguments(prompt["arguments"]) {
+			if useColors {
+				fmt.Fprintf(buf, "%s- %s%s%s%s%s\n", descIndent, ColorGreen, arg.name, ColorGray, arg.detail, ColorReset)
+			} else {
+				fmt.Fprintf(buf, "%s- %s%s\n", descIndent, arg.name, arg.detail)
+			}
+		}
+
 		// Add blank line between prompts, but not after the last one
 		if i < len(promptsSlice)-1 {
-			fmt.Fprintln(&buf)
+			fmt.Fprintln(buf)
 		}
 	}
 
-	return buf.String(), nil
+	return buf.err
+}
+
+// promptArgument is an argument of a prompt as shown in the prompts list.
+type promptArgument struct {
+	name   string
+	detail string
 }
 
-func formatContent(content any) (string, error) {
+// promptArguments returns the declared arguments of a prompt in order, with their required
+// flag and description as detail.
+func promptArguments(arguments any) []promptArgument {
+	argsSlice, _ := arguments.([]any)
+	result := make([]promptArgument, 0, len(argsSlice))
+	for _, a := range argsSlice {
+		arg, ok := a.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := arg["name"].(string)
+		if name == "" {
+			continue
+		}
+		detail := ""
+		if required, _ := arg["required"].(bool); required {
+			detail = " (required)"
+		}
+		if desc, _ := arg["description"].(string); desc != "" {
+			detail += ": " + desc
+		}
+		result = append(result, promptArgument{name: name, detail: detail})
+	}
+	return result
+}
+
+// formatContent writes the content of a tool result or prompt message.
+func formatContent(w io.Writer, content any) error {
 	contentSlice, ok := content.([]any)
 	if !ok {
-		return "", fmt.Errorf("content is not a slice")
+		return fmt.Errorf("content is not a slice")
 	}
 
-	var buf strings.Builder
-	useColors := isTerminal()
+	buf := &errWriter{w: w}
+	useColors := colorsEnabled()
 
 	for _, c := range contentSlice {
 		contentItem, ok1 := c.(map[string]any)
@@ -744,38 +989,246 @@ func formatContent(content any) (string, error) {
 		switch contentType {
 		case "text":
 			text, _ := contentItem["text"].(string)
-			if useColors {
-				buf.WriteString(ColorGray + text + ColorReset)
+			if embedded, ok2 := indentEmbeddedJSON(text); ok2 {
+				if useColors {
+					embedded = colorizeJSON(embedded)
+				}
+				fmt.Fprint(buf, embedded)
+			} else if useColors {
+				fmt.Fprint(buf, ColorGray+text+ColorReset)
 			} else {
-				buf.WriteString(text)
+				fmt.Fprint(buf, text)
 			}
 		case "image":
-			if useColors {
-				buf.WriteString(ColorYellow + "[IMAGE CONTENT]" + ColorReset + "\n")
-			} else {
-				buf.WriteString("[IMAGE CONTENT]\n")
-			}
+			fmt.Fprint(buf, formatImage(contentItem, useColors))
 		default:
 			if useColors {
-				buf.WriteString(fmt.Sprintf("%s[%s CONTENT]%s\n",
-					ColorYellow, strings.ToUpper(contentType), ColorReset))
+				fmt.Fprintf(buf, "%s[%s CONTENT]%s\n",
+					ColorYellow, strings.ToUpper(contentType), ColorReset)
 			} else {
-				buf.WriteString(fmt.Sprintf("[%s CONTENT]\n", strings.ToUpper(contentType)))
+				fmt.Fprintf(buf, "[%s CONTENT]\n", strings.ToUpper(contentType))
 			}
 		}
 	}
 
-	return buf.String(), nil
+	return buf.err
 }
 
-func formatGenericMap(data map[string]any) (string, error) {
-	if len(data) == 0 {
-		return "No data available", nil
+// Image display methods, from the most to the least capable terminal. Images that an
+// interactive terminal can't show inline are described by their MIME type, and saved to
+// ImageDir when it is set.
+const (
+	imageITerm2      = "iterm2"
+	imageKitty       = "kitty"
+	imageFile        = "file"
+	imagePlaceholder = "placeholder"
+)
+
+// kittyChunkSize is the largest payload kitty accepts in a single graphics escape sequence.
+const kittyChunkSize = 4096
+
+// imageDisplay chooses how to show an image of the given MIME type. Inline images are only
+// used in terminals known to support them, and never inside tmux or screen, which don't pass
+// the escape sequences through. Output that isn't an interactive terminal keeps the placeholder.
+func imageDisplay(getenv func(string) string, terminal bool, mimeType string) string {
+	term := getenv("TERM")
+	if !terminal || term == "" || term == "dumb" {
+		return imagePlaceholder
+	}
+	if getenv("TMUX") == "" && !strings.HasPrefix(term, "screen") {
+		if getenv("TERM_PROGRAM") == "iTerm.app" || getenv("LC_TERMINAL") == "iTerm2" {
+			return imageITerm2
+		}
+		// kitty only decodes PNG itself
+		if (term == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != "") && mimeType == "image/png" {
+			return imageKitty
+		}
+	}
+	return imageFile
+}
+
+// formatImage shows an image content block inline or as a placeholder, depending on what the
+// terminal supports. Images that aren't shown inline are saved to ImageDir when it is set,
+// and the placeholder gives the path of the saved file.
+func formatImage(contentItem map[string]any, useColors bool) string {
+	label := "[IMAGE CONTENT]"
+	if useColors {
+		label = ColorYellow + "[IMAGE CONTENT]" + ColorReset
+	}
+	placeholder := label + "\n"
+
+	mimeType, _ := contentItem["mimeType"].(string)
+	method := imageDisplay(os.Getenv, isTerminal(), mimeType)
+	if method == imagePlaceholder && ImageDir == "" {
+		return placeholder
+	}
+
+	encoded, _ := contentItem["data"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) == 0 {
+		return placeholder
+	}
+
+	switch method {
+	case imageITerm2:
+		return iTerm2Image(data) + "\n"
+	case imageKitty:
+		return kittyImage(data) + "\n"
+	}
+
+	if ImageDir == "" {
+		return fmt.Sprintf("%s (%s)\n", label, mimeType)
+	}
+	path, err := writeImageFile(ImageDir, data, mimeType)
+	if err != nil {
+		return fmt.Sprintf("%s (%s, not saved: %v)\n", label, mimeType, err)
+	}
+	return fmt.Sprintf("%s %s (%s)\n", label, path, mimeType)
+}
+
+// iTerm2Image returns the iTerm2 escape sequence that displays an image inline.
+func iTerm2Image(data []byte) string {
+	return fmt.Sprintf("\033]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a",
+		len(data), base64.StdEncoding.EncodeToString(data))
+}
+
+// kittyImage returns the kitty graphics protocol escape sequences that display a PNG image
+// inline. The payload is sent in chunks, with m=1 marking all but the last one.
+func kittyImage(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var buf strings.Builder
+	for start := 0; start < len(encoded); start += kittyChunkSize {
+		end := min(start+kittyChunkSize, len(encoded))
+		more := 0
+		if end < len(encoded) {
+			more = 1
+		}
+		if start == 0 {
+			fmt.Fprintf(&buf, "\033_Ga=T,f=100,m=%d;%s\033\\", more, encoded[start:end])
+		} else {
+			fmt.Fprintf(&buf, "\033_Gm=%d;%s\033\\", more, encoded[start:end])
+		}
+	}
+	return buf.String()
+}
+
+// writeImageFile writes an image to a new file in dir, with an extension named after its MIME
+// type, and returns the file's path. dir is created if it doesn't exist.
+func writeImageFile(dir string, data []byte, mimeType string) (string, error) {
+	// Prefer the extension named after the subtype, such as .jpeg over .jfif for image/jpeg
+	extension := ""
+	if extensions, err := mime.ExtensionsByType(mimeType); err == nil && len(extensions) > 0 {
+		extension = extensions[0]
+		_, subtype, _ := strings.Cut(mimeType, "/")
+		for _, candidate := range extensions {
+			if candidate == "."+subtype {
+				extension = candidate
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(dir, "mcp-image-*"+extension)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// indentEmbeddedJSON re-indents text that holds a JSON object or array. It reports false for
+// any other text, or when PrettyEmbeddedJSON is disabled.
+func indentEmbeddedJSON(text string) (string, bool) {
+	if !PrettyEmbeddedJSON {
+		return "", false
+	}
+
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
 	}
 
 	var buf bytes.Buffer
-	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
-	useColors := isTerminal()
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// colorizeJSON adds terminal colors to indented JSON: keys in cyan, strings in green,
+// numbers in yellow, and booleans and null in purple.
+func colorizeJSON(text string) string {
+	var buf strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+
+			color := ColorGreen
+			if rest := strings.TrimLeft(text[end:], " "); strings.HasPrefix(rest, ":") {
+				color = ColorCyan
+			}
+			buf.WriteString(color + text[i:end] + ColorReset)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9') || c == 't' || c == 'f' || c == 'n':
+			end := i
+			for end < len(text) && !strings.ContainsRune(",]} \n", rune(text[end])) {
+				end++
+			}
+
+			color := ColorYellow
+			if c == 't' || c == 'f' || c == 'n' {
+				color = ColorPurple
+			}
+			buf.WriteString(color + text[i:end] + ColorReset)
+			i = end
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	return buf.String()
+}
+
+// minValueWidth is the narrowest a truncated value in a generic map is shortened to.
+const minValueWidth = 20
+
+// truncateValue shortens value to width runes, ending it with "..." when it was cut. A width
+// of zero leaves the value unchanged.
+func truncateValue(value string, width int) string {
+	runes := []rune(value)
+	if width <= 0 || len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// formatGenericMap writes the keys and values of data as a table.
+func formatGenericMap(out io.Writer, data map[string]any) error {
+	if len(data) == 0 {
+		_, err := io.WriteString(out, "No data available")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	useColors := colorsEnabled()
 
 	if useColors {
 		fmt.Fprintf(w, "%sKEY%s\t%sVALUE%s\n",
@@ -795,6 +1248,16 @@ func formatGenericMap(data map[string]any) (string, error) {
 	}
 	sort.Strings(keys)
 
+	// Values are only shortened to fit the terminal; piped output keeps them whole
+	maxValueWidth := 0
+	if isTerminal() {
+		keyWidth := len("KEY")
+		for _, k := range keys {
+			keyWidth = max(keyWidth, len(k))
+		}
+		maxValueWidth = max(getTermWidth()-keyWidth-2, minValueWidth)
+	}
+
 	for _, k := range keys {
 		v := data[k]
 		var valueStr string
@@ -809,10 +1272,7 @@ func formatGenericMap(data map[string]any) (string, error) {
 			if err != nil {
 				valueStr = fmt.Sprintf("<%T>", val)
 			} else {
-				valueStr = string(jsonBytes)
-				if len(valueStr) > 50 {
-					valueStr = valueStr[:47] + "..."
-				}
+				valueStr = truncateValue(string(jsonBytes), maxValueWidth)
 			}
 		}
 
@@ -825,8 +1285,36 @@ func formatGenericMap(data map[string]any) (string, error) {
 		}
 	}
 
-	_ = w.Flush()
-	return buf.String(), nil
+	return w.Flush()
+}
+
+// SelectFields projects data onto the given dot-separated paths, such as "content.0.text",
+// where numeric segments index into arrays. The result maps each path to the value found there.
+func SelectFields(data map[string]any, paths []string) (map[string]any, error) {
+	selected := make(map[string]any, len(paths))
+	for _, path := range paths {
+		var current any = data
+		for _, segment := range strings.Split(path, ".") {
+			switch value := current.(type) {
+			case map[string]any:
+				next, ok := value[segment]
+				if !ok {
+					return nil, fmt.Errorf("field %q not found: no key %q", path, segment)
+				}
+				current = next
+			case []any:
+				index, err := strconv.Atoi(segment)
+				if err != nil || index < 0 || index >= len(value) {
+					return nil, fmt.Errorf("field %q not found: no index %q in array of length %d", path, segment, len(value))
+				}
+				current = value[index]
+			default:
+				return nil, fmt.Errorf("field %q not found: can't look up %q in %T", path, segment, current)
+			}
+		}
+		selected[path] = current
+	}
+	return selected, nil
 }
 
 // NormalizeParameterType converts common type names to their canonical form.
@@ -868,3 +1356,64 @@ func NormalizeParameterType(typeName string) string {
 
 	return typeName
 }
+
+// CoerceValue converts a value to the given parameter type, which may be any name accepted
+// by NormalizeParameterType. Strings are parsed for the numeric and boolean types, since they
+// are often written quoted or on a command line, and a single value becomes a one-element
+// array. Values of other types are returned unchanged.
+func CoerceValue(typeName string, value any) (any, error) {
+	switch NormalizeParameterType(typeName) {
+	case "int":
+		switch v := value.(type) {
+		case float64:
+			if v != float64(int64(v)) {
+				return nil, fmt.Errorf("expected an integer, got %v", v)
+			}
+			return int64(v), nil
+		case int, int64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected an integer, got %q", v)
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	case "float":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", v)
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number, got %T", value)
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean, got %q", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a boolean, got %T", value)
+	case typeArray:
+		if _, ok := value.([]any); ok {
+			return value, nil
+		}
+		return []any{value}, nil
+	default:
+		return value, nil
+	}
+}
@@ -1,8 +1,13 @@
 package jsonutils
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -60,6 +65,8 @@ func TestWrapText(t *testing.T) {
 
 // TestGetTermWidth tests the terminal width detection.
 func TestGetTermWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+
 	// Save original stdout and restore it after the test
 	origStdout := os.Stdout
 	defer func() { os.Stdout = origStdout }()
@@ -85,6 +92,128 @@ func TestGetTermWidth(t *testing.T) {
 	}
 }
 
+func TestGetTermWidthOverride(t *testing.T) {
+	defer SetWidth(0)
+
+	t.Setenv("COLUMNS", "132")
+	if width := getTermWidth(); width != 132 {
+		t.Errorf("Expected width 132 from COLUMNS, got %d", width)
+	}
+
+	SetWidth(200)
+	if width := getTermWidth(); width != 200 {
+		t.Errorf("Expected width 200 from SetWidth, got %d", width)
+	}
+
+	// Descriptions wrap at the overridden width
+	description := strings.Repeat("word ", 30)
+	tools := []any{map[string]any{"name": "tool", "description": description}}
+	output, err := Format(map[string]any{"tools": tools}, FormatOptions{})
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(output, strings.TrimSpace(description)) {
+		t.Errorf("Expected the description on a single line at width 200, got:\n%s", output)
+	}
+}
+
+func TestFormatGenericMapPipedKeepsLongValues(t *testing.T) {
+	origStdout := os.Stdout
+	defer func() { os.Stdout = origStdout }()
+
+	r, w, _ := os.Pipe()
+	defer func() { _ = r.Close() }()
+	os.Stdout = w
+
+	long := make([]any, 40)
+	for i := range long {
+		long[i] = float64(i)
+	}
+	output, err := Format(map[string]any{"numbers": long}, FormatOptions{})
+	_ = w.Close()
+	os.Stdout = origStdout
+
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	expected, _ := json.Marshal(long)
+	if !strings.Contains(output, string(expected)) {
+		t.Errorf("Expected the whole value %s in piped output, got:\n%s", expected, output)
+	}
+	if strings.Contains(output, "...") {
+		t.Errorf("Expected no truncation in piped output, got:\n%s", output)
+	}
+}
+
+func TestTruncateValue(t *testing.T) {
+	testCases := []struct {
+		value    string
+		width    int
+		expected string
+	}{
+		{"short", 20, "short"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a value that is too long", 10, "a value..."},
+		{"héllo wörld", 8, "héllo..."},
+		{"unlimited width keeps everything", 0, "unlimited width keeps everything"},
+	}
+
+	for _, tc := range testCases {
+		if got := truncateValue(tc.value, tc.width); got != tc.expected {
+			t.Errorf("truncateValue(%q, %d) = %q, want %q", tc.value, tc.width, got, tc.expected)
+		}
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	defer SetColorMode(ColorAuto)
+
+	for _, invalid := range []string{"", "yes", "sometimes"} {
+		if _, err := ParseColorMode(invalid); err == nil {
+			t.Errorf("ParseColorMode(%q) expected an error", invalid)
+		}
+	}
+
+	r, w, _ := os.Pipe()
+	defer func() {
+		_ = r.Close()
+		_ = w.Close()
+	}()
+
+	testCases := []struct {
+		mode     string
+		noColor  string
+		expected bool
+	}{
+		{"auto", "", false},
+		{"always", "", true},
+		{"always", "1", true},
+		{"never", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Setenv("NO_COLOR", tc.noColor)
+		mode, err := ParseColorMode(tc.mode)
+		if err != nil {
+			t.Fatalf("ParseColorMode(%q) error = %v", tc.mode, err)
+		}
+		SetColorMode(mode)
+		if got := UseColors(w); got != tc.expected {
+			t.Errorf("UseColors() with mode %s and NO_COLOR=%q = %v, want %v", tc.mode, tc.noColor, got, tc.expected)
+		}
+	}
+
+	// Forced colors reach the formatters even though the output is piped
+	SetColorMode(ColorAlways)
+	output, err := Format(map[string]any{"key": "value"}, FormatOptions{})
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(output, ColorGreen+"key"+ColorReset) {
+		t.Errorf("Expected colorized output with --color=always, got %q", output)
+	}
+}
+
 func TestFormat(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -139,7 +268,7 @@ func TestFormat(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			output, err := Format(tc.data, tc.format)
+			output, err := Format(tc.data, FormatOptions{Format: tc.format})
 
 			if tc.expectError {
 				if err == nil {
@@ -169,6 +298,70 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestFormatIndent(t *testing.T) {
+	object := map[string]any{"key": "value"}
+
+	testCases := []struct {
+		name     string
+		data     any
+		options  FormatOptions
+		expected string
+	}{
+		{name: "json is compact", data: object, options: FormatOptions{Format: "json"}, expected: `{"key":"value"}`},
+		{name: "json with indent", data: object, options: FormatOptions{Format: "json", Indent: 4}, expected: "{\n    \"key\": \"value\"\n}"},
+		{name: "pretty is indented", data: object, options: FormatOptions{Format: "pretty"}, expected: "{\n  \"key\": \"value\"\n}"},
+		{name: "pretty compact", data: object, options: FormatOptions{Format: "pretty", Compact: true}, expected: `{"key":"value"}`},
+		{name: "compact wins over indent", data: object, options: FormatOptions{Format: "json", Indent: 4, Compact: true}, expected: `{"key":"value"}`},
+		{name: "table fallback with indent", data: []string{"a"}, options: FormatOptions{Format: "table", Indent: 3}, expected: "[\n   \"a\"\n]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := Format(tc.data, tc.options)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if output != tc.expected {
+				t.Errorf("Format() = %q, want %q", output, tc.expected)
+			}
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestFormatTo(t *testing.T) {
+	data := map[string]any{
+		"tools": []any{
+			map[string]any{"name": "tool1", "description": "First tool"},
+			map[string]any{"name": "tool2", "description": "Second tool"},
+		},
+	}
+
+	for _, format := range []string{"table", "json", "pretty"} {
+		var buf bytes.Buffer
+		if err := FormatTo(&buf, data, FormatOptions{Format: format}); err != nil {
+			t.Fatalf("FormatTo() with format %s error = %v", format, err)
+		}
+		expected, err := Format(data, FormatOptions{Format: format})
+		if err != nil {
+			t.Fatalf("Format() with format %s error = %v", format, err)
+		}
+		if buf.String() != expected+"\n" {
+			t.Errorf("FormatTo() with format %s = %q, want the output of Format and a newline %q", format, buf.String(), expected+"\n")
+		}
+
+		if err := FormatTo(failingWriter{}, data, FormatOptions{Format: format}); err == nil {
+			t.Errorf("FormatTo() with format %s to a failing writer returned no error", format)
+		}
+	}
+}
+
 func TestParseFormat(t *testing.T) {
 	testCases := []struct {
 		expected OutputFormat
@@ -211,7 +404,7 @@ func TestToolsListFormatting(t *testing.T) {
 		"tools": tools,
 	}
 
-	output, err := formatTable(toolsData)
+	output, err := Format(toolsData, FormatOptions{})
 	if err != nil {
 		t.Fatalf("Error formatting tools list: %v", err)
 	}
@@ -267,7 +460,7 @@ func TestToolListManFormat(t *testing.T) {
 	}
 
 	// Format the tools list
-	result, err := formatToolsList(tools)
+	result, err := Format(map[string]any{"tools": tools}, FormatOptions{})
 	if err != nil {
 		t.Fatalf("Failed to format tools list: %v", err)
 	}
@@ -339,7 +532,7 @@ func TestToolListWithArrayParams(t *testing.T) {
 	}
 
 	// Format the tools list
-	result, err := formatToolsList(tools)
+	result, err := Format(map[string]any{"tools": tools}, FormatOptions{})
 	if err != nil {
 		t.Fatalf("Failed to format tools list: %v", err)
 	}
@@ -424,7 +617,7 @@ func TestToolListWithNestedObjectParams(t *testing.T) {
 	}
 
 	// Format the tools list
-	result, err := formatToolsList(tools)
+	result, err := Format(map[string]any{"tools": tools}, FormatOptions{})
 	if err != nil {
 		t.Fatalf("Failed to format tools list: %v", err)
 	}
@@ -504,3 +697,205 @@ func TestNormalizeParameterType(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatContentEmbeddedJSON(t *testing.T) {
+	content := []any{
+		map[string]any{"type": "text", "text": ` {"name":"mcp","tags":["a","b"]} `},
+	}
+
+	output, err := Format(map[string]any{"content": content}, FormatOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "{\n  \"name\": \"mcp\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}"
+	if output != expected {
+		t.Errorf("Format() = %q, want %q", output, expected)
+	}
+
+	PrettyEmbeddedJSON = false
+	defer func() { PrettyEmbeddedJSON = true }()
+
+	output, err = Format(map[string]any{"content": content}, FormatOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != ` {"name":"mcp","tags":["a","b"]} ` {
+		t.Errorf("Format() with PrettyEmbeddedJSON disabled = %q", output)
+	}
+}
+
+func TestColorizeJSON(t *testing.T) {
+	output := colorizeJSON(`{"key": "va\"lue", "n": -1.5, "ok": true, "none": null}`)
+
+	for _, expected := range []string{
+		ColorCyan + `"key"` + ColorReset,
+		ColorGreen + `"va\"lue"` + ColorReset,
+		ColorYellow + "-1.5" + ColorReset,
+		ColorPurple + "true" + ColorReset,
+		ColorPurple + "null" + ColorReset,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("colorizeJSON() = %q, missing %q", output, expected)
+		}
+	}
+}
+
+func TestImageDisplay(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		terminal bool
+		mimeType string
+		expected string
+	}{
+		{"not a terminal", map[string]string{"TERM": "xterm-kitty"}, false, "image/png", imagePlaceholder},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, true, "image/png", imagePlaceholder},
+		{"iTerm2", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, true, "image/jpeg", imageITerm2},
+		{"kitty png", map[string]string{"TERM": "xterm-kitty"}, true, "image/png", imageKitty},
+		{"kitty jpeg", map[string]string{"TERM": "xterm-kitty"}, true, "image/jpeg", imageFile},
+		{"iTerm2 in tmux", map[string]string{"TERM": "screen-256color", "TMUX": "/tmp/tmux", "LC_TERMINAL": "iTerm2"}, true, "image/png", imageFile},
+		{"other terminal", map[string]string{"TERM": "xterm-256color"}, true, "image/png", imageFile},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			if got := imageDisplay(getenv, tc.terminal, tc.mimeType); got != tc.expected {
+				t.Errorf("imageDisplay() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestKittyImage(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff}, kittyChunkSize)
+	output := kittyImage(data)
+
+	if !strings.HasPrefix(output, "\033_Ga=T,f=100,m=1;") {
+		t.Errorf("first chunk has unexpected header: %q", output[:20])
+	}
+	if strings.Count(output, "\033_G") != 2 || !strings.Contains(output, "\033_Gm=0;") {
+		t.Errorf("expected two chunks ending with m=0, got %d", strings.Count(output, "\033_G"))
+	}
+}
+
+func TestWriteImageFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "images")
+	path, err := writeImageFile(dir, []byte("jpeg data"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("writeImageFile() error = %v", err)
+	}
+
+	if filepath.Dir(path) != dir || !strings.HasSuffix(path, ".jpeg") {
+		t.Errorf("path %q does not end with .jpeg", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "jpeg data" {
+		t.Errorf("file content = %q", data)
+	}
+}
+
+func TestFormatImageImageDir(t *testing.T) {
+	original := ImageDir
+	defer func() { ImageDir = original }()
+
+	item := map[string]any{"type": "image", "mimeType": "image/png", "data": "cG5nIGRhdGE="}
+
+	// Without a directory to save to, nothing is written
+	ImageDir = ""
+	if got := formatImage(item, false); got != "[IMAGE CONTENT]\n" {
+		t.Errorf("formatImage() = %q, want the placeholder", got)
+	}
+
+	ImageDir = t.TempDir()
+	got := formatImage(item, false)
+	files, _ := filepath.Glob(filepath.Join(ImageDir, "mcp-image-*.png"))
+	if len(files) != 1 {
+		t.Fatalf("saved files = %v, want one PNG", files)
+	}
+	if want := "[IMAGE CONTENT] " + files[0] + " (image/png)\n"; got != want {
+		t.Errorf("formatImage() = %q, want %q", got, want)
+	}
+	if data, _ := os.ReadFile(files[0]); string(data) != "png data" {
+		t.Errorf("saved image = %q", data)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	data := map[string]any{
+		"content": []any{
+			map[string]any{"type": "text", "text": "hello"},
+		},
+		"isError": false,
+	}
+
+	selected, err := SelectFields(data, []string{"content.0.text", "isError"})
+	if err != nil {
+		t.Fatalf("SelectFields() error = %v", err)
+	}
+	expected := map[string]any{"content.0.text": "hello", "isError": false}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("SelectFields() = %v, want %v", selected, expected)
+	}
+
+	for _, path := range []string{"missing", "content.1.text", "content.x", "isError.value"} {
+		if _, err := SelectFields(data, []string{path}); err == nil {
+			t.Errorf("SelectFields(%q) expected an error", path)
+		}
+	}
+}
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		typeName string
+		value    any
+		want     any
+	}{
+		{"integer", "42", int64(42)},
+		{"integer", float64(3), int64(3)},
+		{"number", " 1.5", 1.5},
+		{"boolean", "false", false},
+		{"array", "go", []any{"go"}},
+		{"array", []any{"a", "b"}, []any{"a", "b"}},
+		{"string", "42", "42"},
+		{"object", "x", "x"},
+	}
+	for _, tt := range tests {
+		got, err := CoerceValue(tt.typeName, tt.value)
+		if err != nil {
+			t.Errorf("CoerceValue(%q, %v) error = %v", tt.typeName, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CoerceValue(%q, %v) = %#v, want %#v", tt.typeName, tt.value, got, tt.want)
+		}
+	}
+
+	for typeName, value := range map[string]any{"integer": "4.5", "number": "abc", "boolean": "yes"} {
+		if _, err := CoerceValue(typeName, value); err == nil {
+			t.Errorf("CoerceValue(%q, %v) returned no error", typeName, value)
+		}
+	}
+}
+
+func TestFormatPromptsListArguments(t *testing.T) {
+	prompts := []any{
+		map[string]any{
+			"name":        "summarize",
+			"description": "Summarize a topic",
+			"arguments": []any{
+				map[string]any{"name": "topic", "description": "What to summarize", "required": true},
+				map[string]any{"name": "style"},
+			},
+		},
+		map[string]any{"name": "greet"},
+	}
+
+	output, err := Format(map[string]any{"prompts": prompts}, FormatOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "summarize\n     Summarize a topic\n     - topic (required): What to summarize\n     - style\n\ngreet\n"
+	if output != expected {
+		t.Errorf("Format() = %q, want %q", output, expected)
+	}
+}
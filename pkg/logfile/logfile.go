@@ -0,0 +1,188 @@
+// Package logfile provides the size-rotated log files of the proxy, mock and guard servers.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the size in bytes at which a log file is rotated.
+	DefaultMaxSize int64 = 10 << 20
+	// DefaultBackups is the number of rotated log files kept.
+	DefaultBackups = 3
+)
+
+// Options configures the rotation of a log file.
+type Options struct {
+	// MaxSize is the size in bytes at which the file is rotated. Zero disables rotation.
+	MaxSize int64
+	// Backups is the number of rotated files kept as name.log.1 (the newest) to name.log.N.
+	// Zero discards the old contents when the file is rotated.
+	Backups int
+}
+
+// DefaultOptions returns the options used when none are given.
+func DefaultOptions() Options {
+	return Options{MaxSize: DefaultMaxSize, Backups: DefaultBackups}
+}
+
+// File is a log file that is appended to and rotated once it reaches its maximum size.
+// It is safe for concurrent use.
+type File struct {
+	mu      sync.Mutex
+	file    *os.File
+	path    string
+	size    int64
+	options Options
+}
+
+// Open opens the log file ~/.mcpt/logs/<name>.log for appending, creating it and its directory
+// when needed.
+func Open(name string, options Options) (*File, error) {
+	homeDir := os.Getenv("HOME")
+	if homeDir == "" {
+		// On Windows, try USERPROFILE if HOME is not set
+		homeDir = os.Getenv("USERPROFILE")
+		if homeDir == "" {
+			return nil, fmt.Errorf("HOME environment variable not set and USERPROFILE not found")
+		}
+	}
+
+	logDir := filepath.Join(homeDir, ".mcpt", "logs")
+	if err := os.MkdirAll(logDir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating log directory: %w", err)
+	}
+
+	// Clean the path to avoid any path traversal
+	logPath := filepath.Clean(filepath.Join(logDir, name+".log"))
+
+	// Verify the path is still under the expected log directory
+	if !strings.HasPrefix(logPath, logDir) {
+		return nil, fmt.Errorf("invalid log path: outside of log directory")
+	}
+
+	f := &File{path: logPath, options: options}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file at f.path for appending and records its current size.
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error opening log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Name returns the path of the log file.
+func (f *File) Name() string {
+	return f.path
+}
+
+// Write appends p to the log file, rotating it first when p would take it past its maximum
+// size. A single write larger than the maximum size is still written whole.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.options.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.options.MaxSize {
+		// Keep logging to the old file if only moving it aside failed
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate closes the log file, moves it aside and opens a new, empty file. The file is reopened
+// even when moving it fails, in which case f.file is set and the error is returned.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error closing log file: %w", err)
+	}
+	f.file = nil
+
+	moveErr := f.moveAside()
+	if err := f.open(); err != nil {
+		return err
+	}
+	if moveErr != nil {
+		return fmt.Errorf("error rotating log file: %w", moveErr)
+	}
+	return nil
+}
+
+// moveAside shifts the backups up by one, keeping at most the configured number, and moves the
+// log file to name.log.1. Without backups, the log file is removed.
+func (f *File) moveAside() error {
+	if f.options.Backups <= 0 {
+		return removeIfExists(f.path)
+	}
+
+	if err := removeIfExists(backupPath(f.path, f.options.Backups)); err != nil {
+		return err
+	}
+	for i := f.options.Backups - 1; i >= 1; i-- {
+		if err := renameIfExists(backupPath(f.path, i), backupPath(f.path, i+1)); err != nil {
+			return err
+		}
+	}
+	return renameIfExists(f.path, backupPath(f.path, 1))
+}
+
+// removeIfExists removes a file, ignoring that it doesn't exist.
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// renameIfExists renames a file, ignoring that it doesn't exist.
+func renameIfExists(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// backupPath returns the path of the nth rotated copy of a log file.
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,111 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	return string(data)
+}
+
+func TestOpenAppends(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, line := range []string{"first\n", "second\n"} {
+		f, err := Open("test", DefaultOptions())
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	path := filepath.Join(home, ".mcpt", "logs", "test.log")
+	if got := readFile(t, path); got != "first\nsecond\n" {
+		t.Errorf("log = %q, want both lines", got)
+	}
+}
+
+func TestWriteRotates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	f, err := Open("test", Options{MaxSize: 10, Backups: 2})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	path := f.Name()
+	want := map[string]string{
+		path:        "dddddd\n",
+		path + ".1": "cccccc\n",
+		path + ".2": "bbbbbb\n",
+	}
+	for file, content := range want {
+		if got := readFile(t, file); got != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more backups than configured were kept: %v", err)
+	}
+}
+
+func TestWriteWithoutBackups(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f, err := Open("test", Options{MaxSize: 10})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+
+	_, _ = f.Write([]byte("aaaaaa\n"))
+	_, _ = f.Write([]byte("bbbbbb\n"))
+
+	if got := readFile(t, f.Name()); got != "bbbbbb\n" {
+		t.Errorf("log = %q, want only the last line", got)
+	}
+	if _, err := os.Stat(f.Name() + ".1"); !os.IsNotExist(err) {
+		t.Errorf("a backup was kept: %v", err)
+	}
+}
+
+func TestWriteWithoutRotation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f, err := Open("test", Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+
+	line := strings.Repeat("x", 100) + "\n"
+	for i := 0; i < 3; i++ {
+		_, _ = f.Write([]byte(line))
+	}
+
+	if got := readFile(t, f.Name()); got != strings.Repeat(line, 3) {
+		t.Errorf("log has %d bytes, want %d", len(got), 3*len(line))
+	}
+}
@@ -2,13 +2,11 @@
 package mock
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
+
+	"github.com/f/mcptools/pkg/jsonrpc"
+	"github.com/f/mcptools/pkg/logfile"
 )
 
 // Tool represents a mock tool in the MCP protocol.
@@ -31,81 +29,58 @@ type Resource struct {
 	Content     string
 }
 
+// Options configures what the mock server writes to stderr. Everything is written to the log
+// file either way.
+type Options struct {
+	// Quiet limits stderr to errors and the line saying the server started.
+	Quiet bool
+	// Debug also writes a line for every request and response.
+	Debug bool
+	// Log configures the rotation of the log file.
+	Log logfile.Options
+}
+
 // Server is a mock MCP server that responds to JSON-RPC requests.
 type Server struct {
 	// Fields ordered for optimal memory alignment (8-byte aligned fields first)
 	tools     map[string]Tool     // pointer (8 bytes)
 	prompts   map[string]Prompt   // pointer (8 bytes)
 	resources map[string]Resource // pointer (8 bytes)
-	logFile   *os.File            // pointer (8 bytes)
-	id        int                 // int (8 bytes)
+	rpc       *jsonrpc.Server     // pointer (8 bytes)
 }
 
-// NewServer creates a new mock MCP server.
-func NewServer() (*Server, error) {
-	// Create log directory - using a fixed, safe path
-	homeDir := os.Getenv("HOME")
-	if homeDir == "" {
-		// On Windows, try USERPROFILE if HOME is not set
-		homeDir = os.Getenv("USERPROFILE")
-		if homeDir == "" {
-			return nil, fmt.Errorf("HOME environment variable not set and USERPROFILE not found")
-		}
-	}
-
-	logDir := filepath.Join(homeDir, ".mcpt", "logs")
-	if err := os.MkdirAll(logDir, 0o750); err != nil {
-		return nil, fmt.Errorf("error creating log directory: %w", err)
-	}
-
-	// Open log file - using a fixed, safe path
-	logPath := filepath.Join(logDir, "mock.log")
-	// Clean the path to avoid any path traversal
-	logPath = filepath.Clean(logPath)
-
-	// Verify the path is still under the expected log directory
-	if !strings.HasPrefix(logPath, logDir) {
-		return nil, fmt.Errorf("invalid log path: outside of log directory")
-	}
-
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+// NewServer creates a new mock MCP server logging to ~/.mcpt/logs/mock.log.
+func NewServer(logOptions logfile.Options) (*Server, error) {
+	rpc, err := jsonrpc.NewServer("mock", logOptions)
 	if err != nil {
-		return nil, fmt.Errorf("error opening log file: %w", err)
+		return nil, err
 	}
 
-	fmt.Fprintf(os.Stderr, "Logging to %s\n", logPath)
-
-	return &Server{
-		id:        0,
+	s := &Server{
 		tools:     make(map[string]Tool),
 		prompts:   make(map[string]Prompt),
 		resources: make(map[string]Resource),
-		logFile:   logFile,
-	}, nil
-}
-
-// log writes a message to the log file with a timestamp.
-func (s *Server) log(message string) {
-	timestamp := time.Now().Format(time.RFC3339)
-	fmt.Fprintf(s.logFile, "[%s] %s\n", timestamp, message)
-}
-
-// logJSON writes a JSON-formatted message to the log file with a timestamp.
-func (s *Server) logJSON(label string, v any) {
-	jsonBytes, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		s.log(fmt.Sprintf("Error marshaling %s: %v", label, err))
-		return
+		rpc:       rpc,
 	}
-	s.log(fmt.Sprintf("%s: %s", label, string(jsonBytes)))
+	rpc.Handlers = map[string]jsonrpc.Handler{
+		"initialize": func(r jsonrpc.Request) (any, error) { return s.handleInitialize(r.Params), nil },
+		"notifications/initialized": func(jsonrpc.Request) (any, error) {
+			s.rpc.Log("Received initialization notification")
+			return nil, nil
+		},
+		"tools/list":     func(jsonrpc.Request) (any, error) { return s.handleToolsList(), nil },
+		"tools/call":     func(r jsonrpc.Request) (any, error) { return s.handleToolCall(r.Params) },
+		"resources/list": func(jsonrpc.Request) (any, error) { return s.handleResourcesList(), nil },
+		"resources/read": func(r jsonrpc.Request) (any, error) { return s.handleResourceRead(r.Params) },
+		"prompts/list":   func(jsonrpc.Request) (any, error) { return s.handlePromptsList(), nil },
+		"prompts/get":    func(r jsonrpc.Request) (any, error) { return s.handlePromptGet(r.Params) },
+	}
+	return s, nil
 }
 
 // Close closes the log file.
 func (s *Server) Close() error {
-	if s.logFile != nil {
-		return s.logFile.Close()
-	}
-	return nil
+	return s.rpc.Close()
 }
 
 // AddTool adds a new tool to the mock server.
@@ -136,82 +111,7 @@ func (s *Server) AddResource(uri, description, content string) {
 
 // Start begins listening for JSON-RPC requests on stdin and responding on stdout.
 func (s *Server) Start() error {
-	decoder := json.NewDecoder(os.Stdin)
-
-	s.log("Mock server started, waiting for requests...")
-	fmt.Fprintf(os.Stderr, "Mock server started, waiting for requests...\n")
-
-	// Check error from Close() when deferring
-	defer func() {
-		if err := s.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", err)
-		}
-	}()
-
-	for {
-		// Request struct with fields ordered for optimal memory alignment
-		var request struct {
-			Method  string         `json:"method"`           // string (16 bytes: pointer + len)
-			Params  map[string]any `json:"params,omitempty"` // map (8 bytes)
-			JSONRPC string         `json:"jsonrpc"`          // string (16 bytes: pointer + len)
-			ID      int            `json:"id"`               // int (8 bytes)
-		}
-
-		fmt.Fprintf(os.Stderr, "Waiting for request...\n")
-		if err := decoder.Decode(&request); err != nil {
-			if err == io.EOF {
-				s.log("Client disconnected (EOF)")
-				return nil
-			}
-			s.log(fmt.Sprintf("Error decoding request: %v", err))
-			fmt.Fprintf(os.Stderr, "Error decoding request: %v\n", err)
-			return fmt.Errorf("error decoding request: %w", err)
-		}
-
-		// Log the incoming request
-		s.logJSON("Received request", request)
-		fmt.Fprintf(os.Stderr, "Received request: %s (ID: %d)\n", request.Method, request.ID)
-		s.id = request.ID
-
-		// Handle notifications (methods without an ID)
-		if request.Method == "notifications/initialized" {
-			fmt.Fprintf(os.Stderr, "Received initialization notification\n")
-			s.log("Received initialization notification")
-			continue
-		}
-
-		var response any
-		var err error
-
-		switch request.Method {
-		case "initialize":
-			response = s.handleInitialize(request.Params)
-		case "tools/list":
-			response = s.handleToolsList()
-		case "tools/call":
-			response, err = s.handleToolCall(request.Params)
-		case "resources/list":
-			response = s.handleResourcesList()
-		case "resources/read":
-			response, err = s.handleResourceRead(request.Params)
-		case "prompts/list":
-			response = s.handlePromptsList()
-		case "prompts/get":
-			response, err = s.handlePromptGet(request.Params)
-		default:
-			err = fmt.Errorf("method not found")
-		}
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error handling request: %v\n", err)
-			s.log(fmt.Sprintf("Error handling request: %v", err))
-			s.writeError(err)
-			continue
-		}
-
-		fmt.Fprintf(os.Stderr, "Sending response\n")
-		s.writeResponse(response)
-	}
+	return s.rpc.Serve("Mock server")
 }
 
 // handleInitialize handles the initialize request from the client.
@@ -220,7 +120,7 @@ func (s *Server) handleInitialize(params map[string]any) map[string]any {
 	if clientInfo, ok := params["clientInfo"].(map[string]any); ok {
 		clientName, _ := clientInfo["name"].(string)
 		clientVersion, _ := clientInfo["version"].(string)
-		fmt.Fprintf(os.Stderr, "Client initialized: %s v%s\n", clientName, clientVersion)
+		s.rpc.Infof("Client initialized: %s v%s\n", clientName, clientVersion)
 	}
 
 	// Extract protocol version from params, defaulting to latest if not provided
@@ -457,7 +357,7 @@ func (s *Server) handlePromptGet(params map[string]any) (map[string]any, error)
 	// Get arguments if provided and substitute them in the template
 	if argsValue, hasArgs := params["arguments"]; hasArgs {
 		if args, isMap := argsValue.(map[string]any); isMap {
-			fmt.Fprintf(os.Stderr, "Prompt arguments received: %v\n", args)
+			s.rpc.Debugf("Prompt arguments received: %v\n", args)
 
 			// Simple placeholder substitution
 			for argName, argValue := range args {
@@ -496,57 +396,15 @@ func (s *Server) handlePromptGet(params map[string]any) (map[string]any, error)
 	}, nil
 }
 
-// writeResponse writes a successful JSON-RPC response to stdout.
-func (s *Server) writeResponse(result any) {
-	response := map[string]any{
-		"jsonrpc": "2.0",
-		"id":      s.id,
-		"result":  result,
-	}
-
-	// Log the outgoing response
-	s.logJSON("Sending response", response)
-
-	err := json.NewEncoder(os.Stdout).Encode(response)
-	if err != nil {
-		s.log(fmt.Sprintf("Error encoding response: %v", err))
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
-	}
-}
-
-// writeError writes a JSON-RPC error response to stdout.
-func (s *Server) writeError(err error) {
-	// Use method not found error code for unsupported methods
-	code := -32000 // Default server error
-	if err.Error() == "method not found" {
-		code = -32601 // Method not found error code
-	}
-
-	response := map[string]any{
-		"jsonrpc": "2.0",
-		"id":      s.id,
-		"error": map[string]any{
-			"code":    code,
-			"message": err.Error(),
-		},
-	}
-
-	// Log the outgoing error response
-	s.logJSON("Sending error response", response)
-
-	encodeErr := json.NewEncoder(os.Stdout).Encode(response)
-	if encodeErr != nil {
-		s.log(fmt.Sprintf("Error encoding error response: %v", encodeErr))
-		fmt.Fprintf(os.Stderr, "Error encoding error response: %v\n", encodeErr)
-	}
-}
-
-// RunMockServer creates and runs a mock MCP server with the specified entities.
-func RunMockServer(tools map[string]string, prompts map[string]map[string]string, resources map[string]map[string]string) error {
-	server, err := NewServer()
+// RunMockServer creates and runs a mock MCP server with the specified entities and options.
+func RunMockServer(tools map[string]string, prompts map[string]map[string]string, resources map[string]map[string]string, options Options) error {
+	server, err := NewServer(options.Log)
 	if err != nil {
 		return fmt.Errorf("error creating server: %w", err)
 	}
+	server.rpc.Quiet = options.Quiet
+	server.rpc.Debug = options.Debug
+	server.rpc.Infof("Logging to %s\n", server.rpc.LogPath())
 
 	// Add tools
 	for name, desc := range tools {
@@ -567,7 +425,7 @@ func RunMockServer(tools map[string]string, prompts map[string]map[string]string
 		server.AddResource(uri, desc, content)
 	}
 
-	server.log(fmt.Sprintf("Starting mock server with %d tools, %d prompts, and %d resources",
+	server.rpc.Log(fmt.Sprintf("Starting mock server with %d tools, %d prompts, and %d resources",
 		len(tools), len(prompts), len(resources)))
 
 	return server.Start()
@@ -0,0 +1,184 @@
+// Package openapi generates OpenAPI documents that describe the tools of an MCP server as an
+// HTTP API.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.1.0"
+
+// DefaultPathPrefix is the path under which tools are exposed when Options doesn't set one.
+const DefaultPathPrefix = "/tools"
+
+// callToolResultSchema describes the result of a tool call, which every tool returns.
+var callToolResultSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"content": map[string]any{
+			"type":        "array",
+			"description": "The content returned by the tool.",
+			"items":       map[string]any{"$ref": "#/components/schemas/Content"},
+		},
+		"structuredContent": map[string]any{
+			"type":        "object",
+			"description": "The structured result of tools that return one.",
+		},
+		"isError": map[string]any{
+			"type":        "boolean",
+			"description": "Whether the tool call failed.",
+		},
+	},
+	"required": []any{"content"},
+}
+
+// contentSchema describes one item of the content of a tool result.
+var contentSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"type": map[string]any{
+			"type": "string",
+			"enum": []any{"text", "image", "audio", "resource"},
+		},
+		"text":     map[string]any{"type": "string"},
+		"data":     map[string]any{"type": "string", "description": "Base64-encoded data of images and audio."},
+		"mimeType": map[string]any{"type": "string"},
+		"resource": map[string]any{"type": "object", "description": "An embedded resource."},
+	},
+	"required": []any{"type"},
+}
+
+// Options configures a generated document.
+type Options struct {
+	// Title is the title of the API. It defaults to "MCP tools".
+	Title string
+	// Version is the version of the API. It defaults to "1.0.0".
+	Version string
+	// ServerURL is the URL the API is served at. No server is listed when it is empty.
+	ServerURL string
+	// PathPrefix is prepended to tool names to build their paths. It defaults to
+	// DefaultPathPrefix.
+	PathPrefix string
+}
+
+// Generate returns an OpenAPI document in which each tool is a POST operation at
+// PathPrefix/<name>, taking the tool's input schema as its JSON request body and returning a
+// tool call result.
+func Generate(tools []mcp.Tool, options Options) (map[string]any, error) {
+	title := options.Title
+	if title == "" {
+		title = "MCP tools"
+	}
+	version := options.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	prefix := strings.TrimSuffix(options.PathPrefix, "/")
+	if options.PathPrefix == "" {
+		prefix = DefaultPathPrefix
+	}
+
+	paths := make(map[string]any, len(tools))
+	for _, tool := range tools {
+		schema, err := inputSchema(tool)
+		if err != nil {
+			return nil, err
+		}
+
+		path := prefix + "/" + url.PathEscape(tool.Name)
+		if _, exists := paths[path]; exists {
+			return nil, fmt.Errorf("duplicate tool name %q", tool.Name)
+		}
+		paths[path] = map[string]any{"post": operation(tool, schema)}
+	}
+
+	document := map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"CallToolResult": callToolResultSchema,
+				"Content":        contentSchema,
+			},
+		},
+	}
+	if options.ServerURL != "" {
+		document["servers"] = []any{map[string]any{"url": options.ServerURL}}
+	}
+	return document, nil
+}
+
+// operation returns the POST operation that calls tool.
+func operation(tool mcp.Tool, schema map[string]any) map[string]any {
+	op := map[string]any{
+		"operationId": tool.Name,
+		"requestBody": map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schema},
+			},
+		},
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "The result of the tool call.",
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": map[string]any{"$ref": "#/components/schemas/CallToolResult"},
+					},
+				},
+			},
+		},
+	}
+
+	description := strings.TrimSpace(tool.Description)
+	if description != "" {
+		summary, _, _ := strings.Cut(description, "\n")
+		op["summary"] = strings.TrimSpace(summary)
+		op["description"] = description
+	}
+	if tool.Annotations.Title != "" {
+		op["summary"] = tool.Annotations.Title
+	}
+	return op
+}
+
+// inputSchema returns the JSON schema of the arguments of tool, from its raw schema when it
+// has one.
+func inputSchema(tool mcp.Tool) (map[string]any, error) {
+	var data []byte
+	if tool.RawInputSchema != nil {
+		data = tool.RawInputSchema
+	} else {
+		var err error
+		if data, err = json.Marshal(tool.InputSchema); err != nil {
+			return nil, fmt.Errorf("error encoding the input schema of %s: %w", tool.Name, err)
+		}
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid input schema for %s: %w", tool.Name, err)
+	}
+	if schema == nil {
+		schema = map[string]any{}
+	}
+	if schema["type"] == nil || schema["type"] == "" {
+		schema["type"] = "object"
+	}
+	// An object without properties marshals without them, which OpenAPI tools read as any
+	// object; listing none makes tools without arguments explicit
+	if schema["type"] == "object" && schema["properties"] == nil {
+		schema["properties"] = map[string]any{}
+	}
+	return schema, nil
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGenerate(t *testing.T) {
+	tools := []mcp.Tool{
+		mcp.NewTool("search",
+			mcp.WithDescription("Search documents\nMatches are ranked by relevance."),
+			mcp.WithString("query", mcp.Required(), mcp.Description("What to search for")),
+		),
+		mcp.NewToolWithRawSchema("tag", "Tags a document", json.RawMessage(`{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string"}}},"additionalProperties":false}`)),
+		{Name: "ping"},
+	}
+
+	document, err := Generate(tools, Options{Title: "Docs", ServerURL: "http://localhost:8080"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if document["openapi"] != Version {
+		t.Errorf("openapi = %v, want %s", document["openapi"], Version)
+	}
+	if info := document["info"].(map[string]any); info["title"] != "Docs" || info["version"] != "1.0.0" {
+		t.Errorf("unexpected info: %v", info)
+	}
+	if servers := document["servers"].([]any); len(servers) != 1 {
+		t.Errorf("unexpected servers: %v", servers)
+	}
+
+	paths := document["paths"].(map[string]any)
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 paths, got %v", paths)
+	}
+
+	search := paths["/tools/search"].(map[string]any)["post"].(map[string]any)
+	if search["operationId"] != "search" || search["summary"] != "Search documents" {
+		t.Errorf("unexpected search operation: %v", search)
+	}
+	schema := requestSchema(t, search)
+	if required, _ := schema["required"].([]any); len(required) != 1 || required[0] != "query" {
+		t.Errorf("unexpected search schema: %v", schema)
+	}
+
+	tag := paths["/tools/tag"].(map[string]any)["post"].(map[string]any)
+	if schema := requestSchema(t, tag); schema["additionalProperties"] != false {
+		t.Errorf("expected the raw schema of tag, got %v", schema)
+	}
+
+	ping := paths["/tools/ping"].(map[string]any)["post"].(map[string]any)
+	if schema := requestSchema(t, ping); schema["type"] != "object" || schema["properties"] == nil {
+		t.Errorf("expected an object schema without properties for ping, got %v", schema)
+	}
+}
+
+func TestGenerateDuplicateTool(t *testing.T) {
+	_, err := Generate([]mcp.Tool{{Name: "a"}, {Name: "a"}}, Options{})
+	if err == nil {
+		t.Error("expected an error for duplicate tool names")
+	}
+}
+
+// requestSchema returns the request body schema of an operation, decoded from JSON like a
+// consumer of the document would.
+func requestSchema(t *testing.T, operation map[string]any) map[string]any {
+	t.Helper()
+
+	data, err := json.Marshal(operation)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		RequestBody struct {
+			Content map[string]struct {
+				Schema map[string]any `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return decoded.RequestBody.Content["application/json"].Schema
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package proxy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// shell returns the shell that runs tools, bash when it is installed and sh otherwise.
+func shell() string {
+	info, err := os.Stat("/bin/bash")
+	if err == nil && !info.IsDir() {
+		return "/bin/bash"
+	}
+	return "/bin/sh"
+}
+
+// commandCmd returns the command that runs an inline command with the shell.
+func commandCmd(ctx context.Context, command string) *exec.Cmd {
+	// #nosec G204 - Command is validated and comes from a trusted source (config)
+	return exec.CommandContext(ctx, shell(), "-c", command)
+}
+
+// scriptCmd returns the command that runs a script with the shell.
+func scriptCmd(ctx context.Context, scriptPath string) *exec.Cmd {
+	// #nosec G204 - scriptPath is validated and comes from a trusted source (config)
+	return exec.CommandContext(ctx, shell(), "-c", scriptPath)
+}
+
+// isExecutable reports whether a script has one of its executable bits set.
+func isExecutable(_ string, info os.FileInfo) bool {
+	return info.Mode()&0o111 != 0
+}
+
+// setProcessGroup starts the command in a new process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the command's process group, including any processes it spawned.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,87 @@
+//go:build windows
+
+package proxy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// defaultPathExt is used when PATHEXT isn't set.
+const defaultPathExt = ".COM;.EXE;.BAT;.CMD"
+
+// commandProcessor returns the path of cmd.exe.
+func commandProcessor() string {
+	if comspec := os.Getenv("COMSPEC"); comspec != "" {
+		return comspec
+	}
+	return "cmd.exe"
+}
+
+// commandCmd returns the command that runs an inline command with cmd.exe.
+func commandCmd(ctx context.Context, command string) *exec.Cmd {
+	comspec := commandProcessor()
+	// #nosec G204 - Command is validated and comes from a trusted source (config)
+	cmd := exec.CommandContext(ctx, comspec, "/S", "/C", command)
+	// cmd.exe doesn't follow the quoting rules used to build command lines from arguments.
+	// With /S it removes the outer quotes and runs the rest as-is.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: `"` + comspec + `" /S /C "` + command + `"`,
+	}
+	return cmd
+}
+
+// scriptCmd returns the command that runs a script, with PowerShell for .ps1 scripts when it
+// is installed and with cmd.exe otherwise.
+func scriptCmd(ctx context.Context, scriptPath string) *exec.Cmd {
+	if strings.EqualFold(filepath.Ext(scriptPath), ".ps1") {
+		if powerShell := findPowerShell(); powerShell != "" {
+			// #nosec G204 - scriptPath is validated and comes from a trusted source (config)
+			return exec.CommandContext(ctx, powerShell, "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", scriptPath)
+		}
+	}
+	return commandCmd(ctx, `"`+scriptPath+`"`)
+}
+
+// findPowerShell returns the path of PowerShell, preferring PowerShell 7, or "" when neither
+// is installed.
+func findPowerShell() string {
+	for _, name := range []string{"pwsh.exe", "powershell.exe"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// isExecutable reports whether a script can be run, which Windows decides by its extension
+// rather than by mode bits. Besides the extensions in PATHEXT, PowerShell scripts are accepted.
+func isExecutable(path string, _ os.FileInfo) bool {
+	ext := filepath.Ext(path)
+	if strings.EqualFold(ext, ".ps1") {
+		return true
+	}
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = defaultPathExt
+	}
+	for _, executable := range strings.Split(pathExt, ";") {
+		if executable != "" && strings.EqualFold(ext, executable) {
+			return true
+		}
+	}
+	return false
+}
+
+// setProcessGroup is a no-op on Windows.
+func setProcessGroup(_ *exec.Cmd) {}
+
+// killProcessGroup kills the command's process. Windows has no process groups, so processes
+// it spawned keep running.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIsExecutableWindows(t *testing.T) {
+	t.Setenv("PATHEXT", ".COM;.EXE;.BAT;.CMD")
+
+	testCases := map[string]bool{
+		`C:\tools\build.bat`: true,
+		`C:\tools\build.CMD`: true,
+		`C:\tools\run.exe`:   true,
+		`C:\tools\run.ps1`:   true,
+		`C:\tools\run.sh`:    false,
+		`C:\tools\notes.txt`: false,
+	}
+	for path, expected := range testCases {
+		if got := isExecutable(path, nil); got != expected {
+			t.Errorf("isExecutable(%q) = %v, want %v", path, got, expected)
+		}
+	}
+}
+
+func TestProxyCommandWindows(t *testing.T) {
+	server := newTestServer(t)
+
+	if err := server.AddTool("greet", "Greets", "name:string", "", "echo hello %name%"); err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	text, err := callToolText(t, server, "greet", map[string]interface{}{"name": "world"})
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if strings.TrimSpace(text) != "hello world" {
+		t.Errorf("output = %q, want %q", text, "hello world")
+	}
+
+	cmd := commandCmd(context.Background(), `echo "a b"`)
+	if expected := `" /S /C "echo "a b""`; !strings.HasSuffix(cmd.SysProcAttr.CmdLine, expected) {
+		t.Errorf("command line = %q, want it to end with %q", cmd.SysProcAttr.CmdLine, expected)
+	}
+}
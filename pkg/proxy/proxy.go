@@ -2,18 +2,69 @@
 package proxy
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/f/mcptools/pkg/jsonrpc"
 	"github.com/f/mcptools/pkg/jsonutils"
+	"github.com/f/mcptools/pkg/logfile"
 )
 
+// DefaultToolTimeout is how long a tool's script or command may run before it is killed.
+const DefaultToolTimeout = 60 * time.Second
+
+// ErrToolTimeout is returned when a tool's script or command runs past its timeout.
+var ErrToolTimeout = errors.New("tool timed out")
+
+// Output modes of a tool.
+const (
+	// OutputText returns the script output as text content.
+	OutputText = "text"
+	// OutputJSON parses the script output as JSON and returns it as structured content.
+	OutputJSON = "json"
+)
+
+// envArgPrefix prefixes the environment variables that hold tool arguments, so that they
+// can't replace variables such as PATH or HOME.
+const envArgPrefix = "MCP_ARG_"
+
+// truncatedMarker is appended to tool output that exceeded the configured size limit.
+const truncatedMarker = "\n[output truncated]\n"
+
+// Options configures how the proxy server executes tools.
+type Options struct {
+	// MaxOutputSize limits how many bytes of script output are kept. Zero means no limit.
+	MaxOutputSize int64
+	// DryRun makes tool calls return the command they would run, with its environment and
+	// stdin, instead of running it.
+	DryRun bool
+	// PrefixedEnvOnly exports arguments only as MCP_ARG_<name> variables, without the
+	// variables named after the parameters themselves.
+	PrefixedEnvOnly bool
+	// WorkingDir is the directory tools run in when their config doesn't set a "cwd".
+	// Empty runs them in the current directory.
+	WorkingDir string
+	// Quiet limits stderr to errors and the line saying the server started. Everything is
+	// written to the log file either way.
+	Quiet bool
+	// Debug also writes a line to stderr for every request and response.
+	Debug bool
+	// Log configures the rotation of the log file.
+	Log logfile.Options
+}
+
 // Parameter represents a tool parameter with a name and type.
 type Parameter struct {
 	Name     string
@@ -29,97 +80,115 @@ type Tool struct {
 	ScriptPath  string
 	Command     string // Inline command to execute
 	Parameters  []Parameter
+	// InputSchema is the full JSON schema for tools registered from a schema file.
+	// When nil, the schema is generated from Parameters.
+	InputSchema map[string]interface{}
+	// Timeout is how long the script or command may run before it is killed. Zero means
+	// no limit.
+	Timeout time.Duration
+	// Output is how the script output is returned, OutputText or OutputJSON.
+	Output string
+	// Dir is the directory the script or command runs in. Empty means the current directory.
+	Dir string
 }
 
 // Server handles proxying requests to shell scripts.
 type Server struct {
 	// Fields ordered for optimal memory alignment (8-byte aligned fields first)
 	tools   map[string]Tool
-	logFile *os.File
-	id      int
+	rpc     *jsonrpc.Server
+	options Options
 }
 
-// NewProxyServer creates a new proxy server.
-func NewProxyServer() (*Server, error) {
-	// Create log directory
-	homeDir := os.Getenv("HOME")
-	if homeDir == "" {
-		// On Windows, try USERPROFILE if HOME is not set
-		homeDir = os.Getenv("USERPROFILE")
-		if homeDir == "" {
-			return nil, fmt.Errorf("HOME environment variable not set and USERPROFILE not found")
-		}
+// NewProxyServer creates a new proxy server logging to ~/.mcpt/logs/proxy.log.
+func NewProxyServer(logOptions logfile.Options) (*Server, error) {
+	rpc, err := jsonrpc.NewServer("proxy", logOptions)
+	if err != nil {
+		return nil, err
 	}
 
-	logDir := filepath.Join(homeDir, ".mcpt", "logs")
-	if err := os.MkdirAll(logDir, 0o750); err != nil {
-		return nil, fmt.Errorf("error creating log directory: %w", err)
+	s := &Server{
+		tools: make(map[string]Tool),
+		rpc:   rpc,
+	}
+	rpc.Handlers = map[string]jsonrpc.Handler{
+		"initialize": func(r jsonrpc.Request) (any, error) { return s.handleInitialize(r.Params), nil },
+		"notifications/initialized": func(jsonrpc.Request) (any, error) {
+			s.rpc.Log("Received initialization notification")
+			return nil, nil
+		},
+		"tools/list": func(jsonrpc.Request) (any, error) { return s.handleToolsList(), nil },
+		"tools/call": func(r jsonrpc.Request) (any, error) { return s.handleToolCall(r.Params) },
+		// The proxy only serves tools, but answers the other list methods with empty lists
+		// because some clients treat method not found as a protocol error.
+		"resources/list":           emptyList("resources"),
+		"resources/templates/list": emptyList("resourceTemplates"),
+		"prompts/list":             emptyList("prompts"),
 	}
+	return s, nil
+}
 
-	// Open log file
-	logPath := filepath.Join(logDir, "proxy.log")
-	// Clean the path to avoid any path traversal
-	logPath = filepath.Clean(logPath)
+// Close closes the log file.
+func (s *Server) Close() error {
+	return s.rpc.Close()
+}
 
-	// Verify the path is still under the expected log directory
-	if !strings.HasPrefix(logPath, logDir) {
-		return nil, fmt.Errorf("invalid log path: outside of log directory")
+// emptyList returns a handler answering a list method with no entries and no next page.
+func emptyList(key string) jsonrpc.Handler {
+	return func(jsonrpc.Request) (any, error) {
+		return map[string]interface{}{key: []interface{}{}}, nil
 	}
+}
 
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+// AddTool adds a new tool to the proxy server.
+func (s *Server) AddTool(name, description, paramStr, scriptPath string, command string) error {
+	// Parse parameters
+	params, err := parseParameters(paramStr)
 	if err != nil {
-		return nil, fmt.Errorf("error opening log file: %w", err)
+		return fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Logging to %s\n", logPath)
-
-	return &Server{
-		tools:   make(map[string]Tool),
-		id:      0,
-		logFile: logFile,
-	}, nil
-}
-
-// log writes a message to the log file with a timestamp.
-func (s *Server) log(message string) {
-	timestamp := time.Now().Format(time.RFC3339)
-	fmt.Fprintf(s.logFile, "[%s] %s\n", timestamp, message)
+	return s.addTool(Tool{
+		Name:        name,
+		Description: description,
+		Parameters:  params,
+	}, scriptPath, command)
 }
 
-// logJSON writes a JSON-formatted message to the log file with a timestamp.
-func (s *Server) logJSON(label string, v any) {
-	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+// AddToolWithSchema adds a new tool whose input is described by a full JSON schema.
+// The schema is served as-is in tools/list, and its top-level properties are mapped
+// to parameters so that arguments are still exported as environment variables.
+func (s *Server) AddToolWithSchema(name, description string, schema map[string]interface{}, scriptPath, command string) error {
+	params, err := parametersFromSchema(schema)
 	if err != nil {
-		s.log(fmt.Sprintf("Error marshaling %s: %v", label, err))
-		return
+		return fmt.Errorf("invalid schema: %w", err)
 	}
-	s.log(fmt.Sprintf("%s: %s", label, string(jsonBytes)))
-}
 
-// Close closes the log file.
-func (s *Server) Close() error {
-	if s.logFile != nil {
-		return s.logFile.Close()
+	if description == "" {
+		description, _ = schema["description"].(string)
 	}
-	return nil
+
+	return s.addTool(Tool{
+		Name:        name,
+		Description: description,
+		Parameters:  params,
+		InputSchema: schema,
+	}, scriptPath, command)
 }
 
-// AddTool adds a new tool to the proxy server.
-func (s *Server) AddTool(name, description, paramStr, scriptPath string, command string) error {
-	// Parse parameters
-	params, err := parseParameters(paramStr)
-	if err != nil {
-		return fmt.Errorf("invalid parameters: %w", err)
+// addTool validates the script or command of a tool and registers it.
+func (s *Server) addTool(tool Tool, scriptPath, command string) error {
+	if err := checkEnvNames(tool.Parameters); err != nil {
+		return err
 	}
 
+	tool.Timeout = DefaultToolTimeout
+	tool.Output = OutputText
+
 	// If a command is provided, use it directly
 	if command != "" {
-		s.tools[name] = Tool{
-			Name:        name,
-			Description: description,
-			Parameters:  params,
-			Command:     command,
-		}
+		tool.Command = command
+		s.tools[tool.Name] = tool
 		return nil
 	}
 
@@ -143,20 +212,106 @@ func (s *Server) AddTool(name, description, paramStr, scriptPath string, command
 	}
 
 	// Additional security check: verify the file is executable
-	if info.Mode()&0o111 == 0 {
+	if !isExecutable(absPath, info) {
 		return fmt.Errorf("script is not executable: %s", absPath)
 	}
 
-	s.tools[name] = Tool{
-		Name:        name,
-		Description: description,
-		Parameters:  params,
-		ScriptPath:  absPath,
+	tool.ScriptPath = absPath
+	s.tools[tool.Name] = tool
+
+	return nil
+}
+
+// SetToolTimeout sets how long a tool may run before it is killed. Zero means no limit.
+func (s *Server) SetToolTimeout(name string, timeout time.Duration) error {
+	tool, exists := s.tools[name]
+	if !exists {
+		return fmt.Errorf("tool not found: %s", name)
 	}
+	tool.Timeout = timeout
+	s.tools[name] = tool
+	return nil
+}
 
+// SetToolDir sets the directory a tool runs in, expanding a leading ~ to the home directory.
+func (s *Server) SetToolDir(name, dir string) error {
+	tool, exists := s.tools[name]
+	if !exists {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	resolved, err := resolveDir(dir)
+	if err != nil {
+		return err
+	}
+	tool.Dir = resolved
+	s.tools[name] = tool
 	return nil
 }
 
+// resolveDir returns the absolute path of a directory, expanding a leading ~ to the home
+// directory, and checks that it exists.
+func resolveDir(dir string) (string, error) {
+	absDir, err := filepath.Abs(expandHome(dir))
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory: %w", err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return "", fmt.Errorf("working directory not found: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working directory %s is not a directory", absDir)
+	}
+	return absDir, nil
+}
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// SetToolOutput sets how a tool returns the output of its script, OutputText or OutputJSON.
+func (s *Server) SetToolOutput(name, output string) error {
+	tool, exists := s.tools[name]
+	if !exists {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	if output != OutputText && output != OutputJSON {
+		return fmt.Errorf("invalid output %q, expected %s or %s", output, OutputText, OutputJSON)
+	}
+	tool.Output = output
+	s.tools[name] = tool
+	return nil
+}
+
+// ParseToolTimeout parses the timeout of a tool config, which is a duration such as "90s" or
+// "2m", or a number of seconds. Zero disables the timeout.
+func ParseToolTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("timeout can't be negative: %s", value)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q, expected a duration such as 90s or a number of seconds", value)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("timeout can't be negative: %s", value)
+	}
+	return timeout, nil
+}
+
 // parseParameters parses a comma-separated parameter string in the format "name:type,name:type".
 // If a parameter is wrapped in square brackets like [name:type], it's considered optional.
 func parseParameters(paramStr string) ([]Parameter, error) {
@@ -211,6 +366,62 @@ func parseParameters(paramStr string) ([]Parameter, error) {
 	return parameters, nil
 }
 
+// parametersFromSchema maps the top-level properties of an object JSON schema to parameters.
+// Properties that are not strings, numbers or booleans are passed through as JSON.
+func parametersFromSchema(schema map[string]interface{}) ([]Parameter, error) {
+	if schemaType, ok := schema["type"]; ok && schemaType != "object" {
+		return nil, fmt.Errorf("schema type must be object, got %v", schemaType)
+	}
+
+	properties := map[string]interface{}{}
+	if value, ok := schema["properties"]; ok {
+		properties, ok = value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("schema properties must be an object")
+		}
+	}
+
+	required := make(map[string]bool)
+	if value, ok := schema["required"].([]interface{}); ok {
+		for _, name := range value {
+			if nameStr, ok := name.(string); ok {
+				required[nameStr] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := make([]Parameter, 0, len(names))
+	for _, name := range names {
+		paramType := "json"
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			switch property["type"] {
+			case "string":
+				paramType = "string"
+			case "integer":
+				paramType = "int"
+			case "number":
+				paramType = "float"
+			case "boolean":
+				paramType = "bool"
+			}
+		}
+
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			Type:     paramType,
+			Required: required[name],
+		})
+	}
+
+	return parameters, nil
+}
+
 // ExecuteScript executes a shell script or command with the given parameters.
 func (s *Server) ExecuteScript(toolName string, args map[string]interface{}) (string, error) {
 	tool, exists := s.tools[toolName]
@@ -218,26 +429,42 @@ func (s *Server) ExecuteScript(toolName string, args map[string]interface{}) (st
 		return "", fmt.Errorf("tool not found: %s", toolName)
 	}
 
-	// Set up environment variables for the script/command
-	env := os.Environ()
+	// Convert the arguments to the types declared by the tool
+	args, err := coerceArguments(tool.Parameters, args)
+	if err != nil {
+		return "", err
+	}
+
+	// Set up environment variables for the script/command.
+	// Nested objects and arrays are exported as compact JSON; scripts that need them
+	// should prefer reading the JSON arguments from stdin instead.
+	// MCP_TOOL_NAME lets a single script serve several tools.
+	toolEnv := []string{"MCP_TOOL_NAME=" + toolName}
 	for name, value := range args {
-		// Convert value to string
-		strValue := fmt.Sprintf("%v", value)
-		env = append(env, fmt.Sprintf("%s=%s", name, strValue))
+		formatted := formatEnvValue(value)
+		toolEnv = append(toolEnv, envArgPrefix+envName(name)+"="+formatted)
+		if s.options.PrefixedEnvOnly {
+			continue
+		}
+		if reason := bareEnvConflict(name); reason != "" {
+			s.rpc.Log(fmt.Sprintf("Argument %s of %s is only exported as %s%s: %s", name, toolName, envArgPrefix, envName(name), reason))
+			continue
+		}
+		toolEnv = append(toolEnv, name+"="+formatted)
 	}
+	env := append(os.Environ(), toolEnv...)
 
-	// Determine which shell to use for executing the script/command
-	shell := "/bin/sh"
-	bashExists, statErr := os.Stat("/bin/bash")
-	if statErr == nil && !bashExists.IsDir() {
-		shell = "/bin/bash"
+	ctx := context.Background()
+	if tool.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tool.Timeout)
+		defer cancel()
 	}
 
 	var cmd *exec.Cmd
 	if tool.Command != "" {
 		// Use the inline command
-		// #nosec G204 - Command is validated and comes from a trusted source (config)
-		cmd = exec.Command(shell, "-c", tool.Command)
+		cmd = commandCmd(ctx, tool.Command)
 	} else {
 		// Use the script file
 		scriptPath := filepath.Clean(tool.ScriptPath)
@@ -248,23 +475,249 @@ func (s *Server) ExecuteScript(toolName string, args map[string]interface{}) (st
 		if info.IsDir() {
 			return "", fmt.Errorf("not a script: %s is a directory", scriptPath)
 		}
-		if info.Mode()&0o111 == 0 {
+		if !isExecutable(scriptPath, info) {
 			return "", fmt.Errorf("script is not executable: %s", scriptPath)
 		}
-		// #nosec G204 - scriptPath is validated and comes from a trusted source (config)
-		cmd = exec.Command(shell, "-c", scriptPath)
+		cmd = scriptCmd(ctx, scriptPath)
+	}
+
+	// Pass the full arguments object as JSON on stdin
+	stdinJSON, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling arguments: %w", err)
+	}
+
+	cmd.Dir = tool.Dir
+
+	if s.options.DryRun {
+		report := dryRunReport(cmd.Args, cmd.Dir, toolEnv, stdinJSON)
+		s.rpc.Log(fmt.Sprintf("Dry run of %s:\n%s", toolName, report))
+		return report, nil
 	}
 
 	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(stdinJSON)
 	cmd.Stderr = os.Stderr
 
-	// Execute and capture output
-	output, err := cmd.Output()
+	// On timeout, kill the whole process group so that processes started by the script
+	// don't keep its output open
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	cmd.WaitDelay = time.Second
+
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		return "", fmt.Errorf("error creating stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("error executing command: %w", err)
+	}
+
+	// Stream the output instead of buffering it all at once
+	output, readErr := readOutput(stdout, s.options.MaxOutputSize)
+	if err := cmd.Wait(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s was killed after %v", ErrToolTimeout, toolName, tool.Timeout)
+		}
 		return "", fmt.Errorf("error executing command: %w", err)
 	}
+	if readErr != nil {
+		return "", fmt.Errorf("error reading command output: %w", readErr)
+	}
+
+	return output, nil
+}
+
+// envName converts a parameter name into an environment variable name by replacing every
+// character other than ASCII letters, digits and underscores with an underscore.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// bareEnvConflict returns why an argument can't also be exported under its own name, or ""
+// when it can. Names that aren't valid variable names, that start with MCP_, or that are
+// already set in the proxy's environment, such as PATH or HOME, are only exported with the
+// MCP_ARG_ prefix.
+func bareEnvConflict(name string) string {
+	switch {
+	case name == "" || envName(name) != name || (name[0] >= '0' && name[0] <= '9'):
+		return "not a valid variable name"
+	case strings.HasPrefix(name, "MCP_"):
+		return "reserved prefix MCP_"
+	}
+	if _, set := os.LookupEnv(name); set {
+		return "would replace an inherited variable"
+	}
+	return ""
+}
+
+// checkEnvNames fails when two parameters would be exported as the same environment variable.
+func checkEnvNames(params []Parameter) error {
+	seen := make(map[string]string, len(params))
+	for _, param := range params {
+		name := envName(param.Name)
+		if other, exists := seen[name]; exists {
+			return fmt.Errorf("parameters %q and %q are both exported as %s%s", other, param.Name, envArgPrefix, name)
+		}
+		seen[name] = param.Name
+	}
+	return nil
+}
+
+// dryRunReport describes the command a tool call would run: the shell command line, the
+// command with the tool's variables expanded, the directory it runs in, the variables set for
+// it and its stdin.
+func dryRunReport(args []string, dir string, toolEnv []string, stdin []byte) string {
+	sort.Strings(toolEnv[1:])
+	values := make(map[string]string, len(toolEnv))
+	for _, entry := range toolEnv {
+		name, value, _ := strings.Cut(entry, "=")
+		values[name] = value
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	expanded := os.Expand(args[len(args)-1], func(name string) string {
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return os.Getenv(name)
+	})
+
+	var b strings.Builder
+	b.WriteString("Dry run, the command was not executed.\n")
+	fmt.Fprintf(&b, "Command: %s\n", strings.Join(quoted, " "))
+	fmt.Fprintf(&b, "Expanded: %s\n", expanded)
+	if dir != "" {
+		fmt.Fprintf(&b, "Directory: %s\n", dir)
+	}
+	b.WriteString("Environment:\n")
+	for _, entry := range toolEnv {
+		fmt.Fprintf(&b, "  %s\n", entry)
+	}
+	fmt.Fprintf(&b, "Stdin: %s\n", stdin)
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell when it contains characters the shell would
+// interpret.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%", r)
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// coerceArguments converts each argument to the type declared for it by the tool's parameters.
+// Arguments without a declared parameter are passed through unchanged.
+func coerceArguments(params []Parameter, args map[string]interface{}) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		coerced[name] = value
+	}
+
+	for _, param := range params {
+		value, exists := coerced[param.Name]
+		if !exists || value == nil {
+			continue
+		}
+
+		converted, err := coerceValue(param.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter %s: %w", param.Name, err)
+		}
+		coerced[param.Name] = converted
+	}
+
+	return coerced, nil
+}
+
+// coerceValue converts a value to the given parameter type.
+// Strings are parsed for the numeric and boolean types, since clients often send them quoted.
+func coerceValue(paramType string, value interface{}) (interface{}, error) {
+	if paramType == "string" {
+		if _, ok := value.(string); ok {
+			return value, nil
+		}
+		return formatEnvValue(value), nil
+	}
+	return jsonutils.CoerceValue(paramType, value)
+}
+
+// formatEnvValue renders a value for use in an environment variable.
+// Booleans become true/false, numbers are written without exponents or quotes,
+// and objects and arrays are written as compact JSON.
+func formatEnvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsonBytes)
+	}
+}
+
+// readOutput reads r until EOF, keeping at most maxSize bytes, or everything when maxSize is
+// zero. Output beyond the limit is drained and discarded without being held in memory, so the
+// process never blocks on a full pipe, and a marker is appended to show that the result is
+// incomplete. Truncated output is cut at the start of the rune that didn't fit whole.
+func readOutput(r io.Reader, maxSize int64) (string, error) {
+	if maxSize <= 0 {
+		output, err := io.ReadAll(r)
+		return string(output), err
+	}
+
+	output, err := io.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return "", err
+	}
+	rest, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return "", err
+	}
+	if rest == 0 {
+		return string(output), nil
+	}
 
-	return string(output), nil
+	return string(trimPartialRune(output)) + truncatedMarker, nil
+}
+
+// trimPartialRune removes the incomplete UTF-8 sequence that data ends with, if any.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			return data
+		}
+	}
+	return data
 }
 
 // GetToolSchema generates a JSON schema for the tool's parameters.
@@ -274,6 +727,10 @@ func (s *Server) GetToolSchema(toolName string) (map[string]interface{}, error)
 		return nil, fmt.Errorf("tool not found: %s", toolName)
 	}
 
+	if tool.InputSchema != nil {
+		return tool.InputSchema, nil
+	}
+
 	properties := make(map[string]interface{})
 	required := make([]string, 0, len(tool.Parameters))
 
@@ -321,74 +778,7 @@ func (s *Server) GetToolSchema(toolName string) (map[string]interface{}, error)
 
 // Start begins listening for JSON-RPC requests on stdin and responding on stdout.
 func (s *Server) Start() error {
-	decoder := json.NewDecoder(os.Stdin)
-
-	s.log("Proxy server started, waiting for requests...")
-	fmt.Fprintf(os.Stderr, "Proxy server started, waiting for requests...\n")
-
-	// Check error from Close() when deferring
-	defer func() {
-		if err := s.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", err)
-		}
-	}()
-
-	for {
-		// Request struct with fields ordered for optimal memory alignment
-		var request struct {
-			Method  string                 `json:"method"`           // string (16 bytes: pointer + len)
-			Params  map[string]interface{} `json:"params,omitempty"` // map (8 bytes)
-			JSONRPC string                 `json:"jsonrpc"`          // string (16 bytes: pointer + len)
-			ID      int                    `json:"id"`               // int (8 bytes)
-		}
-
-		fmt.Fprintf(os.Stderr, "Waiting for request...\n")
-		if err := decoder.Decode(&request); err != nil {
-			if err == io.EOF {
-				s.log("Client disconnected (EOF)")
-				return nil
-			}
-			s.log(fmt.Sprintf("Error decoding request: %v", err))
-			fmt.Fprintf(os.Stderr, "Error decoding request: %v\n", err)
-			return fmt.Errorf("error decoding request: %w", err)
-		}
-
-		// Log the incoming request
-		s.logJSON("Received request", request)
-		fmt.Fprintf(os.Stderr, "Received request: %s (ID: %d)\n", request.Method, request.ID)
-		s.id = request.ID
-
-		// Handle notifications (methods without an ID)
-		if request.Method == "notifications/initialized" {
-			fmt.Fprintf(os.Stderr, "Received initialization notification\n")
-			s.log("Received initialization notification")
-			continue
-		}
-
-		var response any
-		var err error
-
-		switch request.Method {
-		case "initialize":
-			response = s.handleInitialize(request.Params)
-		case "tools/list":
-			response = s.handleToolsList()
-		case "tools/call":
-			response, err = s.handleToolCall(request.Params)
-		default:
-			err = fmt.Errorf("method not found")
-		}
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error handling request: %v\n", err)
-			s.log(fmt.Sprintf("Error handling request: %v", err))
-			s.writeError(err)
-			continue
-		}
-
-		fmt.Fprintf(os.Stderr, "Sending response\n")
-		s.writeResponse(response)
-	}
+	return s.rpc.Serve("Proxy server")
 }
 
 // handleInitialize handles the initialize request from the client.
@@ -397,7 +787,7 @@ func (s *Server) handleInitialize(params map[string]interface{}) map[string]inte
 	if clientInfo, ok := params["clientInfo"].(map[string]interface{}); ok {
 		clientName, _ := clientInfo["name"].(string)
 		clientVersion, _ := clientInfo["version"].(string)
-		fmt.Fprintf(os.Stderr, "Client initialized: %s v%s\n", clientName, clientVersion)
+		s.rpc.Infof("Client initialized: %s v%s\n", clientName, clientVersion)
 	}
 
 	// Extract protocol version from params, defaulting to latest if not provided
@@ -406,7 +796,8 @@ func (s *Server) handleInitialize(params map[string]interface{}) map[string]inte
 		protocolVersion = version
 	}
 
-	// Return server information and capabilities in the format expected by clients
+	// Return server information and capabilities in the format expected by clients. Only tools
+	// are advertised, even though resources/list and prompts/list are answered.
 	capabilities := map[string]interface{}{
 		"tools": map[string]interface{}{},
 	}
@@ -425,48 +816,10 @@ func (s *Server) handleInitialize(params map[string]interface{}) map[string]inte
 func (s *Server) handleToolsList() map[string]interface{} {
 	tools := make([]map[string]interface{}, 0, len(s.tools))
 
-	for _, tool := range s.tools {
-		// Generate schema directly from the tool parameters
-		properties := make(map[string]interface{})
-		required := make([]string, 0, len(tool.Parameters))
-
-		for _, param := range tool.Parameters {
-			var paramSchema map[string]interface{}
-
-			switch param.Type {
-			case "string":
-				paramSchema = map[string]interface{}{
-					"type": "string",
-				}
-			case "int":
-				paramSchema = map[string]interface{}{
-					"type": "integer",
-				}
-			case "float":
-				paramSchema = map[string]interface{}{
-					"type": "number",
-				}
-			case "bool":
-				paramSchema = map[string]interface{}{
-					"type": "boolean",
-				}
-			}
-
-			properties[param.Name] = paramSchema
-
-			// Only add to required list if the parameter is required
-			if param.Required {
-				required = append(required, param.Name)
-			}
-		}
-
-		schema := map[string]interface{}{
-			"type":       "object",
-			"properties": properties,
-		}
-
-		if len(required) > 0 {
-			schema["required"] = required
+	for name, tool := range s.tools {
+		schema, err := s.GetToolSchema(name)
+		if err != nil {
+			continue
 		}
 
 		tools = append(tools, map[string]interface{}{
@@ -519,17 +872,34 @@ func (s *Server) handleToolCall(params map[string]interface{}) (map[string]inter
 	}
 
 	// Log the input parameters
-	s.logJSON("Tool input", arguments)
+	s.rpc.LogJSON("Tool input", arguments)
 
 	// Execute the shell script
 	output, err := s.ExecuteScript(name, arguments)
+	if errors.Is(err, ErrToolTimeout) {
+		// A timeout is reported as a failed tool call, so the caller sees it as the result
+		s.rpc.Log(fmt.Sprintf("Timeout executing script: %v", err))
+		return map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": err.Error(),
+				},
+			},
+			"isError": true,
+		}, nil
+	}
 	if err != nil {
-		s.log(fmt.Sprintf("Error executing script: %v", err))
+		s.rpc.Log(fmt.Sprintf("Error executing script: %v", err))
 		return nil, fmt.Errorf("error executing script: %w", err)
 	}
 
 	// Log the output
-	s.log(fmt.Sprintf("Script output: %s", output))
+	s.rpc.Log(fmt.Sprintf("Script output: %s", output))
+
+	if tool.Output == OutputJSON && !s.options.DryRun {
+		return jsonToolResult(name, output), nil
+	}
 
 	// Return the output in the correct format for the MCP protocol
 	// Check if the output is a base64-encoded PNG image
@@ -560,75 +930,127 @@ func (s *Server) handleToolCall(params map[string]interface{}) (map[string]inter
 	}, nil
 }
 
-// writeResponse writes a successful JSON-RPC response to stdout.
-func (s *Server) writeResponse(result any) {
-	response := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      s.id,
-		"result":  result,
+// jsonToolResult returns the JSON output of a tool as structured content, along with its
+// text for clients that don't read structured content. Output that isn't a JSON object is
+// wrapped in one under "result", and output that isn't valid JSON fails the call.
+func jsonToolResult(name, output string) map[string]interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(output), &value); err != nil {
+		return map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("tool %s returned invalid JSON: %v", name, err),
+				},
+			},
+			"isError": true,
+		}
 	}
 
-	// Log the outgoing response
-	s.logJSON("Sending response", response)
-
-	err := json.NewEncoder(os.Stdout).Encode(response)
+	structured, ok := value.(map[string]interface{})
+	if !ok {
+		structured = map[string]interface{}{"result": value}
+	}
+	text, err := json.Marshal(value)
 	if err != nil {
-		s.log(fmt.Sprintf("Error encoding response: %v", err))
-		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		text = []byte(output)
+	}
+
+	return map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": string(text),
+			},
+		},
+		"structuredContent": structured,
 	}
 }
 
-// writeError writes a JSON-RPC error response to stdout.
-func (s *Server) writeError(err error) {
-	// Use method not found error code for unsupported methods
-	code := -32000 // Default server error
-	if err.Error() == "method not found" {
-		code = -32601 // Method not found error code
+// addConfiguredTool adds a tool from its config. The tool runs in its "cwd", or in the
+// working directory of the server options, and a relative script path is resolved against
+// that directory.
+func (s *Server) addConfiguredTool(name string, config map[string]string) error {
+	description := config["description"]
+	parameters := config["parameters"]
+	scriptPath := expandHome(config["script"])
+	command := config["command"]
+
+	dir := config["cwd"]
+	if dir == "" {
+		dir = s.options.WorkingDir
+	}
+	if dir != "" {
+		var err error
+		if dir, err = resolveDir(dir); err != nil {
+			return fmt.Errorf("error adding tool %s: %w", name, err)
+		}
+		if scriptPath != "" && !filepath.IsAbs(scriptPath) {
+			scriptPath = filepath.Join(dir, scriptPath)
+		}
+	}
+
+	var addErr error
+	if schemaJSON := config["schema"]; schemaJSON != "" {
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+			return fmt.Errorf("error parsing schema for tool %s: %w", name, err)
+		}
+		addErr = s.AddToolWithSchema(name, description, schema, scriptPath, command)
+	} else {
+		addErr = s.AddTool(name, description, parameters, scriptPath, command)
+	}
+	if addErr != nil {
+		return fmt.Errorf("error adding tool %s: %w", name, addErr)
 	}
 
-	response := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      s.id,
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": err.Error(),
-		},
+	if dir != "" {
+		if err := s.SetToolDir(name, dir); err != nil {
+			return fmt.Errorf("error adding tool %s: %w", name, err)
+		}
 	}
 
-	// Log the outgoing error response
-	s.logJSON("Sending error response", response)
+	if timeoutValue := config["timeout"]; timeoutValue != "" {
+		timeout, err := ParseToolTimeout(timeoutValue)
+		if err != nil {
+			return fmt.Errorf("error adding tool %s: %w", name, err)
+		}
+		if err := s.SetToolTimeout(name, timeout); err != nil {
+			return fmt.Errorf("error adding tool %s: %w", name, err)
+		}
+	}
 
-	encodeErr := json.NewEncoder(os.Stdout).Encode(response)
-	if encodeErr != nil {
-		s.log(fmt.Sprintf("Error encoding error response: %v", encodeErr))
-		fmt.Fprintf(os.Stderr, "Error encoding error response: %v\n", encodeErr)
+	if output := config["output"]; output != "" {
+		if err := s.SetToolOutput(name, output); err != nil {
+			return fmt.Errorf("error adding tool %s: %w", name, err)
+		}
 	}
+
+	return nil
 }
 
-// RunProxyServer creates and runs a proxy server with the specified tool configs.
-func RunProxyServer(toolConfigs map[string]map[string]string) error {
-	server, err := NewProxyServer()
+// RunProxyServer creates and runs a proxy server with the specified tool configs and options.
+func RunProxyServer(toolConfigs map[string]map[string]string, options Options) error {
+	server, err := NewProxyServer(options.Log)
 	if err != nil {
 		return fmt.Errorf("error creating server: %w", err)
 	}
+	server.options = options
+	server.rpc.Quiet = options.Quiet
+	server.rpc.Debug = options.Debug
+	server.rpc.Infof("Logging to %s\n", server.rpc.LogPath())
 
 	// Add tools from configs
 	for name, config := range toolConfigs {
-		description := config["description"]
-		parameters := config["parameters"]
-		scriptPath := config["script"]
-		command := config["command"]
-
-		addErr := server.AddTool(name, description, parameters, scriptPath, command)
-		if addErr != nil {
-			return fmt.Errorf("error adding tool %s: %w", name, addErr)
+		if err := server.addConfiguredTool(name, config); err != nil {
+			return err
 		}
 	}
 
 	// Print registered tools
-	fmt.Fprintln(os.Stderr, "Registered proxy tools:")
+	server.rpc.Infof("Registered proxy tools:\n")
 	for name, tool := range server.tools {
-		fmt.Fprintf(os.Stderr, "- %s: %s (%s: %s)\n", name, tool.Description,
+		server.rpc.Infof("- %s: %s (%s: %s)\n", name, tool.Description,
 			map[bool]string{true: "script", false: "command"}[tool.ScriptPath != ""],
 			map[bool]string{true: tool.ScriptPath, false: tool.Command}[tool.ScriptPath != ""])
 		paramStr := ""
@@ -643,10 +1065,10 @@ func RunProxyServer(toolConfigs map[string]map[string]string) error {
 			}
 		}
 		if paramStr != "" {
-			fmt.Fprintf(os.Stderr, "  Parameters: %s\n", paramStr)
+			server.rpc.Infof("  Parameters: %s\n", paramStr)
 		}
 	}
 
-	server.log(fmt.Sprintf("Starting proxy server with %d tools", len(toolConfigs)))
+	server.rpc.Log(fmt.Sprintf("Starting proxy server with %d tools", len(toolConfigs)))
 	return server.Start()
 }
@@ -0,0 +1,457 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/f/mcptools/pkg/logfile"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	server, err := NewProxyServer(logfile.Options{})
+	if err != nil {
+		t.Fatalf("NewProxyServer(logfile.Options{}) error = %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server
+}
+
+func callToolText(t *testing.T, server *Server, name string, args map[string]interface{}) (string, error) {
+	t.Helper()
+
+	result, err := server.handleToolCall(map[string]interface{}{
+		"name":      name,
+		"arguments": args,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	content, ok := result["content"].([]map[string]interface{})
+	if !ok || len(content) == 0 {
+		t.Fatalf("unexpected tool result: %v", result)
+	}
+
+	text, _ := content[0]["text"].(string)
+	return strings.TrimSpace(text), nil
+}
+
+func TestProxyParameterTypes(t *testing.T) {
+	server := newTestServer(t)
+
+	err := server.AddTool("echo_env", "Echo typed env vars", "s:string,i:int,f:float,b:bool",
+		"", `printf '%s|%s|%s|%s' "$s" "$i" "$f" "$b"`)
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	err = server.AddTool("echo_stdin", "Echo stdin", "s:string,i:int,f:float,b:bool", "", "cat")
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		tool      string
+		args      map[string]interface{}
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "env typed values",
+			tool:     "echo_env",
+			args:     map[string]interface{}{"s": "hello", "i": float64(42), "f": 1.5, "b": true},
+			expected: "hello|42|1.5|true",
+		},
+		{
+			name:     "env zero values",
+			tool:     "echo_env",
+			args:     map[string]interface{}{"s": "", "i": float64(0), "f": float64(0), "b": false},
+			expected: "|0|0|false",
+		},
+		{
+			name:     "env large integer without exponent",
+			tool:     "echo_env",
+			args:     map[string]interface{}{"s": "x", "i": float64(12345678), "f": 1e7, "b": false},
+			expected: "x|12345678|10000000|false",
+		},
+		{
+			name:     "env values coerced from strings",
+			tool:     "echo_env",
+			args:     map[string]interface{}{"s": "x", "i": "7", "f": "2.25", "b": "false"},
+			expected: "x|7|2.25|false",
+		},
+		{
+			name:     "stdin typed values",
+			tool:     "echo_stdin",
+			args:     map[string]interface{}{"s": "hello", "i": float64(42), "f": 1.5, "b": true},
+			expected: `{"b":true,"f":1.5,"i":42,"s":"hello"}`,
+		},
+		{
+			name:     "stdin zero values",
+			tool:     "echo_stdin",
+			args:     map[string]interface{}{"s": "", "i": "0", "f": float64(0), "b": "false"},
+			expected: `{"b":false,"f":0,"i":0,"s":""}`,
+		},
+		{
+			name:      "fractional value for int",
+			tool:      "echo_env",
+			args:      map[string]interface{}{"s": "x", "i": 1.5, "f": 1.0, "b": true},
+			expectErr: true,
+		},
+		{
+			name:      "invalid bool",
+			tool:      "echo_env",
+			args:      map[string]interface{}{"s": "x", "i": float64(1), "f": 1.0, "b": "maybe"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, err := callToolText(t, server, tc.tool, tc.args)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got output %q", text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handleToolCall() error = %v", err)
+			}
+			if text != tc.expected {
+				t.Errorf("output = %q, want %q", text, tc.expected)
+			}
+		})
+	}
+}
+
+func TestProxySchemaTool(t *testing.T) {
+	server := newTestServer(t)
+
+	schema := map[string]interface{}{
+		"type":        "object",
+		"description": "Search items",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "description": "Search text"},
+			"limit": map[string]interface{}{"type": "integer"},
+			"order": map[string]interface{}{"type": "string", "enum": []interface{}{"asc", "desc"}},
+			"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required": []interface{}{"query"},
+	}
+
+	err := server.AddToolWithSchema("search", "", schema, "",
+		`printf '%s|%s|%s|%s|%s' "$MCP_TOOL_NAME" "$query" "$limit" "$tags" "$(cat)"`)
+	if err != nil {
+		t.Fatalf("AddToolWithSchema() error = %v", err)
+	}
+
+	tools, ok := server.handleToolsList()["tools"].([]map[string]interface{})
+	if !ok || len(tools) != 1 {
+		t.Fatalf("unexpected tools list: %v", tools)
+	}
+	if tools[0]["description"] != "Search items" {
+		t.Errorf("description = %v, want schema description", tools[0]["description"])
+	}
+	inputSchema, ok := tools[0]["inputSchema"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected input schema: %v", tools[0]["inputSchema"])
+	}
+	properties, _ := inputSchema["properties"].(map[string]interface{})
+	order, _ := properties["order"].(map[string]interface{})
+	if _, hasEnum := order["enum"]; !hasEnum {
+		t.Errorf("expected enum to be served in schema, got %v", order)
+	}
+
+	text, err := callToolText(t, server, "search", map[string]interface{}{
+		"query": "go",
+		"limit": float64(5),
+		"tags":  []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	expected := `search|go|5|["a","b"]|{"limit":5,"query":"go","tags":["a","b"]}`
+	if text != expected {
+		t.Errorf("output = %q, want %q", text, expected)
+	}
+
+	if _, err := callToolText(t, server, "search", map[string]interface{}{"limit": float64(1)}); err == nil {
+		t.Error("expected error for missing required parameter")
+	}
+}
+
+func TestParseToolTimeout(t *testing.T) {
+	valid := map[string]time.Duration{
+		"90s": 90 * time.Second,
+		"2m":  2 * time.Minute,
+		"30":  30 * time.Second,
+		"1.5": 1500 * time.Millisecond,
+		"0":   0,
+	}
+	for value, expected := range valid {
+		timeout, err := ParseToolTimeout(value)
+		if err != nil || timeout != expected {
+			t.Errorf("ParseToolTimeout(%q) = %v, %v, want %v", value, timeout, err, expected)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "-5", "-1s"} {
+		if _, err := ParseToolTimeout(value); err == nil {
+			t.Errorf("ParseToolTimeout(%q) expected an error", value)
+		}
+	}
+}
+
+func TestProxyJSONOutput(t *testing.T) {
+	server := newTestServer(t)
+
+	tools := map[string]string{
+		"object":  `echo '{"count": 2, "items": ["a", "b"]}'`,
+		"array":   `echo '[1, 2]'`,
+		"invalid": `echo 'not json'`,
+	}
+	for name, command := range tools {
+		if err := server.AddTool(name, "Returns JSON", "", "", command); err != nil {
+			t.Fatalf("AddTool() error = %v", err)
+		}
+		if err := server.SetToolOutput(name, OutputJSON); err != nil {
+			t.Fatalf("SetToolOutput() error = %v", err)
+		}
+	}
+	if err := server.SetToolOutput("object", "yaml"); err == nil {
+		t.Error("SetToolOutput() expected an error for an unknown output")
+	}
+
+	testCases := []struct {
+		tool       string
+		structured map[string]interface{}
+		text       string
+		isError    bool
+	}{
+		{
+			tool:       "object",
+			structured: map[string]interface{}{"count": float64(2), "items": []interface{}{"a", "b"}},
+			text:       `{"count":2,"items":["a","b"]}`,
+		},
+		{
+			tool:       "array",
+			structured: map[string]interface{}{"result": []interface{}{float64(1), float64(2)}},
+			text:       `[1,2]`,
+		},
+		{
+			tool:    "invalid",
+			text:    "tool invalid returned invalid JSON",
+			isError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tool, func(t *testing.T) {
+			result, err := server.handleToolCall(map[string]interface{}{"name": tc.tool, "arguments": map[string]interface{}{}})
+			if err != nil {
+				t.Fatalf("handleToolCall() error = %v", err)
+			}
+
+			if isError, _ := result["isError"].(bool); isError != tc.isError {
+				t.Errorf("isError = %v, want %v", isError, tc.isError)
+			}
+			if tc.structured != nil && !reflect.DeepEqual(result["structuredContent"], tc.structured) {
+				t.Errorf("structuredContent = %v, want %v", result["structuredContent"], tc.structured)
+			}
+			content, _ := result["content"].([]map[string]interface{})
+			if len(content) != 1 || !strings.HasPrefix(content[0]["text"].(string), tc.text) {
+				t.Errorf("content = %v, want text starting with %q", content, tc.text)
+			}
+		})
+	}
+}
+
+func TestProxyDryRun(t *testing.T) {
+	server := newTestServer(t)
+	server.options.DryRun = true
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	err := server.AddTool("add", "Adds a and b", "a:int,b:int", "",
+		`touch `+marker+`; echo "total is $a + $b = $(($a+$b))"`)
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	output, err := callToolText(t, server, "add", map[string]interface{}{"a": "1", "b": float64(2)})
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+
+	for _, expected := range []string{
+		`Expanded: touch ` + marker + `; echo "total is 1 + 2 = $((1+2))"`,
+		"  MCP_TOOL_NAME=add\n  MCP_ARG_a=1\n  MCP_ARG_b=2\n  a=1\n  b=2\n",
+		`Stdin: {"a":1,"b":2}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("dry run output missing %q:\n%s", expected, output)
+		}
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("dry run executed the command")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	testCases := map[string]string{
+		"/bin/sh":      "/bin/sh",
+		"-c":           "-c",
+		"echo $a":      "'echo $a'",
+		"it's":         `'it'\''s'`,
+		"":             "''",
+		"./add.sh":     "./add.sh",
+		"a b; rm -rf":  "'a b; rm -rf'",
+		"key=value,x+": "key=value,x+",
+	}
+	for input, expected := range testCases {
+		if got := shellQuote(input); got != expected {
+			t.Errorf("shellQuote(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestProxyArgumentEnv(t *testing.T) {
+	server := newTestServer(t)
+
+	err := server.AddTool("env", "Echo env vars", "my-param:string,PATH:string,name:string", "",
+		`printf '%s|%s|%s|%s' "$MCP_ARG_my_param" "$MCP_ARG_PATH" "$name" "$MCP_ARG_name"; command -v sh >/dev/null && printf '|path kept'`)
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	args := map[string]interface{}{"my-param": "dash", "PATH": "/nowhere", "name": "bare"}
+	text, err := callToolText(t, server, "env", args)
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if expected := "dash|/nowhere|bare|bare|path kept"; text != expected {
+		t.Errorf("output = %q, want %q", text, expected)
+	}
+
+	server.options.PrefixedEnvOnly = true
+	text, err = callToolText(t, server, "env", args)
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if expected := "dash|/nowhere||bare|path kept"; text != expected {
+		t.Errorf("prefixed only output = %q, want %q", text, expected)
+	}
+}
+
+func TestProxyArgumentEnvCollision(t *testing.T) {
+	server := newTestServer(t)
+
+	err := server.AddTool("clash", "Clashing names", "a-b:string,a_b:string", "", "true")
+	if err == nil || !strings.Contains(err.Error(), "MCP_ARG_a_b") {
+		t.Errorf("AddTool() error = %v, want a collision on MCP_ARG_a_b", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	testCases := map[string]string{
+		"name":       "name",
+		"my-param":   "my_param",
+		"with space": "with_space",
+		"café":       "caf_",
+		"1st":        "1st",
+	}
+	for input, expected := range testCases {
+		if got := envName(input); got != expected {
+			t.Errorf("envName(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestProxyAnswersEmptyResourcesAndPrompts(t *testing.T) {
+	server := newTestServer(t)
+
+	for method, key := range map[string]string{
+		"resources/list":           "resources",
+		"resources/templates/list": "resourceTemplates",
+		"prompts/list":             "prompts",
+	} {
+		response := server.rpc.Handle([]byte(`{"jsonrpc":"2.0","id":1,"method":"` + method + `"}`))
+		if _, hasError := response["error"]; hasError {
+			t.Errorf("%s returned an error: %v", method, response["error"])
+			continue
+		}
+		result, _ := response["result"].(map[string]interface{})
+		if entries, ok := result[key].([]interface{}); !ok || len(entries) != 0 {
+			t.Errorf("%s result = %v, want an empty %s list", method, response["result"], key)
+		}
+	}
+
+	response := server.rpc.Handle([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+	result, _ := response["result"].(map[string]interface{})
+	capabilities, _ := result["capabilities"].(map[string]interface{})
+	if !reflect.DeepEqual(capabilities, map[string]interface{}{"tools": map[string]interface{}{}}) {
+		t.Errorf("capabilities = %v, want only tools", capabilities)
+	}
+}
+
+func TestProxyMaxOutputSize(t *testing.T) {
+	server := newTestServer(t)
+	err := server.AddTool("big", "Prints 20000 bytes", "", "", `head -c 20000 /dev/zero | tr '\0' x`)
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+
+	// Output is returned in full by default
+	output, err := callToolText(t, server, "big", nil)
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if output != strings.Repeat("x", 20000) {
+		t.Errorf("output has %d bytes, want all 20000", len(output))
+	}
+
+	server.options.MaxOutputSize = 100
+	output, err = callToolText(t, server, "big", nil)
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if output != strings.TrimSpace(strings.Repeat("x", 100)+truncatedMarker) {
+		t.Errorf("output with MaxOutputSize = %q, want 100 bytes and the marker", output)
+	}
+}
+
+func TestReadOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxSize  int64
+		expected string
+	}{
+		{"unlimited", "line one\nline two\n", 0, "line one\nline two\n"},
+		{"within limit", "short\n", 6, "short\n"},
+		{"truncated", "0123456789\n", 4, "0123" + truncatedMarker},
+		{"no newline", strings.Repeat("x", 1<<20), 3, "xxx" + truncatedMarker},
+		{"split rune", "abéé", 3, "ab" + truncatedMarker},
+		{"whole rune", "abéé", 4, "abé" + truncatedMarker},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := readOutput(strings.NewReader(tc.input), tc.maxSize)
+			if err != nil {
+				t.Fatalf("readOutput() error = %v", err)
+			}
+			if output != tc.expected {
+				t.Errorf("readOutput() = %q, want %q", output, tc.expected)
+			}
+		})
+	}
+}
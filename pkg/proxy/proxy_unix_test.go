@@ -0,0 +1,123 @@
+//go:build !windows
+
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProxyToolTimeout(t *testing.T) {
+	server := newTestServer(t)
+
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	err := server.AddTool("hang", "Never finishes", "", "", `sleep 30 & echo $! > `+pidFile+`; sleep 30`)
+	if err != nil {
+		t.Fatalf("AddTool() error = %v", err)
+	}
+	if err := server.SetToolTimeout("hang", 200*time.Millisecond); err != nil {
+		t.Fatalf("SetToolTimeout() error = %v", err)
+	}
+
+	start := time.Now()
+	result, err := server.handleToolCall(map[string]interface{}{"name": "hang", "arguments": map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("tool was killed after %v, want about 200ms", elapsed)
+	}
+
+	if result["isError"] != true {
+		t.Errorf("expected an error result, got %v", result)
+	}
+	content, _ := result["content"].([]map[string]interface{})
+	if len(content) != 1 || !strings.Contains(content[0]["text"].(string), "hang was killed after 200ms") {
+		t.Errorf("unexpected timeout result: %v", content)
+	}
+
+	// The process the script started in the background is killed with it
+	data, _ := os.ReadFile(pidFile)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	if pid == 0 {
+		t.Fatal("script did not start its child process")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(pid, 0) == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if syscall.Kill(pid, 0) == nil {
+		t.Errorf("child process %d is still running", pid)
+	}
+}
+
+func TestProxyToolWorkingDir(t *testing.T) {
+	server := newTestServer(t)
+
+	toolDir := t.TempDir()
+	serverDir := t.TempDir()
+	server.options.WorkingDir = serverDir
+	if err := os.WriteFile(filepath.Join(toolDir, "where.sh"), []byte("#!/bin/sh\necho \"script in $(pwd -P)\"\n"), 0o700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	configs := map[string]map[string]string{
+		"tool_cwd":    {"description": "Runs in its cwd", "command": "pwd -P", "cwd": toolDir},
+		"server_cwd":  {"description": "Runs in the server working dir", "command": "pwd -P"},
+		"relative_sh": {"description": "Relative script", "script": "./where.sh", "cwd": toolDir},
+	}
+	for name, config := range configs {
+		if err := server.addConfiguredTool(name, config); err != nil {
+			t.Fatalf("addConfiguredTool(%s) error = %v", name, err)
+		}
+	}
+
+	resolvedToolDir, _ := filepath.EvalSymlinks(toolDir)
+	resolvedServerDir, _ := filepath.EvalSymlinks(serverDir)
+	expected := map[string]string{
+		"tool_cwd":    resolvedToolDir,
+		"server_cwd":  resolvedServerDir,
+		"relative_sh": "script in " + resolvedToolDir,
+	}
+	for name, want := range expected {
+		text, err := callToolText(t, server, name, map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("%s: handleToolCall() error = %v", name, err)
+		}
+		if strings.TrimSpace(text) != want {
+			t.Errorf("%s ran in %q, want %q", name, strings.TrimSpace(text), want)
+		}
+	}
+}
+
+func TestProxyToolWorkingDirHome(t *testing.T) {
+	server := newTestServer(t)
+
+	home := os.Getenv("HOME")
+	if err := os.Mkdir(filepath.Join(home, "project"), 0o700); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	config := map[string]string{"description": "Runs in home", "command": "pwd -P", "cwd": "~/project"}
+	if err := server.addConfiguredTool("home_cwd", config); err != nil {
+		t.Fatalf("addConfiguredTool() error = %v", err)
+	}
+
+	text, err := callToolText(t, server, "home_cwd", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("handleToolCall() error = %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(home, "project"))
+	if strings.TrimSpace(text) != want {
+		t.Errorf("tool ran in %q, want %q", strings.TrimSpace(text), want)
+	}
+
+	missing := map[string]string{"description": "Missing dir", "command": "pwd", "cwd": filepath.Join(home, "missing")}
+	if err := server.addConfiguredTool("missing_cwd", missing); err == nil {
+		t.Error("expected an error for a missing working directory")
+	}
+}
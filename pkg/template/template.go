@@ -0,0 +1,89 @@
+/*
+Package template implements saved call templates for MCP.
+*/
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Template is a saved invocation of the call command.
+type Template struct {
+	// Entity is the called entity as given to call, such as read_file or resource:uri.
+	Entity string `json:"entity"`
+	// Arguments are the params of the call.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Server is the alias or command of the server the call was made to.
+	Server []string `json:"server,omitempty"`
+}
+
+// GetPath returns the path of the file holding the template with the given name.
+func GetPath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	templatesDir := filepath.Join(homeDir, ".mcpt", "templates")
+	mkdirErr := os.MkdirAll(templatesDir, 0o750)
+	if mkdirErr != nil {
+		return "", fmt.Errorf("failed to create templates directory: %w", mkdirErr)
+	}
+
+	return filepath.Join(templatesDir, name+".json"), nil
+}
+
+// Load loads the template with the given name.
+func Load(name string) (*Template, error) {
+	path, err := GetPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path is generated internally by GetPath
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	var t Template
+	if unmarshalErr := json.Unmarshal(data, &t); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, unmarshalErr)
+	}
+	if t.Entity == "" {
+		return nil, fmt.Errorf("template %s has no entity", name)
+	}
+
+	return &t, nil
+}
+
+// Save saves a template under the given name, replacing any template of that name.
+func Save(name string, t *Template) error {
+	path, err := GetPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	writeErr := os.WriteFile(path, data, 0o600) // #nosec G304 - path is generated internally by GetPath
+	if writeErr != nil {
+		return fmt.Errorf("failed to write template file: %w", writeErr)
+	}
+
+	return nil
+}
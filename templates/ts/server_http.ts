@@ -1,7 +1,6 @@
 import { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
 import { StreamableHTTPServerTransport } from "@modelcontextprotocol/sdk/server/streamableHttp.js";
 import express from "express";
-import { z } from "zod";
 import { randomUUID } from "crypto";
 
 // Initialize server
@@ -39,4 +38,4 @@ await server.connect(transport);
 const port = 3000;
 app.listen(port, () => {
   console.log(`MCP server running on http://localhost:${port}`);
-}); 
\ No newline at end of file
+}); 
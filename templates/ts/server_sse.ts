@@ -1,7 +1,6 @@
 import { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
 import { SSEServerTransport } from "@modelcontextprotocol/sdk/server/sse.js";
 import express from "express";
-import { z } from "zod";
 
 // Initialize server
 const server = new McpServer({
@@ -9,23 +8,38 @@ const server = new McpServer({
   version: "1.0.0"
 });
 
+// Initialize components here
+// COMPONENT_INITIALIZATION
+
 // Setup Express app
 const app = express();
 
+// The transport of each connected client, by session id
+const transports: { [sessionId: string]: SSEServerTransport } = {};
+
 // Create an SSE endpoint that clients can connect to
 app.get("/sse", async (req, res) => {
   const transport = new SSEServerTransport("/messages", res);
+  transports[transport.sessionId] = transport;
+  res.on("close", () => {
+    delete transports[transport.sessionId];
+  });
   await server.connect(transport);
 });
 
-// Create an endpoint to receive messages from clients 
-app.post("/messages", express.json(), async (req, res) => {
-  // Handle the message and send response
-  res.json({ success: true });
+// Create an endpoint to receive messages from clients, which the SSE transport
+// answers on the event stream of their session
+app.post("/messages", async (req, res) => {
+  const transport = transports[req.query.sessionId as string];
+  if (!transport) {
+    res.status(400).send("No transport found for sessionId");
+    return;
+  }
+  await transport.handlePostMessage(req, res);
 });
 
 // Start HTTP server
 const port = 3000;
 app.listen(port, () => {
   console.log(`MCP server running on http://localhost:${port}/sse`);
-}); 
\ No newline at end of file
+});
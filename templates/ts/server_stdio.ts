@@ -1,6 +1,5 @@
 import { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
 import { StdioServerTransport } from "@modelcontextprotocol/sdk/server/stdio.js";
-import { z } from "zod";
 
 // Initialize server
 const server = new McpServer({
@@ -8,6 +7,9 @@ const server = new McpServer({
   version: "1.0.0"
 });
 
+// Initialize components here
+// COMPONENT_INITIALIZATION
+
 // === Start server with stdio transport ===
 const transport = new StdioServerTransport();
 await server.connect(transport);
@@ -2,33 +2,27 @@ import { z } from "zod";
 import { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
 
 export default (server: McpServer) => {
-  // Define a calculator tool
   server.tool(
     "TOOL_NAME",
     "TOOL_DESCRIPTION",
     {
-      // Define the parameters for your tool using Zod
-      
-      someEnum: z.enum(["option1", "option2", "option3"], {
-        description: "An enum parameter"
-      }),
-      aNumber: z.number({
-        description: "A number parameter"
-      }),
-      aString: z.string({
-        description: "A string parameter"
-      })
+      // The parameters of the tool, described with Zod. They are published as the
+      // tool's inputSchema and each call's arguments are validated against them.
+      someEnum: z.enum(["option1", "option2", "option3"]).describe("An enum parameter"),
+      aNumber: z.number().describe("A number parameter"),
+      aString: z.string().optional().describe("An optional string parameter")
     },
-    async (params) => {
-      
+    async ({ someEnum, aNumber, aString }) => {
       // Implement the tool logic here
+      const text = `Called with someEnum=${someEnum}, aNumber=${aNumber}` +
+        (aString !== undefined ? `, aString=${aString}` : "");
 
       return {
         content: [{
           type: "text",
-          text: "This is the tool response to the user's request"
+          text
         }]
       };
     }
   );
-}
\ No newline at end of file
+}